@@ -0,0 +1,147 @@
+// Package anonlogin is a challenge-response protocol for anonymous login of ring members.
+// The server issues a random challenge bound to its identity and the session case. The client proves
+// membership in the ring by a linkable ring signature of the challenge. The server learns only the key image
+// of the signature, which is a pseudonym of the member stable within the session case of the server.
+package anonlogin
+
+import (
+	"encoding/json"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/zbohm/lirisi/client"
+	"github.com/zbohm/lirisi/ring"
+	"golang.org/x/crypto/sha3"
+)
+
+// DefaultTTL is the default lifetime of the challenge.
+const DefaultTTL = 2 * time.Minute
+
+// Challenge issued by the server. The nonce is hex encoded, expiration is in Unix time.
+type Challenge struct {
+	Server  string `json:"server"`
+	Case    string `json:"case"`
+	Nonce   string `json:"nonce"`
+	Expires int64  `json:"expires"`
+}
+
+// Response to the challenge. The signature is in DER.
+type Response struct {
+	Nonce     string `json:"nonce"`
+	Signature []byte `json:"signature"`
+}
+
+// Message returns content signed in response to the challenge.
+func Message(challenge Challenge) []byte {
+	return []byte("lirisi-login-v1\n" + challenge.Server + "\n" + challenge.Case + "\n" + challenge.Nonce + "\n" +
+		strconv.FormatInt(challenge.Expires, 10))
+}
+
+// SigningCase returns case identifier of the signature. It is derived from the server identity and the session case,
+// so that pseudonyms of the same member on servers with the same session case cannot be linked.
+func SigningCase(challenge Challenge) []byte {
+	digest := sha3.Sum256([]byte("lirisi-login-v1\n" + strconv.Itoa(len(challenge.Server)) + ":" + challenge.Server + challenge.Case))
+	return digest[:]
+}
+
+// ParseChallenge decodes the challenge from JSON.
+func ParseChallenge(content []byte) (int, Challenge) {
+	challenge := Challenge{}
+	if err := json.Unmarshal(content, &challenge); err != nil || challenge.Server == "" || challenge.Nonce == "" {
+		return ring.InvalidChallenge, challenge
+	}
+	return ring.Success, challenge
+}
+
+// Respond signs the challenge by the private key as a member of the ring. The challenge must be issued
+// for the server, so that it cannot be relayed from another server.
+func Respond(challenge Challenge, server string, foldedPublicKeys, privateKey []byte) (int, Response) {
+	if challenge.Server != server {
+		return ring.ServerIdentityMismatch, Response{}
+	}
+	status, signature := client.CreateSignature(foldedPublicKeys, privateKey, Message(challenge), SigningCase(challenge), "DER")
+	if status != ring.Success {
+		return status, Response{}
+	}
+	return ring.Success, Response{Nonce: challenge.Nonce, Signature: signature}
+}
+
+// Server issues challenges and verifies responses. It is safe for concurrent use.
+type Server struct {
+	Identity string
+	Case     string
+	// TTL is the lifetime of the challenge.
+	TTL time.Duration
+	// Now returns the current time. Default is time.Now.
+	Now func() time.Time
+
+	foldedPublicKeys []byte
+	pending          map[string]Challenge
+	mutex            sync.Mutex
+}
+
+// NewServer creates server of the identity for members of the ring of the folded public keys.
+// Pseudonyms of the members are scoped to the session case.
+func NewServer(identity string, foldedPublicKeys []byte, caseIdentifier string) (int, *Server) {
+	if status, _, _ := client.UnfoldPublicKeysContent(foldedPublicKeys); status != ring.Success {
+		return status, nil
+	}
+	return ring.Success, &Server{
+		Identity:         identity,
+		Case:             caseIdentifier,
+		TTL:              DefaultTTL,
+		foldedPublicKeys: foldedPublicKeys,
+		pending:          map[string]Challenge{},
+	}
+}
+
+func (s *Server) now() time.Time {
+	if s.Now != nil {
+		return s.Now()
+	}
+	return time.Now()
+}
+
+// NewChallenge issues a new challenge. Expired challenges are dropped.
+func (s *Server) NewChallenge() Challenge {
+	now := s.now()
+	challenge := Challenge{
+		Server:  s.Identity,
+		Case:    s.Case,
+		Nonce:   string(client.NewChallenge()),
+		Expires: now.Add(s.TTL).Unix(),
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for nonce, pending := range s.pending {
+		if now.Unix() > pending.Expires {
+			delete(s.pending, nonce)
+		}
+	}
+	s.pending[challenge.Nonce] = challenge
+	return challenge
+}
+
+// Verify verifies the response and returns the pseudonym of the member, which is the key image
+// of the signature. Each challenge can be answered only once, even by a failed response.
+func (s *Server) Verify(response Response) (int, string) {
+	s.mutex.Lock()
+	challenge, ok := s.pending[response.Nonce]
+	delete(s.pending, response.Nonce)
+	s.mutex.Unlock()
+	if !ok {
+		return ring.UnknownChallenge, ""
+	}
+	if s.now().Unix() > challenge.Expires {
+		return ring.ChallengeExpired, ""
+	}
+	if status := client.VerifySignature(s.foldedPublicKeys, response.Signature, Message(challenge), SigningCase(challenge)); status != ring.Success {
+		return status, ""
+	}
+	status, keyImage := client.SignatureKeyImage(response.Signature, false)
+	if status != ring.Success {
+		return status, ""
+	}
+	return ring.Success, string(keyImage)
+}
@@ -0,0 +1,151 @@
+package anonlogin
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/zbohm/lirisi/internal/testring"
+	"github.com/zbohm/lirisi/ring"
+)
+
+const identity = "https://forum.example.com"
+
+func newServer(t *testing.T, folded []byte, caseIdentifier string) *Server {
+	status, server := NewServer(identity, folded, caseIdentifier)
+	if status != ring.Success {
+		t.Fatal(ring.ErrorMessages[status])
+	}
+	return server
+}
+
+func login(t *testing.T, server *Server, folded, privateKey []byte) string {
+	status, response := Respond(server.NewChallenge(), identity, folded, privateKey)
+	if status != ring.Success {
+		t.Fatal(ring.ErrorMessages[status])
+	}
+	status, pseudonym := server.Verify(response)
+	if status != ring.Success {
+		t.Fatal(ring.ErrorMessages[status])
+	}
+	return pseudonym
+}
+
+func TestLogin(t *testing.T) {
+	privateKeys, folded := testring.Create(t, 3)
+	server := newServer(t, folded, "session-1")
+
+	first := login(t, server, folded, privateKeys[0])
+	if first == "" {
+		t.Fatal("Missing pseudonym.")
+	}
+	if login(t, server, folded, privateKeys[0]) != first {
+		t.Errorf("Pseudonym of the member changed in the session case.")
+	}
+	if login(t, server, folded, privateKeys[1]) == first {
+		t.Errorf("Pseudonyms of different members are equal.")
+	}
+	if login(t, newServer(t, folded, "session-2"), folded, privateKeys[0]) == first {
+		t.Errorf("Pseudonym is not scoped to the session case.")
+	}
+}
+
+func TestPseudonymScopedToServer(t *testing.T) {
+	privateKeys, folded := testring.Create(t, 2)
+	server := newServer(t, folded, "session")
+	status, other := NewServer("https://other.example.com", folded, "session")
+	if status != ring.Success {
+		t.Fatal(ring.ErrorMessages[status])
+	}
+
+	status, response := Respond(other.NewChallenge(), other.Identity, folded, privateKeys[0])
+	if status != ring.Success {
+		t.Fatal(ring.ErrorMessages[status])
+	}
+	status, pseudonym := other.Verify(response)
+	if status != ring.Success {
+		t.Fatal(ring.ErrorMessages[status])
+	}
+	if login(t, server, folded, privateKeys[0]) == pseudonym {
+		t.Errorf("Pseudonyms of the member on servers with the same session case are linked.")
+	}
+}
+
+func TestReplay(t *testing.T) {
+	privateKeys, folded := testring.Create(t, 2)
+	server := newServer(t, folded, "session")
+
+	status, response := Respond(server.NewChallenge(), identity, folded, privateKeys[0])
+	if status != ring.Success {
+		t.Fatal(ring.ErrorMessages[status])
+	}
+	if status, _ := server.Verify(response); status != ring.Success {
+		t.Fatal(ring.ErrorMessages[status])
+	}
+	if status, _ := server.Verify(response); status != ring.UnknownChallenge {
+		t.Errorf("Replayed response verified with status %d.", status)
+	}
+
+	// Response to another server cannot be used.
+	other := newServer(t, folded, "session")
+	other.Identity = "https://other.example.com"
+	status, response = Respond(server.NewChallenge(), identity, folded, privateKeys[0])
+	if status != ring.Success {
+		t.Fatal(ring.ErrorMessages[status])
+	}
+	if status, _ := other.Verify(response); status != ring.UnknownChallenge {
+		t.Errorf("Response to another server verified with status %d.", status)
+	}
+
+	// Signature of one challenge does not answer another one.
+	fresh := server.NewChallenge()
+	response.Nonce = fresh.Nonce
+	if status, _ := server.Verify(response); status == ring.Success {
+		t.Errorf("Signature of another challenge verified.")
+	}
+}
+
+func TestRejected(t *testing.T) {
+	privateKeys, folded := testring.Create(t, 2)
+	outsiders, otherFolded := testring.Create(t, 2)
+	server := newServer(t, folded, "session")
+	now := time.Now()
+	server.Now = func() time.Time { return now }
+
+	if status, _ := Respond(server.NewChallenge(), "https://other.example.com", folded, privateKeys[0]); status != ring.ServerIdentityMismatch {
+		t.Errorf("Challenge of another server answered with status %d.", status)
+	}
+
+	status, response := Respond(server.NewChallenge(), identity, otherFolded, outsiders[0])
+	if status != ring.Success {
+		t.Fatal(ring.ErrorMessages[status])
+	}
+	if status, _ := server.Verify(response); status == ring.Success {
+		t.Errorf("Response of an outsider verified.")
+	}
+
+	status, response = Respond(server.NewChallenge(), identity, folded, privateKeys[0])
+	if status != ring.Success {
+		t.Fatal(ring.ErrorMessages[status])
+	}
+	now = now.Add(DefaultTTL + time.Second)
+	if status, _ := server.Verify(response); status != ring.ChallengeExpired {
+		t.Errorf("Expired challenge answered with status %d.", status)
+	}
+}
+
+func TestParseChallenge(t *testing.T) {
+	_, folded := testring.Create(t, 2)
+	challenge := newServer(t, folded, "session").NewChallenge()
+	content, err := json.Marshal(challenge)
+	if err != nil {
+		t.Fatal(err)
+	}
+	status, parsed := ParseChallenge(content)
+	if status != ring.Success || parsed != challenge {
+		t.Errorf("Unexpected challenge %+v.", parsed)
+	}
+	if status, _ := ParseChallenge([]byte(`{"case": "session"}`)); status != ring.InvalidChallenge {
+		t.Errorf("Unexpected status %d.", status)
+	}
+}
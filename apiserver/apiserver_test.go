@@ -0,0 +1,176 @@
+package apiserver
+
+import (
+	"bytes"
+	"crypto/elliptic"
+	"encoding/asn1"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/zbohm/lirisi/ring"
+	"golang.org/x/crypto/sha3"
+)
+
+var endpoints = []string{"/fold", "/unfold", "/digest", "/sign", "/verify", "/key-image", "/genkey", "/pubout"}
+
+func call(t *testing.T, url string, request, response interface{}, expected int) {
+	body, err := json.Marshal(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	content, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != expected {
+		t.Fatalf("Unexpected status code %d of %s, expected %d: %s", resp.StatusCode, url, expected, content)
+	}
+	if response != nil {
+		if err := json.Unmarshal(content, response); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func startServer(t *testing.T, size int) (*httptest.Server, []byte, string) {
+	dir, err := ioutil.TempDir("", "apiserver")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(New(dir))
+	publicKeys := make([][]byte, size)
+	for i := range publicKeys {
+		genkey := GenkeyResponse{}
+		call(t, server.URL+"/genkey", GenkeyRequest{Curve: "secp256k1"}, &genkey, http.StatusOK)
+		if err := ioutil.WriteFile(filepath.Join(dir, "key"+strconv.Itoa(i)+".pem"), genkey.PrivateKey, 0600); err != nil {
+			t.Fatal(err)
+		}
+		pubout := PuboutResponse{}
+		call(t, server.URL+"/pubout", PuboutRequest{PrivateKey: genkey.PrivateKey}, &pubout, http.StatusOK)
+		publicKeys[i] = pubout.PublicKey
+	}
+	fold := FoldResponse{}
+	call(t, server.URL+"/fold", FoldRequest{PublicKeys: publicKeys, Format: "DER"}, &fold, http.StatusOK)
+	return server, fold.FoldedPublicKeys, dir
+}
+
+func TestSignAndVerify(t *testing.T) {
+	server, folded, dir := startServer(t, 3)
+	defer os.RemoveAll(dir)
+	defer server.Close()
+
+	unfold := UnfoldResponse{}
+	call(t, server.URL+"/unfold", UnfoldRequest{FoldedPublicKeys: folded}, &unfold, http.StatusOK)
+	if len(unfold.PublicKeys) != 3 {
+		t.Errorf("Unexpected number of public keys %d.", len(unfold.PublicKeys))
+	}
+	digest := DigestResponse{}
+	call(t, server.URL+"/digest", DigestRequest{FoldedPublicKeys: folded, Separator: true}, &digest, http.StatusOK)
+	if !strings.Contains(digest.Digest, ":") {
+		t.Errorf("Unexpected digest %s.", digest.Digest)
+	}
+
+	sign := SignResponse{}
+	call(t, server.URL+"/sign", SignRequest{FoldedPublicKeys: folded, Key: "key1.pem", Message: []byte("Hello"), Case: []byte("case")}, &sign, http.StatusOK)
+
+	verify := VerifyResponse{}
+	call(t, server.URL+"/verify", VerifyRequest{FoldedPublicKeys: folded, Signature: sign.Signature, Message: []byte("Hello"), Case: []byte("case")}, &verify, http.StatusOK)
+	if !verify.Valid || verify.Status != ring.Success {
+		t.Errorf("Signature is not valid: %+v", verify)
+	}
+	call(t, server.URL+"/verify", VerifyRequest{FoldedPublicKeys: folded, Signature: sign.Signature, Message: []byte("Hello!"), Case: []byte("case")}, &verify, http.StatusOK)
+	if verify.Valid || verify.Error == "" {
+		t.Errorf("Signature of another message is valid: %+v", verify)
+	}
+
+	keyImage := KeyImageResponse{}
+	call(t, server.URL+"/key-image", KeyImageRequest{Signature: sign.Signature}, &keyImage, http.StatusOK)
+	other := KeyImageResponse{}
+	call(t, server.URL+"/sign", SignRequest{FoldedPublicKeys: folded, Key: "key1.pem", Message: []byte("Bye"), Case: []byte("case")}, &sign, http.StatusOK)
+	call(t, server.URL+"/key-image", KeyImageRequest{Signature: sign.Signature}, &other, http.StatusOK)
+	if keyImage.KeyImage == "" || keyImage.KeyImage != other.KeyImage {
+		t.Errorf("Signatures of the same signer are not linked.")
+	}
+}
+
+func TestErrors(t *testing.T) {
+	server, folded, dir := startServer(t, 2)
+	defer os.RemoveAll(dir)
+	defer server.Close()
+
+	failure := ErrorResponse{}
+	call(t, server.URL+"/genkey", GenkeyRequest{Curve: "unknown"}, &failure, http.StatusUnprocessableEntity)
+	if failure.Status != ring.UnexpectedCurveType || failure.Error != ring.ErrorMessages[ring.UnexpectedCurveType] {
+		t.Errorf("Unexpected error %+v.", failure)
+	}
+	call(t, server.URL+"/sign", SignRequest{FoldedPublicKeys: folded, Key: "missing.pem", Message: []byte("Hello")}, nil, http.StatusNotFound)
+	call(t, server.URL+"/sign", SignRequest{FoldedPublicKeys: folded, Key: "../" + filepath.Base(dir) + "/key0.pem", Message: []byte("Hello")}, nil, http.StatusNotFound)
+	call(t, server.URL+"/fold", map[string]string{"unknown": "field"}, nil, http.StatusBadRequest)
+	call(t, server.URL+"/key-image", map[string]string{"signature": strings.Repeat("A", DefaultMaxRequestSize)}, nil, http.StatusRequestEntityTooLarge)
+
+	resp, err := http.Get(server.URL + "/genkey")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("Unexpected status code %d.", resp.StatusCode)
+	}
+
+	disabled := httptest.NewServer(New(""))
+	defer disabled.Close()
+	call(t, disabled.URL+"/sign", SignRequest{FoldedPublicKeys: folded, Key: "key0.pem", Message: []byte("Hello")}, nil, http.StatusNotFound)
+}
+
+func TestMalformedInput(t *testing.T) {
+	s := New("")
+	s.mux.HandleFunc("/panic", func(w http.ResponseWriter, r *http.Request) { panic("fault") })
+	s.mux.HandleFunc("/late-panic", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, GenkeyResponse{})
+		panic("fault")
+	})
+	server := httptest.NewServer(s)
+	defer server.Close()
+
+	curveOID, _ := ring.GetCurveOID(elliptic.P256)
+	hasherOID, _ := ring.GetHasherOID(sha3.New256)
+	folded, err := asn1.Marshal(ring.FoldedPublicKeys{CurveOID: curveOID, HasherOID: hasherOID, Keys: [][]byte{{}, {}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	failure := ErrorResponse{}
+	call(t, server.URL+"/unfold", UnfoldRequest{FoldedPublicKeys: folded}, &failure, http.StatusUnprocessableEntity)
+	if failure.Status != ring.NilPointCoordinates {
+		t.Errorf("Unexpected error %+v.", failure)
+	}
+	call(t, server.URL+"/panic", map[string]string{}, &failure, http.StatusInternalServerError)
+	if failure.Error != "Internal server error." {
+		t.Errorf("Unexpected error %+v.", failure)
+	}
+	call(t, server.URL+"/late-panic", map[string]string{}, &GenkeyResponse{}, http.StatusOK)
+	call(t, server.URL+"/genkey", GenkeyRequest{}, nil, http.StatusOK)
+}
+
+func TestOpenAPIDescription(t *testing.T) {
+	content, err := ioutil.ReadFile("openapi.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, endpoint := range endpoints {
+		if !bytes.Contains(content, []byte("\n  "+endpoint+":\n")) {
+			t.Errorf("Endpoint %s is not described.", endpoint)
+		}
+	}
+}
@@ -0,0 +1,330 @@
+// Package apiserver exposes the client operations as a stateless JSON HTTP API.
+// Binary values (keys, folded public keys, signatures, messages, case identifiers) are base64 in JSON,
+// whatever the format PEM or DER of the content. The API is described in openapi.yaml.
+//
+// Endpoints (all POST):
+//
+//	/fold       - Fold public keys.
+//	/unfold     - Unfold public keys.
+//	/digest     - Digest of folded public keys.
+//	/sign       - Sign the message by the private key from the key directory of the server.
+//	/verify     - Verify the signature.
+//	/key-image  - Key image of the signature.
+//	/genkey     - Generate private key.
+//	/pubout     - Derive public key from the private key.
+package apiserver
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"path/filepath"
+	"runtime/debug"
+	"strings"
+
+	"github.com/zbohm/lirisi/client"
+	"github.com/zbohm/lirisi/ring"
+)
+
+// DefaultMaxRequestSize limits size of the request body.
+const DefaultMaxRequestSize = 4 << 20
+
+// Server of the API. It is safe for concurrent use.
+type Server struct {
+	// MaxRequestSize limits size of the request body in bytes.
+	MaxRequestSize int64
+	keyDirectory   string
+	mux            *http.ServeMux
+}
+
+// ErrorResponse is returned with status code 4xx or 5xx.
+type ErrorResponse struct {
+	Status int    `json:"status,omitempty"`
+	Error  string `json:"error"`
+}
+
+// FoldRequest is the body of /fold.
+type FoldRequest struct {
+	PublicKeys [][]byte `json:"public_keys"`
+	Hash       string   `json:"hash"`
+	Format     string   `json:"format"`
+	Order      string   `json:"order"`
+}
+
+// FoldResponse is the response of /fold.
+type FoldResponse struct {
+	FoldedPublicKeys []byte `json:"folded_public_keys"`
+}
+
+// UnfoldRequest is the body of /unfold.
+type UnfoldRequest struct {
+	FoldedPublicKeys []byte `json:"folded_public_keys"`
+	Format           string `json:"format"`
+}
+
+// UnfoldResponse is the response of /unfold.
+type UnfoldResponse struct {
+	PublicKeys [][]byte `json:"public_keys"`
+}
+
+// DigestRequest is the body of /digest.
+type DigestRequest struct {
+	FoldedPublicKeys []byte `json:"folded_public_keys"`
+	Separator        bool   `json:"separator"`
+}
+
+// DigestResponse is the response of /digest.
+type DigestResponse struct {
+	Digest string `json:"digest"`
+}
+
+// SignRequest is the body of /sign.
+type SignRequest struct {
+	FoldedPublicKeys []byte `json:"folded_public_keys"`
+	Key              string `json:"key"`
+	Message          []byte `json:"message"`
+	Case             []byte `json:"case"`
+	Format           string `json:"format"`
+}
+
+// SignResponse is the response of /sign.
+type SignResponse struct {
+	Signature []byte `json:"signature"`
+}
+
+// VerifyRequest is the body of /verify.
+type VerifyRequest struct {
+	FoldedPublicKeys []byte `json:"folded_public_keys"`
+	Signature        []byte `json:"signature"`
+	Message          []byte `json:"message"`
+	Case             []byte `json:"case"`
+}
+
+// VerifyResponse is the response of /verify. Invalid signature is not an error of the request.
+type VerifyResponse struct {
+	Valid  bool   `json:"valid"`
+	Status int    `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// KeyImageRequest is the body of /key-image.
+type KeyImageRequest struct {
+	Signature []byte `json:"signature"`
+	Separator bool   `json:"separator"`
+}
+
+// KeyImageResponse is the response of /key-image.
+type KeyImageResponse struct {
+	KeyImage string `json:"key_image"`
+}
+
+// GenkeyRequest is the body of /genkey.
+type GenkeyRequest struct {
+	Curve  string `json:"curve"`
+	Format string `json:"format"`
+}
+
+// GenkeyResponse is the response of /genkey.
+type GenkeyResponse struct {
+	PrivateKey []byte `json:"private_key"`
+}
+
+// PuboutRequest is the body of /pubout.
+type PuboutRequest struct {
+	PrivateKey []byte `json:"private_key"`
+	Format     string `json:"format"`
+}
+
+// PuboutResponse is the response of /pubout.
+type PuboutResponse struct {
+	PublicKey []byte `json:"public_key"`
+}
+
+// New creates server of the API. Private keys for /sign are files in the key directory,
+// referenced by their names. Signing is disabled if the key directory is empty.
+func New(keyDirectory string) *Server {
+	s := &Server{
+		MaxRequestSize: DefaultMaxRequestSize,
+		keyDirectory:   keyDirectory,
+		mux:            http.NewServeMux(),
+	}
+	s.mux.HandleFunc("/fold", s.handleFold)
+	s.mux.HandleFunc("/unfold", s.handleUnfold)
+	s.mux.HandleFunc("/digest", s.handleDigest)
+	s.mux.HandleFunc("/sign", s.handleSign)
+	s.mux.HandleFunc("/verify", s.handleVerify)
+	s.mux.HandleFunc("/key-image", s.handleKeyImage)
+	s.mux.HandleFunc("/genkey", s.handleGenkey)
+	s.mux.HandleFunc("/pubout", s.handlePubout)
+	return s
+}
+
+// headerWriter remembers whether the header of the response was written.
+type headerWriter struct {
+	http.ResponseWriter
+	written bool
+}
+
+func (w *headerWriter) WriteHeader(code int) {
+	w.written = true
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *headerWriter) Write(content []byte) (int, error) {
+	w.written = true
+	return w.ResponseWriter.Write(content)
+}
+
+// ServeHTTP serves the request. Panic of a handler is a fault of the server. It is logged and answered
+// by status 500 unless the response was already started.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	writer := &headerWriter{ResponseWriter: w}
+	defer func() {
+		if err := recover(); err != nil {
+			log.Printf("apiserver: panic serving %s: %v\n%s", r.URL.Path, err, debug.Stack())
+			if !writer.written {
+				writeJSON(writer, http.StatusInternalServerError, ErrorResponse{Error: "Internal server error."})
+			}
+		}
+	}()
+	s.mux.ServeHTTP(writer, r)
+}
+
+func writeJSON(w http.ResponseWriter, code int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// decode reads the request into the value. It writes error response and returns false on failure.
+func (s *Server) decode(w http.ResponseWriter, r *http.Request, value interface{}) bool {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", "POST")
+		writeJSON(w, http.StatusMethodNotAllowed, ErrorResponse{Error: "Method not allowed."})
+		return false
+	}
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, s.MaxRequestSize))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(value); err != nil {
+		code := http.StatusBadRequest
+		if strings.Contains(err.Error(), "request body too large") {
+			code = http.StatusRequestEntityTooLarge
+		}
+		writeJSON(w, code, ErrorResponse{Error: err.Error()})
+		return false
+	}
+	return true
+}
+
+// respond writes the value, or the error message of the status.
+func respond(w http.ResponseWriter, status int, value interface{}) {
+	if status != ring.Success {
+		writeJSON(w, http.StatusUnprocessableEntity, ErrorResponse{Status: status, Error: ring.ErrorMessages[status]})
+		return
+	}
+	writeJSON(w, http.StatusOK, value)
+}
+
+func defaultValue(value, defaultValue string) string {
+	if value == "" {
+		return defaultValue
+	}
+	return value
+}
+
+func (s *Server) handleFold(w http.ResponseWriter, r *http.Request) {
+	request := FoldRequest{}
+	if !s.decode(w, r, &request) {
+		return
+	}
+	status, folded := client.FoldPublicKeys(request.PublicKeys, defaultValue(request.Hash, "sha3-256"),
+		defaultValue(request.Format, "PEM"), defaultValue(request.Order, "hashes"))
+	respond(w, status, FoldResponse{FoldedPublicKeys: folded})
+}
+
+func (s *Server) handleUnfold(w http.ResponseWriter, r *http.Request) {
+	request := UnfoldRequest{}
+	if !s.decode(w, r, &request) {
+		return
+	}
+	status, publicKeys := client.UnfoldPublicKeysIntoBytes(request.FoldedPublicKeys, defaultValue(request.Format, "PEM"))
+	respond(w, status, UnfoldResponse{PublicKeys: publicKeys})
+}
+
+func (s *Server) handleDigest(w http.ResponseWriter, r *http.Request) {
+	request := DigestRequest{}
+	if !s.decode(w, r, &request) {
+		return
+	}
+	status, digest := client.PublicKeysDigest(request.FoldedPublicKeys, request.Separator)
+	respond(w, status, DigestResponse{Digest: string(digest)})
+}
+
+// readKey reads the private key of the name from the key directory. Names with path separators are refused.
+func (s *Server) readKey(name string) ([]byte, bool) {
+	if s.keyDirectory == "" || name == "" || name != filepath.Base(name) || strings.HasPrefix(name, ".") {
+		return nil, false
+	}
+	content, err := ioutil.ReadFile(filepath.Join(s.keyDirectory, name))
+	if err != nil {
+		return nil, false
+	}
+	return content, true
+}
+
+func (s *Server) handleSign(w http.ResponseWriter, r *http.Request) {
+	request := SignRequest{}
+	if !s.decode(w, r, &request) {
+		return
+	}
+	privateKey, ok := s.readKey(request.Key)
+	if !ok {
+		writeJSON(w, http.StatusNotFound, ErrorResponse{Error: "Private key not found."})
+		return
+	}
+	status, signature := client.CreateSignature(request.FoldedPublicKeys, privateKey, request.Message, request.Case, defaultValue(request.Format, "PEM"))
+	respond(w, status, SignResponse{Signature: signature})
+}
+
+func (s *Server) handleVerify(w http.ResponseWriter, r *http.Request) {
+	request := VerifyRequest{}
+	if !s.decode(w, r, &request) {
+		return
+	}
+	response := VerifyResponse{Status: client.VerifySignature(request.FoldedPublicKeys, request.Signature, request.Message, request.Case)}
+	response.Valid = response.Status == ring.Success
+	if !response.Valid {
+		response.Error = ring.ErrorMessages[response.Status]
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+func (s *Server) handleKeyImage(w http.ResponseWriter, r *http.Request) {
+	request := KeyImageRequest{}
+	if !s.decode(w, r, &request) {
+		return
+	}
+	status, keyImage := client.SignatureKeyImage(request.Signature, request.Separator)
+	respond(w, status, KeyImageResponse{KeyImage: string(keyImage)})
+}
+
+func (s *Server) handleGenkey(w http.ResponseWriter, r *http.Request) {
+	request := GenkeyRequest{}
+	if !s.decode(w, r, &request) {
+		return
+	}
+	status, privateKey := client.GeneratePrivateKey(defaultValue(request.Curve, "prime256v1"), defaultValue(request.Format, "PEM"))
+	respond(w, status, GenkeyResponse{PrivateKey: privateKey})
+}
+
+func (s *Server) handlePubout(w http.ResponseWriter, r *http.Request) {
+	request := PuboutRequest{}
+	if !s.decode(w, r, &request) {
+		return
+	}
+	status, publicKey := client.DerivePublicKey(request.PrivateKey, defaultValue(request.Format, "PEM"))
+	respond(w, status, PuboutResponse{PublicKey: publicKey})
+}
@@ -0,0 +1,174 @@
+// Package ballot defines ballots of single choice, approval and ranked choice with their canonical encoding.
+// The ballot holds indexes of the options of the schema, so all clients sign the same bytes for the same choice.
+package ballot
+
+import (
+	"bytes"
+	"encoding/asn1"
+	"encoding/json"
+	"sort"
+
+	"github.com/zbohm/lirisi/ring"
+	"golang.org/x/crypto/sha3"
+)
+
+// Kinds of ballots.
+const (
+	// Single choice ballot holds exactly one option.
+	Single = iota
+	// Approval ballot holds any number of approved options, in ascending order.
+	Approval
+	// Ranked ballot holds options in the order of preference. Not all options have to be ranked.
+	Ranked
+)
+
+// KindNames maps names of ballot kinds used in the schema.
+var KindNames = map[string]int{
+	"single":   Single,
+	"approval": Approval,
+	"ranked":   Ranked,
+}
+
+// Schema describes options of the ballot.
+type Schema struct {
+	Kind    string   `json:"kind"`
+	Options []string `json:"options"`
+}
+
+// schemaContent is the canonical form of the schema.
+type schemaContent struct {
+	Kind    int
+	Options []string
+}
+
+// Ballot holds indexes of chosen options.
+type Ballot struct {
+	Name         string
+	Version      int
+	Kind         int
+	SchemaDigest []byte
+	Choices      []int
+}
+
+// ParseSchema parses schema in JSON.
+func ParseSchema(content []byte) (int, Schema) {
+	schema := Schema{}
+	if err := json.Unmarshal(content, &schema); err != nil {
+		return ring.InvalidBallotSchema, schema
+	}
+	if _, ok := KindNames[schema.Kind]; !ok {
+		return ring.UnknownBallotKind, schema
+	}
+	if len(schema.Options) < 2 {
+		return ring.IncorrectNumberOfOptions, schema
+	}
+	seen := map[string]bool{}
+	for _, option := range schema.Options {
+		if option == "" || seen[option] {
+			return ring.InvalidBallotSchema, schema
+		}
+		seen[option] = true
+	}
+	return ring.Success, schema
+}
+
+// Digest returns SHA3-256 digest of the canonical DER encoding of the schema.
+func (s Schema) Digest() []byte {
+	content, err := asn1.Marshal(schemaContent{Kind: KindNames[s.Kind], Options: s.Options})
+	if err != nil {
+		panic(err)
+	}
+	digest := sha3.Sum256(content)
+	return digest[:]
+}
+
+// OptionIndex returns index of the option with the name, or -1.
+func (s Schema) OptionIndex(name string) int {
+	for i, option := range s.Options {
+		if option == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// checkChoices validates choices by rules of the kind of ballot.
+func checkChoices(kind, numberOfOptions int, choices []int) int {
+	chosen := map[int]bool{}
+	for _, choice := range choices {
+		if choice < 0 || choice >= numberOfOptions {
+			return ring.ChoiceOutOfRange
+		}
+		if chosen[choice] {
+			return ring.DuplicateChoice
+		}
+		chosen[choice] = true
+	}
+	switch kind {
+	case Single:
+		if len(choices) != 1 {
+			return ring.IncorrectNumberOfChoices
+		}
+	case Ranked:
+		if len(choices) == 0 {
+			return ring.IncorrectNumberOfChoices
+		}
+	case Approval:
+	default:
+		return ring.UnknownBallotKind
+	}
+	return ring.Success
+}
+
+// NewBallot creates ballot with the choices (indexes of options) in the canonical DER encoding.
+// Choices of the approval ballot are sorted.
+func NewBallot(schema Schema, choices []int) (int, []byte) {
+	kind, ok := KindNames[schema.Kind]
+	if !ok {
+		return ring.UnknownBallotKind, []byte{}
+	}
+	choices = append([]int{}, choices...)
+	if kind == Approval {
+		sort.Ints(choices)
+	}
+	if status := checkChoices(kind, len(schema.Options), choices); status != ring.Success {
+		return status, []byte{}
+	}
+	content, err := asn1.Marshal(Ballot{
+		Name:         ring.Origin + " Ballot",
+		Version:      ring.SignatureVersion,
+		Kind:         kind,
+		SchemaDigest: schema.Digest(),
+		Choices:      choices,
+	})
+	if err != nil {
+		return ring.Asn1MarshalFailed, []byte{}
+	}
+	return ring.Success, content
+}
+
+// Validate parses the ballot and checks it against the schema. Only the canonical encoding is accepted.
+func Validate(schema Schema, content []byte) (int, Ballot) {
+	ballot := Ballot{}
+	rest, err := asn1.Unmarshal(content, &ballot)
+	if err != nil {
+		return ring.Asn1UnmarshalFailed, ballot
+	}
+	if len(rest) != 0 {
+		return ring.NonCanonicalBallot, ballot
+	}
+	if ballot.Kind != KindNames[schema.Kind] || !bytes.Equal(ballot.SchemaDigest, schema.Digest()) {
+		return ring.BallotSchemaMismatch, ballot
+	}
+	if status := checkChoices(ballot.Kind, len(schema.Options), ballot.Choices); status != ring.Success {
+		return status, ballot
+	}
+	status, canonical := NewBallot(schema, ballot.Choices)
+	if status != ring.Success {
+		return status, ballot
+	}
+	if !bytes.Equal(canonical, content) {
+		return ring.NonCanonicalBallot, ballot
+	}
+	return ring.Success, ballot
+}
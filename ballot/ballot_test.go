@@ -0,0 +1,160 @@
+package ballot
+
+import (
+	"encoding/asn1"
+	"reflect"
+	"testing"
+
+	"github.com/zbohm/lirisi/client"
+	"github.com/zbohm/lirisi/internal/testring"
+	"github.com/zbohm/lirisi/ring"
+	"github.com/zbohm/lirisi/tally"
+)
+
+var caseIdentifier = []byte("election-2026")
+
+// castBallots signs ballots with choices, each by another member of the ring.
+func castBallots(t *testing.T, schema Schema, folded []byte, privateKeys [][]byte, choices [][]int) []tally.Ballot {
+	ballots := make([]tally.Ballot, len(choices))
+	for i, item := range choices {
+		status, content := NewBallot(schema, item)
+		if status != ring.Success {
+			t.Fatal(ring.ErrorMessages[status])
+		}
+		status, signature := client.CreateSignature(folded, privateKeys[i], content, caseIdentifier, "PEM")
+		if status != ring.Success {
+			t.Fatal(ring.ErrorMessages[status])
+		}
+		ballots[i] = tally.Ballot{ID: string(rune('a' + i)), Signature: signature, Message: content}
+	}
+	return ballots
+}
+
+func TestParseSchema(t *testing.T) {
+	status, schema := ParseSchema([]byte(`{"kind": "ranked", "options": ["Alice", "Bob"]}`))
+	if status != ring.Success {
+		t.Fatal(ring.ErrorMessages[status])
+	}
+	if schema.OptionIndex("Bob") != 1 || schema.OptionIndex("Carol") != -1 {
+		t.Errorf("Unexpected option index.")
+	}
+	if status, _ := ParseSchema([]byte(`{"kind": "borda", "options": ["Alice", "Bob"]}`)); status != ring.UnknownBallotKind {
+		t.Errorf("Unexpected status %s", ring.ErrorMessages[status])
+	}
+	if status, _ := ParseSchema([]byte(`{"kind": "single", "options": ["Alice"]}`)); status != ring.IncorrectNumberOfOptions {
+		t.Errorf("Unexpected status %s", ring.ErrorMessages[status])
+	}
+	if status, _ := ParseSchema([]byte(`{"kind": "single", "options": ["Alice", "Bob", "Alice"]}`)); status != ring.InvalidBallotSchema {
+		t.Errorf("Unexpected status %s", ring.ErrorMessages[status])
+	}
+	if status, _ := ParseSchema([]byte(`{"kind": "single", "options": ["Alice", ""]}`)); status != ring.InvalidBallotSchema {
+		t.Errorf("Unexpected status %s", ring.ErrorMessages[status])
+	}
+}
+
+func TestValidate(t *testing.T) {
+	schema := Schema{Kind: "approval", Options: []string{"Alice", "Bob", "Carol"}}
+	status, content := NewBallot(schema, []int{2, 0})
+	if status != ring.Success {
+		t.Fatal(ring.ErrorMessages[status])
+	}
+	status, ballot := Validate(schema, content)
+	if status != ring.Success {
+		t.Fatal(ring.ErrorMessages[status])
+	}
+	if !reflect.DeepEqual(ballot.Choices, []int{0, 2}) {
+		t.Errorf("Unexpected choices %v.", ballot.Choices)
+	}
+
+	unsorted, err := asn1.Marshal(Ballot{
+		Name:         ballot.Name,
+		Version:      ballot.Version,
+		Kind:         ballot.Kind,
+		SchemaDigest: ballot.SchemaDigest,
+		Choices:      []int{2, 0},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status, _ := Validate(schema, unsorted); status != ring.NonCanonicalBallot {
+		t.Errorf("Unsorted approvals: %s", ring.ErrorMessages[status])
+	}
+	if status, _ := Validate(Schema{Kind: "approval", Options: []string{"Alice", "Bob", "Dave"}}, content); status != ring.BallotSchemaMismatch {
+		t.Errorf("Other schema: %s", ring.ErrorMessages[status])
+	}
+	if status, _ := NewBallot(schema, []int{1, 1}); status != ring.DuplicateChoice {
+		t.Errorf("Duplicate choice: %s", ring.ErrorMessages[status])
+	}
+	if status, _ := NewBallot(Schema{Kind: "single", Options: schema.Options}, []int{0, 1}); status != ring.IncorrectNumberOfChoices {
+		t.Errorf("Two single choices: %s", ring.ErrorMessages[status])
+	}
+	if status, _ := NewBallot(schema, []int{3}); status != ring.ChoiceOutOfRange {
+		t.Errorf("Choice out of range: %s", ring.ErrorMessages[status])
+	}
+}
+
+func TestCountPlurality(t *testing.T) {
+	privateKeys, folded := testring.Create(t, 4)
+	schema := Schema{Kind: "single", Options: []string{"Alice", "Bob", "Carol"}}
+	ballots := castBallots(t, schema, folded, privateKeys, [][]int{{1}, {0}, {1}})
+	ballots = append(ballots, tally.Ballot{ID: "junk", Signature: ballots[0].Signature, Message: []byte("Bob")})
+
+	status, result := Count(schema, folded, caseIdentifier, ballots, tally.FirstWins)
+	if status != ring.Success {
+		t.Fatal(ring.ErrorMessages[status])
+	}
+	if !reflect.DeepEqual(result.Counts, []int{1, 2, 0}) || result.Winner != "Bob" {
+		t.Errorf("Unexpected result %v, winner %s.", result.Counts, result.Winner)
+	}
+	if len(result.Audit.Invalid) != 1 || result.Audit.Invalid[0].ID != "junk" {
+		t.Errorf("Unexpected invalid ballots %v.", result.Audit.Invalid)
+	}
+}
+
+func TestCountApproval(t *testing.T) {
+	privateKeys, folded := testring.Create(t, 3)
+	schema := Schema{Kind: "approval", Options: []string{"Alice", "Bob", "Carol"}}
+	ballots := castBallots(t, schema, folded, privateKeys, [][]int{{0, 1}, {1, 2}, {}})
+
+	status, result := Count(schema, folded, caseIdentifier, ballots, tally.FirstWins)
+	if status != ring.Success {
+		t.Fatal(ring.ErrorMessages[status])
+	}
+	if !reflect.DeepEqual(result.Counts, []int{1, 2, 1}) || result.Winner != "Bob" {
+		t.Errorf("Unexpected result %v, winner %s.", result.Counts, result.Winner)
+	}
+}
+
+func TestCountInstantRunoff(t *testing.T) {
+	privateKeys, folded := testring.Create(t, 5)
+	schema := Schema{Kind: "ranked", Options: []string{"Alice", "Bob", "Carol"}}
+	ballots := castBallots(t, schema, folded, privateKeys, [][]int{{0}, {0, 2}, {1}, {1, 0}, {2, 1}})
+
+	status, result := Count(schema, folded, caseIdentifier, ballots, tally.FirstWins)
+	if status != ring.Success {
+		t.Fatal(ring.ErrorMessages[status])
+	}
+	expected := []Round{
+		{Counts: []int{2, 2, 1}, Eliminated: "Carol"},
+		{Counts: []int{2, 3, 0}},
+	}
+	if !reflect.DeepEqual(result.Rounds, expected) || result.Winner != "Bob" {
+		t.Errorf("Unexpected rounds %v, winner %s.", result.Rounds, result.Winner)
+	}
+}
+
+func TestInstantRunoffTie(t *testing.T) {
+	options := []string{"Alice", "Bob", "Carol"}
+	rounds, winner := instantRunoff(options, [][]int{{0}, {0}, {1}, {1}, {2}})
+	expected := []Round{
+		{Counts: []int{2, 2, 1}, Eliminated: "Carol"},
+		{Counts: []int{2, 2, 0}},
+	}
+	if !reflect.DeepEqual(rounds, expected) || winner != "" {
+		t.Errorf("Unexpected rounds %v, winner %s.", rounds, winner)
+	}
+	rounds, winner = instantRunoff(options, [][]int{{0}, {1}, {2}})
+	if len(rounds) != 1 || winner != "" {
+		t.Errorf("Unexpected rounds %v, winner %s.", rounds, winner)
+	}
+}
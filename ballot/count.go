@@ -0,0 +1,128 @@
+package ballot
+
+import (
+	"github.com/zbohm/lirisi/ring"
+	"github.com/zbohm/lirisi/tally"
+)
+
+// Round of the instant runoff. Counts of eliminated options are zero.
+type Round struct {
+	Counts     []int  `json:"counts"`
+	Eliminated string `json:"eliminated,omitempty"`
+}
+
+// Result of counting of ballots. Winner is empty in case of a tie.
+type Result struct {
+	Kind    string       `json:"kind"`
+	Options []string     `json:"options"`
+	Counts  []int        `json:"counts"`
+	Rounds  []Round      `json:"rounds,omitempty"`
+	Winner  string       `json:"winner,omitempty"`
+	Audit   tally.Result `json:"audit"`
+}
+
+// Count validates ballots against the schema, verifies their signatures and counts the ballots.
+// Linked ballots are resolved by the policy. Single choice ballots are counted by plurality,
+// approval ballots by approvals and ranked ballots by instant runoff.
+func Count(schema Schema, foldedPublicKeys, caseIdentifier []byte, ballots []tally.Ballot, policy tally.Policy) (int, Result) {
+	result := Result{Kind: schema.Kind, Options: schema.Options, Counts: make([]int, len(schema.Options))}
+
+	// Invalid ballots are excluded before linked ballots are resolved,
+	// so they do not displace a valid ballot of the same member.
+	invalid := []tally.Rejection{}
+	valid := []tally.Ballot{}
+	parsed := map[string]Ballot{}
+	for _, item := range ballots {
+		status, ballot := Validate(schema, item.Message)
+		if status != ring.Success {
+			invalid = append(invalid, tally.Rejection{ID: item.ID, Reason: ring.ErrorMessages[status]})
+			continue
+		}
+		parsed[item.ID] = ballot
+		valid = append(valid, item)
+	}
+	status, audit := tally.Count(foldedPublicKeys, caseIdentifier, valid, policy)
+	if status != ring.Success {
+		return status, result
+	}
+	audit.Counts = []tally.MessageCount{}
+	audit.Invalid = append(invalid, audit.Invalid...)
+	result.Audit = audit
+
+	counted := make([][]int, len(audit.Accepted))
+	for i, id := range audit.Accepted {
+		counted[i] = parsed[id].Choices
+	}
+	if schema.Kind == "ranked" {
+		result.Rounds, result.Winner = instantRunoff(schema.Options, counted)
+		result.Counts = result.Rounds[len(result.Rounds)-1].Counts
+		return ring.Success, result
+	}
+	for _, choices := range counted {
+		for _, choice := range choices {
+			result.Counts[choice]++
+		}
+	}
+	result.Winner = winner(schema.Options, result.Counts)
+	return ring.Success, result
+}
+
+// winner returns option with the most votes, or empty string in case of a tie.
+func winner(options []string, counts []int) string {
+	best, name := 0, ""
+	for i, count := range counts {
+		if count > best {
+			best, name = count, options[i]
+		} else if count == best {
+			name = ""
+		}
+	}
+	return name
+}
+
+// instantRunoff counts first preferences among remaining options. The option with a majority
+// of non-exhausted ballots wins, otherwise the option with the fewest votes is eliminated.
+// A tie for the fewest votes eliminates the option listed later in the schema. If all remaining
+// options are tied, there is no winner.
+func instantRunoff(options []string, ballots [][]int) ([]Round, string) {
+	rounds := []Round{}
+	remaining := make([]bool, len(options))
+	for i := range remaining {
+		remaining[i] = true
+	}
+	for numberOfRemaining := len(options); ; numberOfRemaining-- {
+		counts := make([]int, len(options))
+		active := 0
+		for _, choices := range ballots {
+			for _, choice := range choices {
+				if remaining[choice] {
+					counts[choice]++
+					active++
+					break
+				}
+			}
+		}
+		round := Round{Counts: counts}
+		lowest, highest := -1, -1
+		for i, count := range counts {
+			if !remaining[i] {
+				continue
+			}
+			if 2*count > active || (numberOfRemaining == 1 && active > 0) {
+				return append(rounds, round), options[i]
+			}
+			if lowest == -1 || count <= counts[lowest] {
+				lowest = i
+			}
+			if highest == -1 || count > counts[highest] {
+				highest = i
+			}
+		}
+		if numberOfRemaining == 1 || active == 0 || counts[lowest] == counts[highest] {
+			return append(rounds, round), ""
+		}
+		remaining[lowest] = false
+		round.Eliminated = options[lowest]
+		rounds = append(rounds, round)
+	}
+}
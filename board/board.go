@@ -0,0 +1,253 @@
+// Package board is an append-only bulletin board of ring signatures. Entries form a Merkle tree,
+// so the operator can sign the tree head and prove that an entry is in the board
+// and that the board only grew since an earlier tree head.
+package board
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"encoding/asn1"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"os"
+	"sync"
+
+	"github.com/zbohm/lirisi/ring"
+	"golang.org/x/crypto/sha3"
+)
+
+// PEM block types.
+const (
+	TreeHeadBlock         = "SIGNED TREE HEAD"
+	InclusionProofBlock   = "INCLUSION PROOF"
+	ConsistencyProofBlock = "CONSISTENCY PROOF"
+)
+
+// ErrOutOfRange is returned for index or tree size out of the board.
+var ErrOutOfRange = errors.New("board: index or tree size out of range")
+
+// Entry holds ring signature with the message and case identifier it was made for.
+type Entry struct {
+	Signature []byte `json:"signature"`
+	Message   []byte `json:"message"`
+	Case      []byte `json:"case"`
+}
+
+// LeafHash returns hash of the entry in the Merkle tree.
+func (e Entry) LeafHash() []byte {
+	content, err := asn1.Marshal(e)
+	if err != nil {
+		panic(err)
+	}
+	return LeafHash(content)
+}
+
+// TreeHead holds root hash of the board of the size at the time.
+type TreeHead struct {
+	Name      string
+	Version   int
+	TreeSize  int
+	Timestamp int64
+	RootHash  []byte
+}
+
+// SignedTreeHead holds tree head signed by the operator of the board (ECDSA over SHA3-256 of the DER of the head).
+type SignedTreeHead struct {
+	Head      TreeHead
+	Signature []byte
+}
+
+// InclusionProof holds audit path of the entry.
+type InclusionProof struct {
+	LeafIndex int
+	TreeSize  int
+	Path      [][]byte
+}
+
+// ConsistencyProof holds proof that the tree of the second size contains the tree of the first size.
+type ConsistencyProof struct {
+	FirstSize  int
+	SecondSize int
+	Path       [][]byte
+}
+
+type ecdsaSignature struct {
+	R, S *big.Int
+}
+
+// Board is an append-only file of entries, one JSON entry per line. It is safe for concurrent use.
+type Board struct {
+	handle  *os.File
+	entries []Entry
+	leaves  [][]byte
+	mutex   sync.RWMutex
+}
+
+// Open opens or creates the board file and loads entries stored in it.
+func Open(path string) (*Board, error) {
+	handle, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	b := &Board{handle: handle}
+	scanner := bufio.NewScanner(handle)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		entry := Entry{}
+		if err := json.Unmarshal(line, &entry); err != nil {
+			handle.Close()
+			return nil, err
+		}
+		b.entries = append(b.entries, entry)
+		b.leaves = append(b.leaves, entry.LeafHash())
+	}
+	if err := scanner.Err(); err != nil {
+		handle.Close()
+		return nil, err
+	}
+	if _, err := handle.Seek(0, os.SEEK_END); err != nil {
+		handle.Close()
+		return nil, err
+	}
+	return b, nil
+}
+
+// Close closes the board file.
+func (b *Board) Close() error {
+	return b.handle.Close()
+}
+
+// Append writes the entry at the end of the board and returns its index.
+func (b *Board) Append(entry Entry) (int, error) {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return -1, err
+	}
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if _, err := b.handle.Write(append(line, '\n')); err != nil {
+		return -1, err
+	}
+	if err := b.handle.Sync(); err != nil {
+		return -1, err
+	}
+	b.entries = append(b.entries, entry)
+	b.leaves = append(b.leaves, entry.LeafHash())
+	return len(b.entries) - 1, nil
+}
+
+// Size returns number of entries.
+func (b *Board) Size() int {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+	return len(b.entries)
+}
+
+// Entries returns all entries in the order they were appended.
+func (b *Board) Entries() []Entry {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+	return append([]Entry{}, b.entries...)
+}
+
+// Find returns index of the first entry with the signature, or -1.
+func (b *Board) Find(signature []byte) int {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+	for i, entry := range b.entries {
+		if bytes.Equal(entry.Signature, signature) {
+			return i
+		}
+	}
+	return -1
+}
+
+// Head returns tree head of the board of the size.
+func (b *Board) Head(size int, timestamp int64) (TreeHead, error) {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+	if size < 0 || size > len(b.leaves) {
+		return TreeHead{}, ErrOutOfRange
+	}
+	return TreeHead{
+		Name:      ring.Origin + " Tree head",
+		Version:   ring.SignatureVersion,
+		TreeSize:  size,
+		Timestamp: timestamp,
+		RootHash:  RootHash(b.leaves[:size]),
+	}, nil
+}
+
+// InclusionProof returns proof that the entry of the index is in the board of the size.
+func (b *Board) InclusionProof(index, size int) (InclusionProof, error) {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+	if size > len(b.leaves) || index < 0 || index >= size {
+		return InclusionProof{}, ErrOutOfRange
+	}
+	return InclusionProof{LeafIndex: index, TreeSize: size, Path: inclusionPath(index, b.leaves[:size])}, nil
+}
+
+// ConsistencyProof returns proof that the board of the second size contains the board of the first size.
+func (b *Board) ConsistencyProof(firstSize, secondSize int) (ConsistencyProof, error) {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+	if firstSize < 0 || firstSize > secondSize || secondSize > len(b.leaves) {
+		return ConsistencyProof{}, ErrOutOfRange
+	}
+	path := [][]byte{}
+	if firstSize > 0 && firstSize < secondSize {
+		path = consistencyPath(firstSize, b.leaves[:secondSize], true)
+	}
+	return ConsistencyProof{FirstSize: firstSize, SecondSize: secondSize, Path: path}, nil
+}
+
+func treeHeadDigest(head TreeHead) []byte {
+	content, err := asn1.Marshal(head)
+	if err != nil {
+		panic(err)
+	}
+	digest := sha3.Sum256(content)
+	return digest[:]
+}
+
+// SignTreeHead signs the tree head by the private key of the operator.
+func SignTreeHead(head TreeHead, privateKey *ecdsa.PrivateKey) (SignedTreeHead, error) {
+	r, s, err := ecdsa.Sign(rand.Reader, privateKey, treeHeadDigest(head))
+	if err != nil {
+		return SignedTreeHead{}, err
+	}
+	signature, err := asn1.Marshal(ecdsaSignature{R: r, S: s})
+	if err != nil {
+		return SignedTreeHead{}, err
+	}
+	return SignedTreeHead{Head: head, Signature: signature}, nil
+}
+
+// VerifyTreeHead verifies signature of the tree head by the public key of the operator.
+func VerifyTreeHead(signed SignedTreeHead, publicKey *ecdsa.PublicKey) bool {
+	signature := ecdsaSignature{}
+	rest, err := asn1.Unmarshal(signed.Signature, &signature)
+	if err != nil || len(rest) != 0 {
+		return false
+	}
+	return ecdsa.Verify(publicKey, treeHeadDigest(signed.Head), signature.R, signature.S)
+}
+
+// Verify verifies the proof that the entry is in the board with the tree head.
+func (p InclusionProof) Verify(entry Entry, head TreeHead) bool {
+	return p.TreeSize == head.TreeSize && VerifyInclusion(entry.LeafHash(), p.LeafIndex, p.TreeSize, p.Path, head.RootHash)
+}
+
+// Verify verifies the proof that the board with the second tree head contains the board with the first tree head.
+func (p ConsistencyProof) Verify(first, second TreeHead) bool {
+	return p.FirstSize == first.TreeSize && p.SecondSize == second.TreeSize &&
+		VerifyConsistency(p.FirstSize, p.SecondSize, p.Path, first.RootHash, second.RootHash)
+}
@@ -0,0 +1,161 @@
+package board
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+func createEntries(size int) []Entry {
+	entries := make([]Entry, size)
+	for i := range entries {
+		entries[i] = Entry{
+			Signature: []byte("signature " + strconv.Itoa(i)),
+			Message:   []byte("message " + strconv.Itoa(i)),
+			Case:      []byte("election-2026"),
+		}
+	}
+	return entries
+}
+
+func openBoard(t *testing.T, size int) (*Board, string) {
+	dir, err := ioutil.TempDir("", "board")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "board.log")
+	b, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, entry := range createEntries(size) {
+		index, err := b.Append(entry)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if index != i {
+			t.Errorf("Unexpected index %d of entry %d.", index, i)
+		}
+	}
+	return b, dir
+}
+
+func TestInclusionProofs(t *testing.T) {
+	b, dir := openBoard(t, 13)
+	defer os.RemoveAll(dir)
+	defer b.Close()
+	entries := b.Entries()
+
+	for size := 1; size <= len(entries); size++ {
+		head, err := b.Head(size, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for index := 0; index < size; index++ {
+			proof, err := b.InclusionProof(index, size)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !proof.Verify(entries[index], head) {
+				t.Errorf("Inclusion proof of %d in %d is not valid.", index, size)
+			}
+			other := (index + 1) % len(entries)
+			if other != index && proof.Verify(entries[other], head) {
+				t.Errorf("Inclusion proof of %d in %d verifies entry %d.", index, size, other)
+			}
+		}
+	}
+	if _, err := b.InclusionProof(13, 13); err != ErrOutOfRange {
+		t.Errorf("Unexpected error %v.", err)
+	}
+}
+
+func TestConsistencyProofs(t *testing.T) {
+	b, dir := openBoard(t, 13)
+	defer os.RemoveAll(dir)
+	defer b.Close()
+
+	for second := 0; second <= 13; second++ {
+		secondHead, err := b.Head(second, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for first := 0; first <= second; first++ {
+			firstHead, err := b.Head(first, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			proof, err := b.ConsistencyProof(first, second)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !proof.Verify(firstHead, secondHead) {
+				t.Errorf("Consistency proof of %d and %d is not valid.", first, second)
+			}
+			if first > 0 && first < second {
+				forged := firstHead
+				forged.RootHash = LeafHash([]byte("forged"))
+				if proof.Verify(forged, secondHead) {
+					t.Errorf("Consistency proof of %d and %d verifies forged head.", first, second)
+				}
+			}
+		}
+	}
+}
+
+func TestReopenBoard(t *testing.T) {
+	b, dir := openBoard(t, 5)
+	defer os.RemoveAll(dir)
+	head, err := b.Head(5, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Close(); err != nil {
+		t.Fatal(err)
+	}
+	b, err = Open(filepath.Join(dir, "board.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.Close()
+	reopened, err := b.Head(b.Size(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(head.RootHash) != string(reopened.RootHash) {
+		t.Errorf("Root hash changed after reopening.")
+	}
+	if b.Find([]byte("signature 3")) != 3 || b.Find([]byte("signature 9")) != -1 {
+		t.Errorf("Unexpected index of the signature.")
+	}
+}
+
+func TestSignedTreeHead(t *testing.T) {
+	b, dir := openBoard(t, 3)
+	defer os.RemoveAll(dir)
+	defer b.Close()
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	head, err := b.Head(3, 1700000000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signed, err := SignTreeHead(head, privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !VerifyTreeHead(signed, &privateKey.PublicKey) {
+		t.Errorf("Signed tree head is not valid.")
+	}
+	signed.Head.TreeSize = 2
+	if VerifyTreeHead(signed, &privateKey.PublicKey) {
+		t.Errorf("Changed tree head is valid.")
+	}
+}
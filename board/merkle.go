@@ -0,0 +1,151 @@
+// # Merkle tree of the bulletin board.
+
+// The tree follows RFC 6962 (Certificate Transparency) with hash function SHA3-256.
+// Leaf hash is *H(0x00 || entry)* and node hash is *H(0x01 || left || right)*.
+// Tree of *n* leaves is split at *k*, the largest power of two smaller than *n*.
+//
+// Inclusion proof of the leaf *m* is the audit path of sibling hashes from the leaf to the root.
+// Consistency proof of the trees of sizes *m < n* holds hashes needed to compute both roots,
+// so the tree of size *n* contains the tree of size *m* unchanged.
+// Both proofs are verified by the algorithms of RFC 9162.
+
+package board
+
+import (
+	"bytes"
+
+	"golang.org/x/crypto/sha3"
+)
+
+// LeafHash returns hash of the leaf.
+func LeafHash(content []byte) []byte {
+	digest := sha3.Sum256(append([]byte{0}, content...))
+	return digest[:]
+}
+
+// nodeHash returns hash of the inner node.
+func nodeHash(left, right []byte) []byte {
+	buff := append([]byte{1}, left...)
+	digest := sha3.Sum256(append(buff, right...))
+	return digest[:]
+}
+
+// splitPoint returns the largest power of two smaller than n.
+func splitPoint(n int) int {
+	k := 1
+	for k<<1 < n {
+		k <<= 1
+	}
+	return k
+}
+
+// RootHash returns root hash of the tree of the leaf hashes.
+func RootHash(leaves [][]byte) []byte {
+	switch len(leaves) {
+	case 0:
+		digest := sha3.Sum256([]byte{})
+		return digest[:]
+	case 1:
+		return leaves[0]
+	}
+	k := splitPoint(len(leaves))
+	return nodeHash(RootHash(leaves[:k]), RootHash(leaves[k:]))
+}
+
+// inclusionPath returns audit path of the leaf m in the tree of leaf hashes.
+func inclusionPath(m int, leaves [][]byte) [][]byte {
+	if len(leaves) <= 1 {
+		return [][]byte{}
+	}
+	k := splitPoint(len(leaves))
+	if m < k {
+		return append(inclusionPath(m, leaves[:k]), RootHash(leaves[k:]))
+	}
+	return append(inclusionPath(m-k, leaves[k:]), RootHash(leaves[:k]))
+}
+
+// consistencyPath returns proof that the first m leaves form the tree of size m.
+func consistencyPath(m int, leaves [][]byte, complete bool) [][]byte {
+	n := len(leaves)
+	if m == n {
+		if complete {
+			return [][]byte{}
+		}
+		return [][]byte{RootHash(leaves)}
+	}
+	k := splitPoint(n)
+	if m <= k {
+		return append(consistencyPath(m, leaves[:k], complete), RootHash(leaves[k:]))
+	}
+	return append(consistencyPath(m-k, leaves[k:], false), RootHash(leaves[:k]))
+}
+
+// VerifyInclusion verifies the audit path of the leaf in the tree with the root hash.
+func VerifyInclusion(leafHash []byte, leafIndex, treeSize int, path [][]byte, rootHash []byte) bool {
+	if leafIndex < 0 || leafIndex >= treeSize {
+		return false
+	}
+	fn, sn := leafIndex, treeSize-1
+	r := leafHash
+	for _, p := range path {
+		if sn == 0 {
+			return false
+		}
+		if fn&1 == 1 || fn == sn {
+			r = nodeHash(p, r)
+			for fn&1 == 0 && fn != 0 {
+				fn >>= 1
+				sn >>= 1
+			}
+		} else {
+			r = nodeHash(r, p)
+		}
+		fn >>= 1
+		sn >>= 1
+	}
+	return sn == 0 && bytes.Equal(r, rootHash)
+}
+
+// VerifyConsistency verifies that the tree of the second size with the second root hash
+// contains the tree of the first size with the first root hash.
+func VerifyConsistency(firstSize, secondSize int, path [][]byte, firstHash, secondHash []byte) bool {
+	if firstSize < 0 || firstSize > secondSize {
+		return false
+	}
+	if firstSize == secondSize {
+		return len(path) == 0 && bytes.Equal(firstHash, secondHash)
+	}
+	if firstSize == 0 {
+		return len(path) == 0
+	}
+	if len(path) == 0 {
+		return false
+	}
+	if firstSize&(firstSize-1) == 0 {
+		path = append([][]byte{firstHash}, path...)
+	}
+	fn, sn := firstSize-1, secondSize-1
+	for fn&1 == 1 {
+		fn >>= 1
+		sn >>= 1
+	}
+	fr, sr := path[0], path[0]
+	for _, c := range path[1:] {
+		if sn == 0 {
+			return false
+		}
+		if fn&1 == 1 || fn == sn {
+			fr = nodeHash(c, fr)
+			sr = nodeHash(c, sr)
+			for fn&1 == 0 && fn != 0 {
+				fn >>= 1
+				sn >>= 1
+			}
+		} else {
+			sr = nodeHash(sr, c)
+		}
+		fn >>= 1
+		sn >>= 1
+	}
+	return sn == 0 && bytes.Equal(fr, firstHash) && bytes.Equal(sr, secondHash)
+}
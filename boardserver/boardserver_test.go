@@ -0,0 +1,154 @@
+package boardserver
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/zbohm/lirisi/client"
+	"github.com/zbohm/lirisi/internal/testring"
+	"github.com/zbohm/lirisi/registry"
+	"github.com/zbohm/lirisi/ring"
+	"github.com/zbohm/lirisi/tally"
+)
+
+var caseIdentifier = []byte("election-2026")
+
+func sign(t *testing.T, folded, privateKey []byte, message, outFormat string) []byte {
+	status, signature := client.CreateSignature(folded, privateKey, []byte(message), caseIdentifier, outFormat)
+	if status != ring.Success {
+		t.Fatal(ring.ErrorMessages[status])
+	}
+	return signature
+}
+
+func startServer(t *testing.T, folded []byte, storage registry.Storage, rejectLinked bool) *httptest.Server {
+	status, server := New(folded, caseIdentifier, storage, rejectLinked)
+	if status != ring.Success {
+		t.Fatal(ring.ErrorMessages[status])
+	}
+	return httptest.NewServer(server)
+}
+
+func post(t *testing.T, url string, submission Submission, expected int) SubmissionResult {
+	body, err := json.Marshal(submission)
+	if err != nil {
+		t.Fatal(err)
+	}
+	response, err := http.Post(url+"/submissions", "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+	result := SubmissionResult{}
+	if err := json.NewDecoder(response.Body).Decode(&result); err != nil {
+		t.Fatal(err)
+	}
+	if response.StatusCode != expected {
+		t.Errorf("Unexpected status code %d, expected %d (%s).", response.StatusCode, expected, result.Error)
+	}
+	return result
+}
+
+func get(t *testing.T, url string, expected int, value interface{}) {
+	response, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode != expected {
+		t.Fatalf("Unexpected status code %d of %s, expected %d.", response.StatusCode, url, expected)
+	}
+	if value != nil {
+		if err := json.NewDecoder(response.Body).Decode(value); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestFlagLinked(t *testing.T) {
+	privateKeys, folded := testring.Create(t, 3)
+	server := startServer(t, folded, registry.NewMemoryStorage(), false)
+	defer server.Close()
+
+	first := post(t, server.URL, Submission{Signature: string(sign(t, folded, privateKeys[0], "yes", "PEM")), Message: "yes"}, http.StatusCreated)
+	der := sign(t, folded, privateKeys[1], "no", "DER")
+	post(t, server.URL, Submission{Signature: base64.StdEncoding.EncodeToString(der), MessageBase64: base64.StdEncoding.EncodeToString([]byte("no"))}, http.StatusCreated)
+	post(t, server.URL, Submission{Signature: string(sign(t, folded, privateKeys[0], "yes", "PEM")), Message: "yes"}, http.StatusOK)
+	post(t, server.URL, Submission{Signature: string(sign(t, folded, privateKeys[0], "no", "PEM")), Message: "no"}, http.StatusAccepted)
+	invalid := post(t, server.URL, Submission{Signature: string(sign(t, folded, privateKeys[2], "yes", "PEM")), Message: "no"}, http.StatusBadRequest)
+	if invalid.Outcome != registry.Invalid.String() || invalid.Error == "" {
+		t.Errorf("Unexpected result of invalid signature %+v.", invalid)
+	}
+
+	submissions := []StoredSubmission{}
+	get(t, server.URL+"/submissions", http.StatusOK, &submissions)
+	if len(submissions) != 3 {
+		t.Fatalf("Unexpected number of submissions %d.", len(submissions))
+	}
+	if !submissions[0].Linked || submissions[1].Linked || !submissions[2].Linked {
+		t.Errorf("Unexpected linked flags %+v.", submissions)
+	}
+
+	linked := []StoredSubmission{}
+	get(t, server.URL+"/key-images/"+first.KeyImage, http.StatusOK, &linked)
+	if len(linked) != 2 {
+		t.Errorf("Unexpected number of linked submissions %d.", len(linked))
+	}
+	get(t, server.URL+"/key-images/00", http.StatusNotFound, nil)
+
+	result := tally.Result{}
+	get(t, server.URL+"/tally?policy=discard-all-linked", http.StatusOK, &result)
+	if len(result.Counts) != 1 || result.Counts[0].Message != "no" || result.Counts[0].Count != 1 {
+		t.Errorf("Unexpected counts %+v.", result.Counts)
+	}
+	get(t, server.URL+"/tally?policy=unknown", http.StatusBadRequest, nil)
+}
+
+func TestRejectLinked(t *testing.T) {
+	dir, err := ioutil.TempDir("", "boardserver")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	storage, err := registry.OpenFileStorage(filepath.Join(dir, "board.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer storage.Close()
+
+	privateKeys, folded := testring.Create(t, 2)
+	server := startServer(t, folded, storage, true)
+	defer server.Close()
+
+	post(t, server.URL, Submission{Signature: string(sign(t, folded, privateKeys[0], "yes", "PEM")), Message: "yes"}, http.StatusCreated)
+	post(t, server.URL, Submission{Signature: string(sign(t, folded, privateKeys[0], "no", "PEM")), Message: "no"}, http.StatusConflict)
+
+	submissions := []StoredSubmission{}
+	get(t, server.URL+"/submissions", http.StatusOK, &submissions)
+	if len(submissions) != 1 || submissions[0].Linked {
+		t.Errorf("Unexpected submissions %+v.", submissions)
+	}
+}
+
+func TestRequestTooLarge(t *testing.T) {
+	_, folded := testring.Create(t, 2)
+	server := startServer(t, folded, registry.NewMemoryStorage(), false)
+	defer server.Close()
+
+	body := []byte(`{"signature": "` + string(bytes.Repeat([]byte("A"), MaxRequestSize)) + `"}`)
+	response, err := http.Post(server.URL+"/submissions", "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	response.Body.Close()
+	if response.StatusCode != http.StatusBadRequest {
+		t.Errorf("Unexpected status code %d.", response.StatusCode)
+	}
+}
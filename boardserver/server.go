@@ -0,0 +1,249 @@
+// Package boardserver is an HTTP bulletin board of ring signatures for one ring and case.
+// It verifies submitted signatures, rejects or flags linked ones, persists them in the registry and counts them.
+//
+// Endpoints:
+//
+//	POST /submissions          - Submit {"signature": PEM or base64 DER, "message": text or "message_base64": base64}.
+//	GET  /submissions          - List stored submissions.
+//	GET  /key-images/{hex}     - Submissions with the key image.
+//	GET  /tally?policy=NAME    - Counts of messages, see package tally for policies.
+package boardserver
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/zbohm/lirisi/client"
+	"github.com/zbohm/lirisi/registry"
+	"github.com/zbohm/lirisi/ring"
+	"github.com/zbohm/lirisi/tally"
+)
+
+// MaxRequestSize limits size of the body of the submission.
+const MaxRequestSize = 1 << 20
+
+// Server of the bulletin board.
+type Server struct {
+	foldedPublicKeys []byte
+	caseIdentifier   []byte
+	ringDigest       string
+	registry         *registry.Registry
+	mux              *http.ServeMux
+}
+
+// Submission is the body of POST /submissions.
+type Submission struct {
+	Signature     string `json:"signature"`
+	Message       string `json:"message"`
+	MessageBase64 string `json:"message_base64,omitempty"`
+}
+
+// SubmissionResult is the response to POST /submissions.
+type SubmissionResult struct {
+	Outcome  string `json:"outcome"`
+	KeyImage string `json:"key_image,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// StoredSubmission is the item of the list of submissions.
+type StoredSubmission struct {
+	KeyImage      string `json:"key_image"`
+	Message       string `json:"message"`
+	MessageDigest string `json:"message_digest"`
+	Signature     []byte `json:"signature"`
+	Linked        bool   `json:"linked"`
+}
+
+// New creates server of the board for the folded public keys and case identifier.
+// With rejectLinked set, signatures linked with a stored signature of another message are rejected,
+// otherwise they are stored and flagged as linked.
+func New(foldedPublicKeys, caseIdentifier []byte, storage registry.Storage, rejectLinked bool) (int, *Server) {
+	status, publicKeys, foldedKeys := client.UnfoldPublicKeysContent(foldedPublicKeys)
+	if status != ring.Success {
+		return status, nil
+	}
+	status, fc := ring.GetFactoryContext(foldedKeys.CurveOID, foldedKeys.HasherOID)
+	if status != ring.Success {
+		return status, nil
+	}
+	s := &Server{
+		foldedPublicKeys: foldedPublicKeys,
+		caseIdentifier:   caseIdentifier,
+		ringDigest:       hex.EncodeToString(fc.PublicKeysDigest(publicKeys)),
+		registry:         registry.NewRegistry(storage),
+		mux:              http.NewServeMux(),
+	}
+	s.registry.RejectConflicting = rejectLinked
+	s.mux.HandleFunc("/submissions", s.handleSubmissions)
+	s.mux.HandleFunc("/key-images/", s.handleKeyImage)
+	s.mux.HandleFunc("/tally", s.handleTally)
+	return ring.Success, s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+func writeJSON(w http.ResponseWriter, code int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func writeError(w http.ResponseWriter, code int, message string) {
+	writeJSON(w, code, map[string]string{"error": message})
+}
+
+func (s *Server) handleSubmissions(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		s.submit(w, r)
+	case http.MethodGet:
+		s.list(w)
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed.")
+	}
+}
+
+// decodeSignature returns signature in PEM as is and decodes signature in DER from base64.
+func decodeSignature(signature string) ([]byte, error) {
+	if strings.Contains(signature, "-----BEGIN") {
+		return []byte(signature), nil
+	}
+	return base64.StdEncoding.DecodeString(strings.TrimSpace(signature))
+}
+
+func (s *Server) submit(w http.ResponseWriter, r *http.Request) {
+	submission := Submission{}
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, MaxRequestSize))
+	if err := decoder.Decode(&submission); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	signature, err := decodeSignature(submission.Signature)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	message := []byte(submission.Message)
+	if submission.MessageBase64 != "" {
+		message, err = base64.StdEncoding.DecodeString(submission.MessageBase64)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	status, outcome := s.registry.Submit(signature, s.foldedPublicKeys, message, s.caseIdentifier)
+	result := SubmissionResult{Outcome: outcome.String()}
+	if status == ring.StorageFailure {
+		result.Error = ring.ErrorMessages[status]
+		writeJSON(w, http.StatusInternalServerError, result)
+		return
+	}
+	if status != ring.Success {
+		result.Error = ring.ErrorMessages[status]
+		writeJSON(w, http.StatusBadRequest, result)
+		return
+	}
+	_, keyImage := client.SignatureKeyImage(signature, false)
+	result.KeyImage = string(keyImage)
+
+	switch {
+	case outcome == registry.New:
+		writeJSON(w, http.StatusCreated, result)
+	case outcome == registry.Conflicting && s.registry.RejectConflicting:
+		result.Error = "The signer has already submitted another message."
+		writeJSON(w, http.StatusConflict, result)
+	case outcome == registry.Conflicting:
+		writeJSON(w, http.StatusAccepted, result)
+	default:
+		writeJSON(w, http.StatusOK, result)
+	}
+}
+
+// storedSubmissions converts entries of the registry and flags entries with the same key image.
+func storedSubmissions(entries []registry.Entry) []StoredSubmission {
+	counts := map[string]int{}
+	for _, entry := range entries {
+		counts[entry.KeyImage]++
+	}
+	submissions := make([]StoredSubmission, len(entries))
+	for i, entry := range entries {
+		submissions[i] = StoredSubmission{
+			KeyImage:      entry.KeyImage,
+			Message:       string(entry.Message),
+			MessageDigest: entry.MessageDigest,
+			Signature:     entry.Signature,
+			Linked:        counts[entry.KeyImage] > 1,
+		}
+	}
+	return submissions
+}
+
+func (s *Server) list(w http.ResponseWriter) {
+	entries, err := s.registry.Entries()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, storedSubmissions(entries))
+}
+
+func (s *Server) handleKeyImage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", "GET")
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed.")
+		return
+	}
+	keyImage := strings.ToLower(strings.Replace(strings.TrimPrefix(r.URL.Path, "/key-images/"), ":", "", -1))
+	entries, err := s.registry.Lookup(s.ringDigest, hex.EncodeToString(s.caseIdentifier), keyImage)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if len(entries) == 0 {
+		writeError(w, http.StatusNotFound, "Key image not found.")
+		return
+	}
+	writeJSON(w, http.StatusOK, storedSubmissions(entries))
+}
+
+func (s *Server) handleTally(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", "GET")
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed.")
+		return
+	}
+	name := r.URL.Query().Get("policy")
+	if name == "" {
+		name = tally.FirstWins.String()
+	}
+	policy, ok := tally.PolicyNames[name]
+	if !ok {
+		writeError(w, http.StatusBadRequest, ring.ErrorMessages[ring.UnknownPolicy])
+		return
+	}
+	entries, err := s.registry.Entries()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	ballots := make([]tally.Ballot, len(entries))
+	for i, entry := range entries {
+		ballots[i] = tally.Ballot{ID: strconv.Itoa(i), Signature: entry.Signature, Message: entry.Message}
+	}
+	status, result := tally.Count(s.foldedPublicKeys, s.caseIdentifier, ballots, policy)
+	if status != ring.Success {
+		writeError(w, http.StatusInternalServerError, ring.ErrorMessages[status])
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
@@ -0,0 +1,211 @@
+package client
+
+import (
+	"bytes"
+	"encoding/asn1"
+	"encoding/hex"
+	"strconv"
+
+	"github.com/zbohm/lirisi/ring"
+)
+
+// AttachedSignature holds ring signature together with the signed content, case identifier and digest of the ring.
+// Content holds the signed message, it may be empty. If DigestOnly is set, only ContentDigest is attached,
+// which is the digest of the message by the hash function of the ring. The signature is made over the AttachedContent, which binds the content type
+// and the mode of the attachment to the message or its digest.
+//
+//	AttachedSignature DEFINITIONS ::= BEGIN
+//	    Name           ::= OCTET STRING,
+//	    Version        ::= INTEGER,
+//	    ContentType    ::= UTF8String,
+//	    DigestOnly     ::= BOOLEAN,
+//	    Content        ::= [0] EXPLICIT OCTET STRING OPTIONAL,
+//	    ContentDigest  ::= [1] EXPLICIT OCTET STRING OPTIONAL,
+//	    CaseIdentifier ::= OCTET STRING,
+//	    RingDigest     ::= OCTET STRING,
+//	    Signature      ::= Signature
+//	END
+type AttachedSignature struct {
+	Name           string
+	Version        int
+	ContentType    string `asn1:"utf8"`
+	DigestOnly     bool
+	Content        []byte `asn1:"optional,explicit,tag:0"`
+	ContentDigest  []byte `asn1:"optional,omitempty,explicit,tag:1"`
+	CaseIdentifier []byte
+	RingDigest     []byte
+	Signature      ring.Signature
+}
+
+// DefaultContentType is the content type of the attached message if none is given.
+const DefaultContentType = "application/octet-stream"
+
+const attachedSignatureType = "RING SIGNATURE ATTACHED"
+
+// AttachedContent is signed in the attached signature. DigestOnly tells whether Content is the message
+// or its digest.
+//
+//	AttachedContent DEFINITIONS ::= BEGIN
+//	    ContentType ::= UTF8String,
+//	    DigestOnly  ::= BOOLEAN,
+//	    Content     ::= OCTET STRING
+//	END
+type AttachedContent struct {
+	ContentType string `asn1:"utf8"`
+	DigestOnly  bool
+	Content     []byte
+}
+
+// contentDigest returns the digest of the message by the hash function of the ring.
+func contentDigest(foldedPublicKeys, message []byte) (int, []byte) {
+	status, _, foldedKeys := UnfoldPublicKeysContent(foldedPublicKeys)
+	if status != ring.Success {
+		return status, nil
+	}
+	hashFnc, ok := ring.GetHasher(foldedKeys.HasherOID)
+	if !ok {
+		return ring.UnexpectedHashType, nil
+	}
+	fc := ring.FactoryContext{Hasher: hashFnc}
+	return ring.Success, fc.MakeDigest(message)
+}
+
+// encodeAttachedContent encodes the content to be signed into DER.
+func encodeAttachedContent(contentType string, digestOnly bool, content []byte) (int, []byte) {
+	if contentType == "" {
+		contentType = DefaultContentType
+	}
+	encoded, err := asn1.Marshal(AttachedContent{ContentType: contentType, DigestOnly: digestOnly, Content: content})
+	if err != nil {
+		return ring.Asn1MarshalFailed, nil
+	}
+	return ring.Success, encoded
+}
+
+// SignedContent returns content to be signed for the attached signature. It is AttachedContent in DER
+// with the message, or with its digest by the hash function of the ring if only the digest is attached.
+func SignedContent(foldedPublicKeys, message []byte, contentType string, digestOnly bool) (int, []byte) {
+	if digestOnly {
+		var status int
+		status, message = contentDigest(foldedPublicKeys, message)
+		if status != ring.Success {
+			return status, nil
+		}
+	}
+	return encodeAttachedContent(contentType, digestOnly, message)
+}
+
+// AttachSignature puts the signature made over SignedContent of the message into the attached signature
+// and encode it into DER or PEM.
+func AttachSignature(foldedPublicKeys, signature, message, caseIdentifier []byte, contentType string, digestOnly bool, outFormat string) (int, []byte) {
+	status, sign := ParseSignature(signature)
+	if status != ring.Success {
+		return status, nil
+	}
+	status, _, foldedKeys := UnfoldPublicKeysContent(foldedPublicKeys)
+	if status != ring.Success {
+		return status, nil
+	}
+	if contentType == "" {
+		contentType = DefaultContentType
+	}
+	attached := AttachedSignature{
+		Name:           ring.Origin + " Attached signature",
+		Version:        ring.SignatureVersion,
+		ContentType:    contentType,
+		DigestOnly:     digestOnly,
+		CaseIdentifier: caseIdentifier,
+		RingDigest:     foldedKeys.Digest,
+		Signature:      sign,
+	}
+	if digestOnly {
+		status, attached.ContentDigest = contentDigest(foldedPublicKeys, message)
+		if status != ring.Success {
+			return status, nil
+		}
+	} else {
+		// Empty message is encoded too, only nil content is left out.
+		attached.Content = append([]byte{}, message...)
+	}
+	if outFormat == "PEM" {
+		status, content := EncodeValue(attachedSignatureType, attached, "DER")
+		if status != ring.Success {
+			return status, content
+		}
+		headers := map[string]string{
+			"Origin":       ring.Origin,
+			"ContentType":  contentType,
+			"NumberOfKeys": strconv.Itoa(len(sign.Signatures)),
+			"KeyImage":     formatKeyImage(sign.KeyImage),
+			"RingDigest":   FormatDigest(hex.EncodeToString(foldedKeys.Digest)),
+		}
+		return encodePEMBlock(attachedSignatureType, headers, content)
+	}
+	return EncodeValue(attachedSignatureType, attached, "DER")
+}
+
+// CreateAttachedSignature creates signature of the message and encode it with the message or its digest,
+// the case identifier and the ring digest into DER or PEM.
+func CreateAttachedSignature(foldedPublicKeys, privateKeyContent, message, caseIdentifier []byte, contentType string, digestOnly bool, outFormat string) (int, []byte) {
+	status, content := SignedContent(foldedPublicKeys, message, contentType, digestOnly)
+	if status != ring.Success {
+		return status, nil
+	}
+	status, signature := CreateSignature(foldedPublicKeys, privateKeyContent, content, caseIdentifier, "DER")
+	if status != ring.Success {
+		return status, nil
+	}
+	return AttachSignature(foldedPublicKeys, signature, message, caseIdentifier, contentType, digestOnly, outFormat)
+}
+
+// ParseAttachedSignature parses attached signature in format PEM or DER.
+func ParseAttachedSignature(content []byte) (int, AttachedSignature) {
+	attached := AttachedSignature{}
+	return DecodeValue(attachedSignatureType, content, &attached), attached
+}
+
+// VerifyAttachedSignature verifies attached signature against the ring. Only the container and the ring are needed.
+// The attached signature is returned to give the content, its type and the case identifier to the caller.
+func VerifyAttachedSignature(foldedPublicKeys, content []byte) (int, AttachedSignature) {
+	status, attached := ParseAttachedSignature(content)
+	if status != ring.Success {
+		return status, attached
+	}
+	status, publicKeys, foldedKeys := UnfoldPublicKeysContent(foldedPublicKeys)
+	if status != ring.Success {
+		return status, attached
+	}
+	if !bytes.Equal(attached.RingDigest, foldedKeys.Digest) {
+		return ring.RingMismatch, attached
+	}
+	message := attached.Content
+	if attached.DigestOnly {
+		message = attached.ContentDigest
+	}
+	if message == nil {
+		return ring.MissingAttachedContent, attached
+	}
+	status, signed := encodeAttachedContent(attached.ContentType, attached.DigestOnly, message)
+	if status != ring.Success {
+		return status, attached
+	}
+	return verifyUnfolded(&attached.Signature, publicKeys, foldedKeys, signed, attached.CaseIdentifier), attached
+}
+
+// MatchAttachedContent checks that the message is the attached content or that it has the attached digest.
+func MatchAttachedContent(foldedPublicKeys []byte, attached AttachedSignature, message []byte) int {
+	if !attached.DigestOnly {
+		if !bytes.Equal(attached.Content, message) {
+			return ring.ContentMismatch
+		}
+		return ring.Success
+	}
+	status, digest := contentDigest(foldedPublicKeys, message)
+	if status != ring.Success {
+		return status
+	}
+	if !bytes.Equal(attached.ContentDigest, digest) {
+		return ring.ContentMismatch
+	}
+	return ring.Success
+}
@@ -0,0 +1,118 @@
+package client_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/zbohm/lirisi/client"
+	"github.com/zbohm/lirisi/internal/testring"
+	"github.com/zbohm/lirisi/ring"
+)
+
+func attach(t *testing.T, folded, privateKey []byte, message string, digestOnly bool, format string) []byte {
+	status, content := client.CreateAttachedSignature(folded, privateKey, []byte(message), caseIdentifier, "text/plain", digestOnly, format)
+	if status != ring.Success {
+		t.Fatal(ring.ErrorMessages[status])
+	}
+	return content
+}
+
+// reattach encodes the attached signature changed by the function.
+func reattach(t *testing.T, content []byte, change func(*client.AttachedSignature)) []byte {
+	status, attached := client.ParseAttachedSignature(content)
+	if status != ring.Success {
+		t.Fatal(ring.ErrorMessages[status])
+	}
+	change(&attached)
+	status, content = client.EncodeValue("RING SIGNATURE ATTACHED", attached, "DER")
+	if status != ring.Success {
+		t.Fatal(ring.ErrorMessages[status])
+	}
+	return content
+}
+
+func TestAttachedSignature(t *testing.T) {
+	privateKeys, folded := testring.Create(t, 3)
+	for _, format := range []string{"DER", "PEM"} {
+		for _, digestOnly := range []bool{false, true} {
+			content := attach(t, folded, privateKeys[1], "Yes", digestOnly, format)
+			status, attached := client.VerifyAttachedSignature(folded, content)
+			if status != ring.Success {
+				t.Fatalf("%s digest only %v: %s", format, digestOnly, ring.ErrorMessages[status])
+			}
+			if attached.ContentType != "text/plain" || !bytes.Equal(attached.CaseIdentifier, caseIdentifier) {
+				t.Errorf("Unexpected attached signature %+v.", attached)
+			}
+			if digestOnly != (attached.Content == nil) || digestOnly == (attached.ContentDigest == nil) {
+				t.Errorf("Unexpected content %q and digest %x.", attached.Content, attached.ContentDigest)
+			}
+			if status := client.MatchAttachedContent(folded, attached, []byte("Yes")); status != ring.Success {
+				t.Errorf("Message does not match: %s", ring.ErrorMessages[status])
+			}
+			if status := client.MatchAttachedContent(folded, attached, []byte("No")); status != ring.ContentMismatch {
+				t.Errorf("Another message matched with status %d.", status)
+			}
+		}
+	}
+}
+
+func TestAttachedSignatureEmptyMessage(t *testing.T) {
+	privateKeys, folded := testring.Create(t, 2)
+	for _, digestOnly := range []bool{false, true} {
+		content := attach(t, folded, privateKeys[0], "", digestOnly, "PEM")
+		status, attached := client.VerifyAttachedSignature(folded, content)
+		if status != ring.Success {
+			t.Fatalf("Digest only %v: %s", digestOnly, ring.ErrorMessages[status])
+		}
+		if attached.DigestOnly != digestOnly || (!digestOnly && len(attached.Content) != 0) {
+			t.Errorf("Unexpected attached signature %+v.", attached)
+		}
+		if status := client.MatchAttachedContent(folded, attached, []byte{}); status != ring.Success {
+			t.Errorf("Empty message does not match: %s", ring.ErrorMessages[status])
+		}
+	}
+}
+
+func TestAttachedSignatureBinding(t *testing.T) {
+	privateKeys, folded := testring.Create(t, 2)
+	content := attach(t, folded, privateKeys[0], "Yes", false, "DER")
+	digested := attach(t, folded, privateKeys[0], "Yes", true, "DER")
+
+	retyped := reattach(t, content, func(attached *client.AttachedSignature) { attached.ContentType = "text/html" })
+	if status, _ := client.VerifyAttachedSignature(folded, retyped); status == ring.Success {
+		t.Errorf("Signature with changed content type verified.")
+	}
+	// The digest cannot be passed off as the message.
+	switched := reattach(t, digested, func(attached *client.AttachedSignature) {
+		attached.DigestOnly, attached.Content, attached.ContentDigest = false, attached.ContentDigest, nil
+	})
+	if status, _ := client.VerifyAttachedSignature(folded, switched); status == ring.Success {
+		t.Errorf("Signature with the digest as the message verified.")
+	}
+	// The plain signature of the message is not the attached signature.
+	status, signature := client.CreateSignature(folded, privateKeys[0], []byte("Yes"), caseIdentifier, "DER")
+	if status != ring.Success {
+		t.Fatal(ring.ErrorMessages[status])
+	}
+	status, attachedPlain := client.AttachSignature(folded, signature, []byte("Yes"), caseIdentifier, "text/plain", false, "DER")
+	if status != ring.Success {
+		t.Fatal(ring.ErrorMessages[status])
+	}
+	if status, _ := client.VerifyAttachedSignature(folded, attachedPlain); status == ring.Success {
+		t.Errorf("Signature of the bare message verified as attached.")
+	}
+}
+
+func TestAttachedSignatureRejected(t *testing.T) {
+	privateKeys, folded := testring.Create(t, 2)
+	_, otherFolded := testring.Create(t, 2)
+	content := attach(t, folded, privateKeys[0], "Yes", false, "PEM")
+
+	if status, _ := client.VerifyAttachedSignature(otherFolded, content); status != ring.RingMismatch {
+		t.Errorf("Signature verified against another ring with status %d.", status)
+	}
+	empty := reattach(t, content, func(attached *client.AttachedSignature) { attached.Content = nil })
+	if status, _ := client.VerifyAttachedSignature(folded, empty); status != ring.MissingAttachedContent {
+		t.Errorf("Signature without content verified with status %d.", status)
+	}
+}
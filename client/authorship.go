@@ -0,0 +1,73 @@
+package client
+
+import (
+	"encoding/asn1"
+	"encoding/hex"
+
+	"github.com/zbohm/lirisi/ring"
+)
+
+// CreateAuthorshipProof creates proof that the private key made the signature and encode it into DER or PEM.
+func CreateAuthorshipProof(foldedPublicKeys, privateKeyContent, signature, caseIdentifier, challenge []byte, outFormat string) (int, []byte) {
+
+	content := []byte{}
+
+	status, sign := ParseSignature(signature)
+	if status != ring.Success {
+		return status, content
+	}
+	status, publicKeys, _ := UnfoldPublicKeysContent(foldedPublicKeys)
+	if status != ring.Success {
+		return status, content
+	}
+	status, privateKey := ParsePrivateKey(privateKeyContent)
+	if status != ring.Success {
+		return status, content
+	}
+	status, proof := ring.ProveAuthorship(privateKey, &sign, publicKeys, caseIdentifier, challenge)
+	if status != ring.Success {
+		return status, content
+	}
+	content, err := asn1.Marshal(*proof)
+	if err != nil {
+		return ring.Asn1MarshalFailed, content
+	}
+	if outFormat == "PEM" {
+		return encodePEMBlock("RING AUTHORSHIP PROOF", map[string]string{
+			"Origin":    ring.Origin,
+			"PublicKey": FormatDigest(hex.EncodeToString(proof.PublicKey.Bytes())),
+		}, content)
+	}
+	return ring.Success, content
+}
+
+// ParseAuthorshipProof parses authorship proof in format PEM or DER.
+func ParseAuthorshipProof(content []byte) (int, ring.AuthorshipProof) {
+	proof := ring.AuthorshipProof{}
+	status, content := decodePEMBlock(content, "RING AUTHORSHIP PROOF")
+	if status != ring.Success {
+		return status, proof
+	}
+	return unmarshalDER(content, &proof), proof
+}
+
+// VerifyAuthorshipProof verifies the signature of the message and proof that the owner of the public key
+// in the proof made it.
+func VerifyAuthorshipProof(foldedPublicKeys, signature, message, proof, caseIdentifier, challenge []byte) int {
+	status, sign := ParseSignature(signature)
+	if status != ring.Success {
+		return status
+	}
+	status, authorshipProof := ParseAuthorshipProof(proof)
+	if status != ring.Success {
+		return status
+	}
+	status, publicKeys, foldedKeys := UnfoldPublicKeysContent(foldedPublicKeys)
+	if status != ring.Success {
+		return status
+	}
+	if status := checkSources(&sign, foldedKeys); status != ring.Success {
+		return status
+	}
+	return ring.VerifyAuthorship(&authorshipProof, &sign, publicKeys, message, caseIdentifier, challenge)
+}
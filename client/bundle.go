@@ -0,0 +1,152 @@
+package client
+
+import (
+	"bytes"
+	"encoding/asn1"
+	"encoding/hex"
+	"strconv"
+
+	"github.com/zbohm/lirisi/ring"
+)
+
+// BundleManifest holds the pieces needed to verify the signature.
+type BundleManifest struct {
+	FoldedPublicKeys ring.FoldedPublicKeys
+	Message          []byte
+	CaseIdentifier   []byte
+	Signature        ring.Signature
+}
+
+// Bundle holds folded public keys, message, case identifier and signature in one structure.
+// ManifestDigest is the digest of DER of the manifest by the hash function of the ring.
+//
+//	Bundle DEFINITIONS ::= BEGIN
+//	    Name             ::= OCTET STRING,
+//	    Version          ::= INTEGER,
+//	    FoldedPublicKeys ::= FoldedPublicKeys,
+//	    Message          ::= OCTET STRING,
+//	    CaseIdentifier   ::= OCTET STRING,
+//	    Signature        ::= Signature,
+//	    ManifestDigest   ::= OCTET STRING
+//	END
+type Bundle struct {
+	Name             string
+	Version          int
+	FoldedPublicKeys ring.FoldedPublicKeys
+	Message          []byte
+	CaseIdentifier   []byte
+	Signature        ring.Signature
+	ManifestDigest   []byte
+}
+
+const bundleType = "RING SIGNATURE BUNDLE"
+
+// Manifest returns the pieces of the bundle covered by the manifest digest.
+func (b *Bundle) Manifest() BundleManifest {
+	return BundleManifest{
+		FoldedPublicKeys: b.FoldedPublicKeys,
+		Message:          b.Message,
+		CaseIdentifier:   b.CaseIdentifier,
+		Signature:        b.Signature,
+	}
+}
+
+// manifestDigest returns digest of the manifest by the hash function of the ring.
+func manifestDigest(manifest BundleManifest) (int, []byte) {
+	hashFnc, ok := ring.GetHasher(manifest.FoldedPublicKeys.HasherOID)
+	if !ok {
+		return ring.OIDHasherNotFound, nil
+	}
+	content, err := asn1.Marshal(manifest)
+	if err != nil {
+		return ring.Asn1MarshalFailed, nil
+	}
+	fc := ring.FactoryContext{Hasher: hashFnc}
+	return ring.Success, fc.MakeDigest(content)
+}
+
+// CreateBundle verifies the signature and packs it with folded public keys, message and case identifier
+// into the bundle encoded into DER or PEM. Pieces that do not belong together are refused.
+func CreateBundle(foldedPublicKeys, signature, message, caseIdentifier []byte, outFormat string) (int, []byte) {
+	if status := VerifySignature(foldedPublicKeys, signature, message, caseIdentifier); status != ring.Success {
+		return status, nil
+	}
+	status, sign := ParseSignature(signature)
+	if status != ring.Success {
+		return status, nil
+	}
+	status, publicKeys, foldedKeys := UnfoldPublicKeysContent(foldedPublicKeys)
+	if status != ring.Success {
+		return status, nil
+	}
+	bundle := Bundle{
+		Name:             ring.Origin + " Bundle",
+		Version:          ring.SignatureVersion,
+		FoldedPublicKeys: foldedKeys,
+		Message:          message,
+		CaseIdentifier:   caseIdentifier,
+		Signature:        sign,
+	}
+	status, bundle.ManifestDigest = manifestDigest(bundle.Manifest())
+	if status != ring.Success {
+		return status, nil
+	}
+	content, err := asn1.Marshal(bundle)
+	if err != nil {
+		return ring.Asn1MarshalFailed, nil
+	}
+	if outFormat == "PEM" {
+		hashFnc, _ := ring.GetHasher(foldedKeys.HasherOID)
+		fc := ring.FactoryContext{Hasher: hashFnc}
+		return encodePEMBlock(bundleType, map[string]string{
+			"Origin":         ring.Origin,
+			"NumberOfKeys":   strconv.Itoa(len(publicKeys)),
+			"KeyImage":       formatKeyImage(sign.KeyImage),
+			"RingDigest":     FormatDigest(hex.EncodeToString(fc.PublicKeysDigest(publicKeys))),
+			"ManifestDigest": FormatDigest(hex.EncodeToString(bundle.ManifestDigest)),
+		}, content)
+	}
+	return ring.Success, content
+}
+
+// ParseBundle parses bundle in format PEM or DER.
+func ParseBundle(content []byte) (int, Bundle) {
+	bundle := Bundle{}
+	return DecodeValue(bundleType, content, &bundle), bundle
+}
+
+// VerifyBundle checks the manifest digest and verifies the signature in the bundle.
+// If the ring digest is given, the ring in the bundle must have it. It is the digest output by PublicKeysDigest.
+func VerifyBundle(content, ringDigest []byte) (int, Bundle) {
+	status, bundle := ParseBundle(content)
+	if status != ring.Success {
+		return status, bundle
+	}
+	status, digest := manifestDigest(bundle.Manifest())
+	if status != ring.Success {
+		return status, bundle
+	}
+	if !bytes.Equal(digest, bundle.ManifestDigest) {
+		return ring.ManifestMismatch, bundle
+	}
+	foldedPublicKeys, err := asn1.Marshal(bundle.FoldedPublicKeys)
+	if err != nil {
+		return ring.Asn1MarshalFailed, bundle
+	}
+	if len(ringDigest) > 0 {
+		status, publicKeys, _ := UnfoldPublicKeysContent(foldedPublicKeys)
+		if status != ring.Success {
+			return status, bundle
+		}
+		hashFnc, _ := ring.GetHasher(bundle.FoldedPublicKeys.HasherOID)
+		fc := ring.FactoryContext{Hasher: hashFnc}
+		if !bytes.Equal(ringDigest, fc.PublicKeysDigest(publicKeys)) {
+			return ring.RingMismatch, bundle
+		}
+	}
+	status, signature := EncodeSignarureToDER(&bundle.Signature)
+	if status != ring.Success {
+		return status, bundle
+	}
+	return VerifySignature(foldedPublicKeys, signature, bundle.Message, bundle.CaseIdentifier), bundle
+}
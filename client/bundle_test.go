@@ -0,0 +1,105 @@
+package client_test
+
+import (
+	"bytes"
+	"encoding/asn1"
+	"encoding/hex"
+	"testing"
+
+	"github.com/zbohm/lirisi/client"
+	"github.com/zbohm/lirisi/internal/testring"
+	"github.com/zbohm/lirisi/ring"
+	"golang.org/x/crypto/sha3"
+)
+
+func createBundle(t *testing.T, format string) ([]byte, []byte) {
+	privateKeys, folded := testring.Create(t, 3)
+	status, signature := client.CreateSignature(folded, privateKeys[1], []byte("message"), caseIdentifier, "DER")
+	if status != ring.Success {
+		t.Fatal(ring.ErrorMessages[status])
+	}
+	status, content := client.CreateBundle(folded, signature, []byte("message"), caseIdentifier, format)
+	if status != ring.Success {
+		t.Fatal(ring.ErrorMessages[status])
+	}
+	status, digest := client.PublicKeysDigest(folded, false)
+	if status != ring.Success {
+		t.Fatal(ring.ErrorMessages[status])
+	}
+	ringDigest, err := hex.DecodeString(string(digest))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return content, ringDigest
+}
+
+// rebundle encodes the bundle changed by the function.
+func rebundle(t *testing.T, content []byte, change func(*client.Bundle)) []byte {
+	status, bundle := client.ParseBundle(content)
+	if status != ring.Success {
+		t.Fatal(ring.ErrorMessages[status])
+	}
+	change(&bundle)
+	status, content = client.EncodeValue("RING SIGNATURE BUNDLE", bundle, "DER")
+	if status != ring.Success {
+		t.Fatal(ring.ErrorMessages[status])
+	}
+	return content
+}
+
+func TestBundle(t *testing.T) {
+	for _, format := range []string{"DER", "PEM"} {
+		content, ringDigest := createBundle(t, format)
+		status, bundle := client.VerifyBundle(content, ringDigest)
+		if status != ring.Success {
+			t.Fatalf("%s: %s", format, ring.ErrorMessages[status])
+		}
+		if !bytes.Equal(bundle.Message, []byte("message")) || !bytes.Equal(bundle.CaseIdentifier, caseIdentifier) {
+			t.Errorf("Unexpected bundle %+v.", bundle)
+		}
+		if status, _ := client.VerifyBundle(content, nil); status != ring.Success {
+			t.Errorf("Bundle without the pinned ring is not valid: %s", ring.ErrorMessages[status])
+		}
+	}
+}
+
+func TestBundleRefused(t *testing.T) {
+	privateKeys, folded := testring.Create(t, 2)
+	status, signature := client.CreateSignature(folded, privateKeys[0], []byte("message"), caseIdentifier, "DER")
+	if status != ring.Success {
+		t.Fatal(ring.ErrorMessages[status])
+	}
+	if status, _ := client.CreateBundle(folded, signature, []byte("other"), caseIdentifier, "DER"); status == ring.Success {
+		t.Errorf("Bundle of another message created.")
+	}
+}
+
+func TestBundleTampered(t *testing.T) {
+	content, ringDigest := createBundle(t, "PEM")
+
+	message := rebundle(t, content, func(bundle *client.Bundle) { bundle.Message = []byte("other") })
+	if status, _ := client.VerifyBundle(message, ringDigest); status != ring.ManifestMismatch {
+		t.Errorf("Bundle with tampered message verified with status %d.", status)
+	}
+	// The manifest digest recomputed after the change does not make the signature valid.
+	tampered := rebundle(t, content, func(bundle *client.Bundle) {
+		bundle.CaseIdentifier = []byte("other")
+		manifest, err := asn1.Marshal(bundle.Manifest())
+		if err != nil {
+			t.Fatal(err)
+		}
+		digest := sha3.Sum256(manifest)
+		bundle.ManifestDigest = digest[:]
+	})
+	if status, _ := client.VerifyBundle(tampered, ringDigest); status != ring.IncorrectChecksum {
+		t.Errorf("Bundle with tampered case verified with status %d.", status)
+	}
+}
+
+func TestBundleRingMismatch(t *testing.T) {
+	content, _ := createBundle(t, "DER")
+	_, otherDigest := createBundle(t, "DER")
+	if status, _ := client.VerifyBundle(content, otherDigest); status != ring.RingMismatch {
+		t.Errorf("Bundle verified against another ring digest with status %d.", status)
+	}
+}
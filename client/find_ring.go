@@ -0,0 +1,48 @@
+package client
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/zbohm/lirisi/ring"
+)
+
+// FindRing returns filename and content of the folded public keys in the folder the signature was made for.
+// Files that are not folded public keys are skipped. The signature must carry the digest of its ring.
+func FindRing(signature []byte, folder string) (int, string, []byte) {
+	status, sign := ParseSignature(signature)
+	if status != ring.Success {
+		return status, "", nil
+	}
+	if len(sign.RingDigest) == 0 {
+		return ring.MissingRingDigest, "", nil
+	}
+	hashFnc, ok := ring.GetHasher(sign.HasherOID)
+	if !ok {
+		return ring.OIDHasherNotFound, "", nil
+	}
+	fc := ring.FactoryContext{Hasher: hashFnc}
+	files, err := ioutil.ReadDir(folder)
+	if err != nil {
+		return ring.ReadingFolderFailed, "", nil
+	}
+	for _, file := range files {
+		if file.IsDir() {
+			continue
+		}
+		path := filepath.Join(folder, file.Name())
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			return ring.ReadingFolderFailed, "", nil
+		}
+		status, publicKeys, _ := UnfoldPublicKeysContent(content)
+		if status != ring.Success {
+			continue
+		}
+		if bytes.Equal(sign.RingDigest, fc.PublicKeysDigest(publicKeys)) {
+			return ring.Success, path, content
+		}
+	}
+	return ring.RingMismatch, "", nil
+}
@@ -0,0 +1,73 @@
+package client_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/zbohm/lirisi/client"
+	"github.com/zbohm/lirisi/internal/testring"
+	"github.com/zbohm/lirisi/ring"
+)
+
+func TestFindRing(t *testing.T) {
+	_, other := testring.Create(t, 2)
+	privateKeys, folded := testring.Create(t, 3)
+	folder, err := ioutil.TempDir("", "lirisi-rings")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(folder)
+	for name, content := range map[string][]byte{
+		"a-other.pem": other,
+		"b-notes.txt": []byte("Not a ring."),
+		"c-ring.pem":  folded,
+	} {
+		if err := ioutil.WriteFile(filepath.Join(folder, name), content, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(folder, "d-folder"), 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	status, signature := client.CreateSignature(folded, privateKeys[2], []byte("message"), caseIdentifier, "PEM")
+	if status != ring.Success {
+		t.Fatal(ring.ErrorMessages[status])
+	}
+	status, path, _ := client.FindRing(signature, folder)
+	if status != ring.Success {
+		t.Fatal(ring.ErrorMessages[status])
+	}
+	if path != filepath.Join(folder, "c-ring.pem") {
+		t.Errorf("Unexpected ring %s.", path)
+	}
+
+	if status, _, _ := client.FindRing(signature, filepath.Join(folder, "missing")); status != ring.ReadingFolderFailed {
+		t.Errorf("Missing folder read with status %d.", status)
+	}
+	if err := os.Remove(filepath.Join(folder, "c-ring.pem")); err != nil {
+		t.Fatal(err)
+	}
+	if status, _, _ := client.FindRing(signature, folder); status != ring.RingMismatch {
+		t.Errorf("Ring found in the folder without it with status %d.", status)
+	}
+}
+
+func TestFindRingMissingDigest(t *testing.T) {
+	privateKeys, folded := testring.Create(t, 2)
+	status, signature := client.CreateSignature(folded, privateKeys[0], []byte("message"), caseIdentifier, "DER")
+	if status != ring.Success {
+		t.Fatal(ring.ErrorMessages[status])
+	}
+	status, sign := client.ParseSignature(signature)
+	if status != ring.Success {
+		t.Fatal(ring.ErrorMessages[status])
+	}
+	sign.RingDigest = nil
+	_, stripped := client.EncodeSignarureToDER(&sign)
+	if status, _, _ := client.FindRing(stripped, os.TempDir()); status != ring.MissingRingDigest {
+		t.Errorf("Signature without the ring digest searched with status %d.", status)
+	}
+}
@@ -0,0 +1,140 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+
+	"github.com/zbohm/lirisi/ring"
+)
+
+// SigningRecord holds one signature made by the signing client. Values are hex encoded.
+type SigningRecord struct {
+	RingDigest    string `json:"ring_digest"`
+	Case          string `json:"case"`
+	KeyImage      string `json:"key_image"`
+	MessageDigest string `json:"message_digest"`
+}
+
+// LoadSigningState reads records of the local signing state. A missing file is an empty state.
+func LoadSigningState(statePath string) (int, []SigningRecord) {
+	records := []SigningRecord{}
+	handle, err := os.Open(statePath)
+	if os.IsNotExist(err) {
+		return ring.Success, records
+	}
+	if err != nil {
+		return ring.SigningStateFailure, records
+	}
+	defer handle.Close()
+
+	scanner := bufio.NewScanner(handle)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		record := SigningRecord{}
+		if err := json.Unmarshal(line, &record); err != nil {
+			return ring.SigningStateFailure, records
+		}
+		records = append(records, record)
+	}
+	if err := scanner.Err(); err != nil {
+		return ring.SigningStateFailure, records
+	}
+	return ring.Success, records
+}
+
+// appendSigningRecord appends the record at the end of the local signing state.
+func appendSigningRecord(statePath string, record SigningRecord) int {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return ring.SigningStateFailure
+	}
+	handle, err := os.OpenFile(statePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return ring.SigningStateFailure
+	}
+	if _, err := handle.Write(append(line, Enter...)); err != nil {
+		handle.Close()
+		return ring.SigningStateFailure
+	}
+	if err := handle.Close(); err != nil {
+		return ring.SigningStateFailure
+	}
+	return ring.Success
+}
+
+// FindSigningRecord returns the record made for the same ring, case and key image, if any.
+func FindSigningRecord(records []SigningRecord, record SigningRecord) (SigningRecord, bool) {
+	for _, item := range records {
+		if item.RingDigest == record.RingDigest && item.Case == record.Case && item.KeyImage == record.KeyImage {
+			return item, true
+		}
+	}
+	return SigningRecord{}, false
+}
+
+// NewSigningRecord creates record of the signature the private key will make.
+func NewSigningRecord(foldedPublicKeys, privateKeyContent, message, caseIdentifier []byte) (int, SigningRecord) {
+	record := SigningRecord{}
+	status, keyImage, _ := KeyImageFor(foldedPublicKeys, privateKeyContent, caseIdentifier)
+	if status != ring.Success {
+		return status, record
+	}
+	status, _, foldedKeys := UnfoldPublicKeysContent(foldedPublicKeys)
+	if status != ring.Success {
+		return status, record
+	}
+	hashFnc, ok := ring.GetHasher(foldedKeys.HasherOID)
+	if !ok {
+		return ring.UnexpectedHashType, record
+	}
+	fc := ring.FactoryContext{Hasher: hashFnc}
+	record.RingDigest = hex.EncodeToString(foldedKeys.Digest)
+	record.Case = hex.EncodeToString(caseIdentifier)
+	record.KeyImage = hex.EncodeToString(keyImage.Bytes())
+	record.MessageDigest = hex.EncodeToString(fc.MakeDigest(message))
+	return ring.Success, record
+}
+
+// CreateGuardedSignature creates signature like CreateSignatureWithAttributes and records it into the local signing state.
+// It refuses to sign again for the same ring and case, unless override is set. The status AlreadySignedMessageInCase
+// tells that the same message was signed before, so the new signature would be only its duplicate.
+// AlreadySignedInCase tells that another message was signed and the signatures would conflict.
+// The state is read and appended without a lock. It guards one signer at a time, concurrent signers
+// sharing the state file may both sign in the same ring and case.
+func CreateGuardedSignature(
+	foldedPublicKeys, privateKeyContent, message, caseIdentifier []byte,
+	attributes map[string]string,
+	outFormat, statePath string,
+	override bool,
+) (int, []byte) {
+	content := []byte{}
+
+	status, record := NewSigningRecord(foldedPublicKeys, privateKeyContent, message, caseIdentifier)
+	if status != ring.Success {
+		return status, content
+	}
+	status, records := LoadSigningState(statePath)
+	if status != ring.Success {
+		return status, content
+	}
+	if previous, found := FindSigningRecord(records, record); found && !override {
+		if previous.MessageDigest == record.MessageDigest {
+			return ring.AlreadySignedMessageInCase, content
+		}
+		return ring.AlreadySignedInCase, content
+	}
+	status, content = CreateSignatureWithAttributes(foldedPublicKeys, privateKeyContent, message, caseIdentifier, attributes, outFormat)
+	if status != ring.Success {
+		return status, content
+	}
+	if status := appendSigningRecord(statePath, record); status != ring.Success {
+		return status, []byte{}
+	}
+	return ring.Success, content
+}
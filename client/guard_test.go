@@ -0,0 +1,105 @@
+package client_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/zbohm/lirisi/client"
+	"github.com/zbohm/lirisi/internal/testring"
+	"github.com/zbohm/lirisi/ring"
+)
+
+func stateFile(t *testing.T) (string, func()) {
+	folder, err := ioutil.TempDir("", "lirisi-state")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return filepath.Join(folder, "state"), func() { os.RemoveAll(folder) }
+}
+
+func guardedSign(folded, privateKey []byte, message, caseIdentifier, statePath string, override bool) int {
+	status, _ := client.CreateGuardedSignature(folded, privateKey, []byte(message), []byte(caseIdentifier), nil, "PEM", statePath, override)
+	return status
+}
+
+func TestGuardRefusesSecondSignature(t *testing.T) {
+	privateKeys, folded := testring.Create(t, 3)
+	statePath, cleanup := stateFile(t)
+	defer cleanup()
+
+	if status := guardedSign(folded, privateKeys[0], "yes", "election", statePath, false); status != ring.Success {
+		t.Fatal(ring.ErrorMessages[status])
+	}
+	if status := guardedSign(folded, privateKeys[0], "no", "election", statePath, false); status != ring.AlreadySignedInCase {
+		t.Errorf("Another message signed again with status %d.", status)
+	}
+	if status := guardedSign(folded, privateKeys[0], "yes", "election", statePath, false); status != ring.AlreadySignedMessageInCase {
+		t.Errorf("The same message signed again with status %d.", status)
+	}
+	if status := guardedSign(folded, privateKeys[0], "no", "referendum", statePath, false); status != ring.Success {
+		t.Errorf("Signing in another case failed: %s", ring.ErrorMessages[status])
+	}
+	if status := guardedSign(folded, privateKeys[1], "no", "election", statePath, false); status != ring.Success {
+		t.Errorf("Signing by another member failed: %s", ring.ErrorMessages[status])
+	}
+}
+
+func TestGuardOverride(t *testing.T) {
+	privateKeys, folded := testring.Create(t, 2)
+	statePath, cleanup := stateFile(t)
+	defer cleanup()
+
+	if status := guardedSign(folded, privateKeys[0], "yes", "election", statePath, false); status != ring.Success {
+		t.Fatal(ring.ErrorMessages[status])
+	}
+	if status := guardedSign(folded, privateKeys[0], "no", "election", statePath, true); status != ring.Success {
+		t.Errorf("Override failed: %s", ring.ErrorMessages[status])
+	}
+	status, records := client.LoadSigningState(statePath)
+	if status != ring.Success {
+		t.Fatal(ring.ErrorMessages[status])
+	}
+	if len(records) != 2 {
+		t.Fatalf("Unexpected number of records %d.", len(records))
+	}
+	if records[0].KeyImage != records[1].KeyImage || records[0].MessageDigest == records[1].MessageDigest {
+		t.Errorf("Unexpected records %v.", records)
+	}
+}
+
+func TestGuardMissingState(t *testing.T) {
+	privateKeys, folded := testring.Create(t, 2)
+	statePath, cleanup := stateFile(t)
+	defer cleanup()
+
+	status, records := client.LoadSigningState(statePath)
+	if status != ring.Success || len(records) != 0 {
+		t.Errorf("Missing state loaded with status %d and %d records.", status, len(records))
+	}
+	if status := guardedSign(folded, privateKeys[0], "yes", "election", statePath, false); status != ring.Success {
+		t.Fatal(ring.ErrorMessages[status])
+	}
+	status, records = client.LoadSigningState(statePath)
+	if status != ring.Success || len(records) != 1 {
+		t.Errorf("Created state loaded with status %d and %d records.", status, len(records))
+	}
+}
+
+func TestGuardCorruptState(t *testing.T) {
+	privateKeys, folded := testring.Create(t, 2)
+	statePath, cleanup := stateFile(t)
+	defer cleanup()
+
+	if err := ioutil.WriteFile(statePath, []byte("{\"ring_digest\": \n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if status, _ := client.LoadSigningState(statePath); status != ring.SigningStateFailure {
+		t.Errorf("Corrupt state loaded with status %d.", status)
+	}
+	status, signature := client.CreateGuardedSignature(folded, privateKeys[0], []byte("yes"), []byte("election"), nil, "PEM", statePath, false)
+	if status != ring.SigningStateFailure || len(signature) != 0 {
+		t.Errorf("Signed with corrupt state with status %d.", status)
+	}
+}
@@ -14,12 +14,38 @@ func SignatureKeyImage(body []byte, separator bool) (int, []byte) {
 	if status != ring.Success {
 		return status, []byte(ring.ErrorMessages[status])
 	}
-	digest := sign.KeyImage.Bytes()
-	content := hex.EncodeToString(digest)
+	return ring.Success, FormatKeyImage(sign.KeyImage, separator)
+}
+
+// FormatKeyImage outputs key image in hex, optionally with separating colons.
+func FormatKeyImage(keyImage ring.PointData, separator bool) []byte {
+	content := hex.EncodeToString(keyImage.Bytes())
 	if separator {
 		content = FormatDigest(content)
 	}
-	return ring.Success, []byte(content)
+	return []byte(content)
+}
+
+// KeyImageFor returns key image the private key will make for the folded public keys and case identifier,
+// and position of the private key among public keys (counted from zero).
+func KeyImageFor(foldedPublicKeys, privateKey, caseIdentifier []byte) (int, ring.PointData, int) {
+	status, publicKeys, foldedKeys := UnfoldPublicKeysContent(foldedPublicKeys)
+	if status != ring.Success {
+		return status, ring.PointData{}, -1
+	}
+	curveType, ok := ring.GetCurve(foldedKeys.CurveOID)
+	if !ok {
+		return ring.UnexpectedCurveType, ring.PointData{}, -1
+	}
+	hashFnc, ok := ring.GetHasher(foldedKeys.HasherOID)
+	if !ok {
+		return ring.UnexpectedHashType, ring.PointData{}, -1
+	}
+	status, key := ParsePrivateKey(privateKey)
+	if status != ring.Success {
+		return status, ring.PointData{}, -1
+	}
+	return ring.KeyImage(curveType, hashFnc, key, publicKeys, caseIdentifier)
 }
 
 // formatKeyImage into more human readable form
@@ -0,0 +1,89 @@
+package client
+
+import (
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/zbohm/lirisi/ring"
+)
+
+// MessageExtension is the extension of the message file stored next to the signature with the same basename.
+const MessageExtension = ".msg"
+
+// SignatureFile holds signature loaded from the folder with the message it signs.
+type SignatureFile struct {
+	Name      string
+	Signature []byte
+	Message   []byte
+}
+
+// LoadSignatureFolder reads signatures from the folder in the order of file names.
+// The message of the signature "ballot.pem" is read from the file "ballot.msg", if it exists.
+func LoadSignatureFolder(folder string) []SignatureFile {
+	var signatures []SignatureFile
+
+	files, err := ioutil.ReadDir(folder)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, file := range files {
+		name := file.Name()
+		if file.IsDir() || filepath.Ext(name) == MessageExtension {
+			continue
+		}
+		content, err := ioutil.ReadFile(filepath.Join(folder, name))
+		if err != nil {
+			log.Fatal(err)
+		}
+		messageName := filepath.Join(folder, strings.TrimSuffix(name, filepath.Ext(name))+MessageExtension)
+		message, err := ioutil.ReadFile(messageName)
+		if err != nil && !os.IsNotExist(err) {
+			log.Fatal(err)
+		}
+		signatures = append(signatures, SignatureFile{Name: name, Signature: content, Message: message})
+	}
+	return signatures
+}
+
+// LinkCluster holds files of signatures with the same key image, e.g. made by the same member of the ring.
+type LinkCluster struct {
+	KeyImage string   `json:"key_image"`
+	Files    []string `json:"files"`
+	Messages []string `json:"messages"`
+}
+
+// LinkSignatures groups signatures by key image. Only clusters of two and more signatures are returned,
+// in the order of their first file. Names of files that are not signatures are returned separately.
+func LinkSignatures(signatures []SignatureFile, separator bool) ([]LinkCluster, []string) {
+	clusters := []LinkCluster{}
+	invalid := []string{}
+	positions := map[string]int{}
+
+	for _, item := range signatures {
+		status, sign := ParseSignature(item.Signature)
+		if status != ring.Success {
+			invalid = append(invalid, item.Name)
+			continue
+		}
+		keyImage := string(FormatKeyImage(sign.KeyImage, separator))
+		position, ok := positions[keyImage]
+		if !ok {
+			position = len(clusters)
+			positions[keyImage] = position
+			clusters = append(clusters, LinkCluster{KeyImage: keyImage, Files: []string{}, Messages: []string{}})
+		}
+		clusters[position].Files = append(clusters[position].Files, item.Name)
+		clusters[position].Messages = append(clusters[position].Messages, string(item.Message))
+	}
+
+	linked := []LinkCluster{}
+	for _, cluster := range clusters {
+		if len(cluster.Files) > 1 {
+			linked = append(linked, cluster)
+		}
+	}
+	return linked, invalid
+}
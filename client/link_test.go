@@ -0,0 +1,59 @@
+package client_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/zbohm/lirisi/client"
+	"github.com/zbohm/lirisi/internal/testring"
+	"github.com/zbohm/lirisi/ring"
+)
+
+func TestLinkSignatures(t *testing.T) {
+	privateKeys, folded := testring.Create(t, 3)
+	folder, err := ioutil.TempDir("", "lirisi-link")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(folder)
+
+	write := func(name string, content []byte) {
+		if err := ioutil.WriteFile(filepath.Join(folder, name), content, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, item := range []struct {
+		name, message string
+		signer        int
+	}{
+		{"a", "yes", 0},
+		{"b", "no", 1},
+		{"c", "no", 0},
+		{"d", "yes", 2},
+	} {
+		status, signature := client.CreateSignature(folded, privateKeys[item.signer], []byte(item.message), caseIdentifier, "PEM")
+		if status != ring.Success {
+			t.Fatal(ring.ErrorMessages[status])
+		}
+		write(item.name+".pem", signature)
+		write(item.name+client.MessageExtension, []byte(item.message))
+	}
+	write("notes.txt", []byte("Not a signature."))
+
+	clusters, invalid := client.LinkSignatures(client.LoadSignatureFolder(folder), false)
+	if len(clusters) != 1 {
+		t.Fatalf("Unexpected clusters %v.", clusters)
+	}
+	if !reflect.DeepEqual(clusters[0].Files, []string{"a.pem", "c.pem"}) {
+		t.Errorf("Unexpected files of the cluster %v.", clusters[0].Files)
+	}
+	if !reflect.DeepEqual(clusters[0].Messages, []string{"yes", "no"}) {
+		t.Errorf("Unexpected messages of the cluster %v.", clusters[0].Messages)
+	}
+	if !reflect.DeepEqual(invalid, []string{"notes.txt"}) {
+		t.Errorf("Unexpected invalid files %v.", invalid)
+	}
+}
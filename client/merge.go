@@ -0,0 +1,98 @@
+package client
+
+import (
+	"bytes"
+	"encoding/hex"
+	"sort"
+
+	"github.com/zbohm/lirisi/ring"
+)
+
+// MergeFoldedPublicKeys folds the union of several folded public keys into one content.
+// All rings must use the same curve and hash function. Duplicate keys are removed
+// and the digests of the merged rings are recorded as the sources of the union.
+func MergeFoldedPublicKeys(foldedPublicKeys [][]byte, format string) (int, []byte) {
+	var content []byte
+
+	status, rings, foldedRings := unfoldRings(foldedPublicKeys)
+	if status != ring.Success {
+		return status, content
+	}
+	hashFnc, ok := ring.GetHasher(foldedRings[0].HasherOID)
+	if !ok {
+		return ring.UnexpectedHashType, content
+	}
+	fc := ring.FactoryContext{Hasher: hashFnc}
+
+	publicKeys := []HashIdentKey{}
+	known := map[string]bool{}
+	sources := [][]byte{}
+	knownSources := map[string]bool{}
+
+	for i, publicKeysOfRing := range rings {
+		source := hex.EncodeToString(foldedRings[i].Digest)
+		if !knownSources[source] {
+			knownSources[source] = true
+			sources = append(sources, foldedRings[i].Digest)
+		}
+		for _, pub := range publicKeysOfRing {
+			digest := hex.EncodeToString(fc.MakeDigest(getXYCoordinates(pub)))
+			if known[digest] {
+				continue
+			}
+			known[digest] = true
+			publicKeys = append(publicKeys, HashIdentKey{pub: IdentKey{digest: digest, key: pub}})
+		}
+	}
+	sort.Slice(sources, func(i, j int) bool { return bytes.Compare(sources[i], sources[j]) < 0 })
+
+	status, publicKeys, keysDigest := sortKeysByHashes(publicKeys, hashFnc)
+	if status != ring.Success {
+		return status, content
+	}
+	status, curve, pointSeq := makeFoldedPublicKeys(publicKeys, foldedRings[0].HasherOID, keysDigest)
+	if status != ring.Success {
+		return status, content
+	}
+	pointSeq.Sources = sources
+	return encodeFoldedPublicKeys(curve, pointSeq, publicKeys, keysDigest, ring.GetHasherName(hashFnc), format)
+}
+
+// MergeFoldedPublicKeysIfNeeded returns the content itself for one ring or the union of several rings.
+func MergeFoldedPublicKeysIfNeeded(foldedPublicKeys [][]byte) (int, []byte) {
+	if len(foldedPublicKeys) == 1 {
+		return ring.Success, foldedPublicKeys[0]
+	}
+	return MergeFoldedPublicKeys(foldedPublicKeys, "DER")
+}
+
+// SignatureSources outputs digests of the rings merged into the ring of the signature. One line per ring.
+func SignatureSources(body []byte, separator bool) (int, []byte) {
+	status, sign := ParseSignature(body)
+	if status != ring.Success {
+		return status, []byte(ring.ErrorMessages[status])
+	}
+	lines := []byte{}
+	for _, source := range sign.Sources {
+		line := hex.EncodeToString(source)
+		if separator {
+			line = FormatDigest(line)
+		}
+		lines = append(lines, line...)
+		lines = append(lines, Enter...)
+	}
+	return ring.Success, lines
+}
+
+// sourcesEqual returns true if both lists of source digests are the same.
+func sourcesEqual(a, b [][]byte) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !bytes.Equal(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
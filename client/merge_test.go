@@ -0,0 +1,146 @@
+package client_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/zbohm/lirisi/client"
+	"github.com/zbohm/lirisi/internal/testring"
+	"github.com/zbohm/lirisi/ring"
+)
+
+var caseIdentifier = []byte("case")
+
+// fold folds public keys of the private keys into the ring.
+func fold(t *testing.T, privateKeys ...[]byte) []byte {
+	publicKeys := make([][]byte, len(privateKeys))
+	for i, privateKey := range privateKeys {
+		var status int
+		status, publicKeys[i] = client.DerivePublicKey(privateKey, "PEM")
+		if status != ring.Success {
+			t.Fatal(ring.ErrorMessages[status])
+		}
+	}
+	status, folded := client.FoldPublicKeys(publicKeys, "sha3-256", "PEM", "hashes")
+	if status != ring.Success {
+		t.Fatal(ring.ErrorMessages[status])
+	}
+	return folded
+}
+
+func merge(t *testing.T, folded ...[]byte) []byte {
+	status, merged := client.MergeFoldedPublicKeys(folded, "PEM")
+	if status != ring.Success {
+		t.Fatal(ring.ErrorMessages[status])
+	}
+	return merged
+}
+
+func unfold(t *testing.T, folded []byte) ring.FoldedPublicKeys {
+	status, _, foldedKeys := client.UnfoldPublicKeysContent(folded)
+	if status != ring.Success {
+		t.Fatal(ring.ErrorMessages[status])
+	}
+	return foldedKeys
+}
+
+func TestMergeRemovesDuplicateKeys(t *testing.T) {
+	privateKeys, _ := testring.Create(t, 4)
+	first := fold(t, privateKeys[0], privateKeys[1], privateKeys[2])
+	second := fold(t, privateKeys[1], privateKeys[2], privateKeys[3])
+
+	merged := unfold(t, merge(t, first, second))
+	if len(merged.Keys) != 4 {
+		t.Errorf("Unexpected number of keys %d.", len(merged.Keys))
+	}
+	if !bytes.Equal(merged.Digest, unfold(t, fold(t, privateKeys...)).Digest) {
+		t.Errorf("Union of the rings differs from the ring of all keys.")
+	}
+}
+
+func TestMergeMismatch(t *testing.T) {
+	_, folded := testring.Create(t, 2)
+	_, otherCurve := testring.CreateWith(t, 2, "secp384r1", "sha3-256")
+	_, otherHash := testring.CreateWith(t, 2, "prime256v1", "sha3-512")
+	if status, _ := client.MergeFoldedPublicKeys([][]byte{folded, otherCurve}, "PEM"); status != ring.UnexpectedCurveType {
+		t.Errorf("Rings on different curves merged with status %d.", status)
+	}
+	if status, _ := client.MergeFoldedPublicKeys([][]byte{folded, otherHash}, "PEM"); status != ring.UnexpectedHashType {
+		t.Errorf("Rings with different hash functions merged with status %d.", status)
+	}
+}
+
+func TestMergeSources(t *testing.T) {
+	privateKeys, _ := testring.Create(t, 3)
+	first := fold(t, privateKeys[0], privateKeys[1])
+	second := fold(t, privateKeys[1], privateKeys[2])
+	merged := merge(t, first, second, first)
+
+	expected := map[string]bool{
+		hex.EncodeToString(unfold(t, first).Digest):  true,
+		hex.EncodeToString(unfold(t, second).Digest): true,
+	}
+	sources := unfold(t, merged).Sources
+	if len(sources) != len(expected) {
+		t.Fatalf("Unexpected number of sources %d.", len(sources))
+	}
+	for _, source := range sources {
+		if !expected[hex.EncodeToString(source)] {
+			t.Errorf("Unexpected source %x.", source)
+		}
+	}
+
+	status, signature := client.CreateSignature(merged, privateKeys[2], []byte("message"), caseIdentifier, "PEM")
+	if status != ring.Success {
+		t.Fatal(ring.ErrorMessages[status])
+	}
+	status, lines := client.SignatureSources(signature, false)
+	if status != ring.Success {
+		t.Fatal(ring.ErrorMessages[status])
+	}
+	if len(bytes.Fields(lines)) != len(expected) {
+		t.Errorf("Unexpected sources of the signature %s.", lines)
+	}
+	for _, line := range bytes.Fields(lines) {
+		if !expected[string(line)] {
+			t.Errorf("Unexpected source of the signature %s.", line)
+		}
+	}
+}
+
+func TestSourceRingsMismatch(t *testing.T) {
+	privateKeys, _ := testring.Create(t, 3)
+	first := fold(t, privateKeys[0], privateKeys[1])
+	second := fold(t, privateKeys[1], privateKeys[2])
+	merged := merge(t, first, second)
+	// The same keys merged from other rings.
+	other := merge(t, first, second, fold(t, privateKeys[0], privateKeys[2]))
+
+	status, signature := client.CreateSignature(merged, privateKeys[0], []byte("message"), caseIdentifier, "DER")
+	if status != ring.Success {
+		t.Fatal(ring.ErrorMessages[status])
+	}
+	if status := client.VerifySignature(merged, signature, []byte("message"), caseIdentifier); status != ring.Success {
+		t.Errorf("Signature is not valid: %s", ring.ErrorMessages[status])
+	}
+	if status := client.VerifySignature(other, signature, []byte("message"), caseIdentifier); status != ring.SourceRingsMismatch {
+		t.Errorf("Signature verified against other sources with status %d.", status)
+	}
+
+	// Sources are covered by the signature.
+	status, sign := client.ParseSignature(signature)
+	if status != ring.Success {
+		t.Fatal(ring.ErrorMessages[status])
+	}
+	sign.Sources = unfold(t, other).Sources
+	_, rewritten := client.EncodeSignarureToDER(&sign)
+	if status := client.VerifySignature(other, rewritten, []byte("message"), caseIdentifier); status != ring.IncorrectChecksum {
+		t.Errorf("Signature with rewritten sources verified with status %d.", status)
+	}
+	sign.Sources = nil
+	_, stripped := client.EncodeSignarureToDER(&sign)
+	if status := client.VerifySignature(merged, stripped, []byte("message"), caseIdentifier); status != ring.IncorrectChecksum {
+		t.Errorf("Signature with stripped sources verified with status %d.", status)
+	}
+}
@@ -0,0 +1,167 @@
+package client
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"encoding/asn1"
+	"encoding/hex"
+	"encoding/pem"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/zbohm/lirisi/ring"
+)
+
+// unfoldRings restores public keys of all rings. All rings must use the same curve and hash function.
+func unfoldRings(foldedPublicKeys [][]byte) (int, [][]*ecdsa.PublicKey, []ring.FoldedPublicKeys) {
+	rings := make([][]*ecdsa.PublicKey, len(foldedPublicKeys))
+	foldedRings := make([]ring.FoldedPublicKeys, len(foldedPublicKeys))
+	if len(foldedPublicKeys) == 0 {
+		return ring.IncorrectNumberOfRings, rings, foldedRings
+	}
+	for i, content := range foldedPublicKeys {
+		status, publicKeys, foldedKeys := UnfoldPublicKeysContent(content)
+		if status != ring.Success {
+			return status, rings, foldedRings
+		}
+		if i > 0 && !foldedKeys.CurveOID.Equal(foldedRings[0].CurveOID) {
+			return ring.UnexpectedCurveType, rings, foldedRings
+		}
+		if i > 0 && !foldedKeys.HasherOID.Equal(foldedRings[0].HasherOID) {
+			return ring.UnexpectedHashType, rings, foldedRings
+		}
+		rings[i] = publicKeys
+		foldedRings[i] = foldedKeys
+	}
+	return ring.Success, rings, foldedRings
+}
+
+// CreateMultiSignature creates Borromean signature over several rings and encode it into DER or PEM.
+// Private key privateKeyContents[i] belongs to the ring foldedPublicKeys[i].
+func CreateMultiSignature(foldedPublicKeys, privateKeyContents [][]byte, message, caseIdentifier []byte, outFormat string) (int, []byte) {
+
+	content := []byte{}
+
+	if len(foldedPublicKeys) != len(privateKeyContents) {
+		return ring.IncorrectNumberOfRings, content
+	}
+	status, rings, foldedRings := unfoldRings(foldedPublicKeys)
+	if status != ring.Success {
+		return status, content
+	}
+	curveType, ok := ring.GetCurve(foldedRings[0].CurveOID)
+	if !ok {
+		return ring.UnexpectedCurveType, content
+	}
+	hashFnc, ok := ring.GetHasher(foldedRings[0].HasherOID)
+	if !ok {
+		return ring.UnexpectedHashType, content
+	}
+	privateKeys := make([]*ecdsa.PrivateKey, len(privateKeyContents))
+	for i, privateKeyContent := range privateKeyContents {
+		status, privateKeys[i] = ParsePrivateKey(privateKeyContent)
+		if status != ring.Success {
+			return status, content
+		}
+	}
+	status, signature := ring.CreateMulti(curveType, hashFnc, privateKeys, rings, message, caseIdentifier)
+	if status != ring.Success {
+		return status, content
+	}
+	if outFormat == "PEM" {
+		return EncodeMultiSignatureToPEM(signature)
+	}
+	return EncodeMultiSignatureToDER(signature)
+}
+
+// EncodeMultiSignatureToDER encodes Borromean signature to DER.
+func EncodeMultiSignatureToDER(signature *ring.MultiSignature) (int, []byte) {
+	content, err := asn1.Marshal(*signature)
+	if err != nil {
+		return ring.Asn1MarshalFailed, content
+	}
+	return ring.Success, content
+}
+
+// EncodeMultiSignatureToPEM encodes Borromean signature to PEM.
+func EncodeMultiSignatureToPEM(signature *ring.MultiSignature) (int, []byte) {
+	status, contentDer := EncodeMultiSignatureToDER(signature)
+	if status != ring.Success {
+		return status, contentDer
+	}
+	curveType, _ := ring.GetCurve(signature.CurveOID)
+	hashFnc, _ := ring.GetHasher(signature.HasherOID)
+
+	numberOfKeys := make([]string, len(signature.Signatures))
+	for i, signatures := range signature.Signatures {
+		numberOfKeys[i] = strconv.Itoa(len(signatures))
+	}
+	block := &pem.Block{
+		Type: "MULTI RING SIGNATURE",
+		Headers: map[string]string{
+			"Origin":        ring.Origin,
+			"CurveName":     ring.GetCurveName(curveType()),
+			"CurveOID":      signature.CurveOID.String(),
+			"HasherOID":     signature.HasherOID.String(),
+			"HasherName":    ring.GetHasherName(hashFnc),
+			"NumberOfRings": strconv.Itoa(len(signature.Signatures)),
+			"NumberOfKeys":  strings.Join(numberOfKeys, ","),
+		},
+		Bytes: contentDer,
+	}
+	var buff bytes.Buffer
+	if err := pem.Encode(&buff, block); err != nil {
+		return ring.EncodePEMFailed, contentDer
+	}
+	return ring.Success, buff.Bytes()
+}
+
+// ParseMultiSignature parses Borromean signature in format PEM or DER.
+func ParseMultiSignature(content []byte) (int, ring.MultiSignature) {
+	sign := ring.MultiSignature{}
+	if matched, _ := regexp.Match(`-+BEGIN MULTI RING SIGNATURE`, content); matched {
+		block, _ := pem.Decode(content)
+		if block == nil {
+			return ring.DecodePEMFailure, sign
+		}
+		content = block.Bytes
+	}
+	rest, err := asn1.Unmarshal(content, &sign)
+	if err != nil {
+		return ring.Asn1UnmarshalFailed, sign
+	}
+	if len(rest) > 0 {
+		return ring.UnexpectedRestOfSignature, sign
+	}
+	return ring.Success, sign
+}
+
+// VerifyMultiSignature verifies Borromean signature. Rings must be in the same order as at signing.
+func VerifyMultiSignature(foldedPublicKeys [][]byte, signature, message, caseIdentifier []byte) int {
+	status, sign := ParseMultiSignature(signature)
+	if status != ring.Success {
+		return status
+	}
+	status, rings, _ := unfoldRings(foldedPublicKeys)
+	if status != ring.Success {
+		return status
+	}
+	return ring.VerifyMulti(&sign, rings, message, caseIdentifier)
+}
+
+// MultiSignatureKeyImages outputs key images of Borromean signature. One line per ring.
+func MultiSignatureKeyImages(body []byte, separator bool) (int, []byte) {
+	status, sign := ParseMultiSignature(body)
+	if status != ring.Success {
+		return status, []byte(ring.ErrorMessages[status])
+	}
+	lines := make([]string, len(sign.KeyImages))
+	for i, keyImage := range sign.KeyImages {
+		lines[i] = hex.EncodeToString(keyImage.Bytes())
+		if separator {
+			lines[i] = FormatDigest(lines[i])
+		}
+	}
+	return ring.Success, []byte(strings.Join(lines, "\n"))
+}
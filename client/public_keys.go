@@ -36,7 +36,6 @@ var Enter = []byte("\n")
 func FoldPublicKeys(pubKeysContent [][]byte, hashName, format, order string) (int, []byte) {
 
 	var content, keysDigest []byte
-	var curve elliptic.Curve
 	var publicKeys []HashIdentKey
 	var status int
 
@@ -58,6 +57,17 @@ func FoldPublicKeys(pubKeysContent [][]byte, hashName, format, order string) (in
 			return status, content
 		}
 	}
+	status, curve, pointSeq := makeFoldedPublicKeys(publicKeys, hasherOID, keysDigest)
+	if status != ring.Success {
+		return status, content
+	}
+	return encodeFoldedPublicKeys(curve, pointSeq, publicKeys, keysDigest, hashName, format)
+}
+
+// makeFoldedPublicKeys creates sequence of public keys points.
+func makeFoldedPublicKeys(publicKeys []HashIdentKey, hasherOID asn1.ObjectIdentifier, keysDigest []byte) (int, elliptic.Curve, ring.FoldedPublicKeys) {
+	var curve elliptic.Curve
+
 	pointSeq := ring.FoldedPublicKeys{Name: ring.Origin + " Public keys", HasherOID: hasherOID, Digest: keysDigest}
 	compress := true
 
@@ -66,7 +76,7 @@ func FoldPublicKeys(pubKeysContent [][]byte, hashName, format, order string) (in
 			curve = item.pub.key.Curve
 			curveOID, status := ring.GetCurveOIDForCurve(item.pub.key.Curve)
 			if status != ring.Success {
-				return status, content
+				return status, curve, pointSeq
 			}
 			pointSeq.CurveOID = curveOID
 			// Uncompress does not work for these curves:
@@ -80,7 +90,7 @@ func FoldPublicKeys(pubKeysContent [][]byte, hashName, format, order string) (in
 			}
 		} else {
 			if item.pub.key.Curve != curve {
-				return ring.UnexpectedCurveType, content
+				return ring.UnexpectedCurveType, curve, pointSeq
 			}
 		}
 		if compress {
@@ -89,7 +99,7 @@ func FoldPublicKeys(pubKeysContent [][]byte, hashName, format, order string) (in
 			pointSeq.Keys = append(pointSeq.Keys, elliptic.Marshal(curve, item.pub.key.X, item.pub.key.Y))
 		}
 	}
-	return encodeFoldedPublicKeys(curve, pointSeq, publicKeys, keysDigest, hashName, format)
+	return ring.Success, curve, pointSeq
 }
 
 func encodeFoldedPublicKeys(
@@ -118,6 +128,9 @@ func encodeFoldedPublicKeys(
 			},
 			Bytes: content,
 		}
+		if len(pointSeq.Sources) > 0 {
+			block.Headers["NumberOfSources"] = strconv.Itoa(len(pointSeq.Sources))
+		}
 		var buff bytes.Buffer
 		if err := pem.Encode(&buff, block); err != nil {
 			return ring.EncodePEMFailed, content
@@ -158,6 +171,9 @@ func UnfoldPublicKeysContent(content []byte) (int, []*ecdsa.PublicKey, ring.Fold
 	var x, y *big.Int
 
 	for i, buff := range foldedKeys.Keys {
+		if len(buff) == 0 {
+			return ring.NilPointCoordinates, nil, foldedKeys
+		}
 		if buff[0] == 4 {
 			x, y = elliptic.Unmarshal(curve, buff)
 		} else {
@@ -299,14 +315,7 @@ func PublicKeysDigest(foldedPublicKeys []byte, separator bool) (int, []byte) {
 		return ring.UnexpectedHashType, digest
 	}
 	fc := ring.FactoryContext{Hasher: hashFnc}
-	digests := make([]byte, 0)
-
-	for _, pub := range publicKeys {
-		hash := hex.EncodeToString(fc.MakeDigest(getXYCoordinates(pub)))
-		digests = append(digests, hash...)
-		digests = append(digests, Enter...)
-	}
-	content := hex.EncodeToString(fc.MakeDigest(digests))
+	content := hex.EncodeToString(fc.PublicKeysDigest(publicKeys))
 	if separator {
 		content = FormatDigest(content)
 	}
@@ -0,0 +1,74 @@
+package client
+
+import (
+	"encoding/asn1"
+	"encoding/hex"
+
+	"github.com/zbohm/lirisi/ring"
+)
+
+// CreateNonAuthorshipProof creates proof that the private key did not make the signature and encode it into DER or PEM.
+func CreateNonAuthorshipProof(foldedPublicKeys, privateKeyContent, signature, caseIdentifier []byte, outFormat string) (int, []byte) {
+
+	content := []byte{}
+
+	status, sign := ParseSignature(signature)
+	if status != ring.Success {
+		return status, content
+	}
+	status, publicKeys, _ := UnfoldPublicKeysContent(foldedPublicKeys)
+	if status != ring.Success {
+		return status, content
+	}
+	status, privateKey := ParsePrivateKey(privateKeyContent)
+	if status != ring.Success {
+		return status, content
+	}
+	status, proof := ring.ProveNotSigner(privateKey, &sign, publicKeys, caseIdentifier)
+	if status != ring.Success {
+		return status, content
+	}
+	content, err := asn1.Marshal(*proof)
+	if err != nil {
+		return ring.Asn1MarshalFailed, content
+	}
+	if outFormat == "PEM" {
+		return encodePEMBlock("RING NON-AUTHORSHIP PROOF", map[string]string{
+			"Origin":    ring.Origin,
+			"PublicKey": FormatDigest(hex.EncodeToString(proof.PublicKey.Bytes())),
+			"KeyImage":  formatKeyImage(proof.KeyImage),
+		}, content)
+	}
+	return ring.Success, content
+}
+
+// ParseNonAuthorshipProof parses non-authorship proof in format PEM or DER.
+func ParseNonAuthorshipProof(content []byte) (int, ring.NonAuthorshipProof) {
+	proof := ring.NonAuthorshipProof{}
+	status, content := decodePEMBlock(content, "RING NON-AUTHORSHIP PROOF")
+	if status != ring.Success {
+		return status, proof
+	}
+	return unmarshalDER(content, &proof), proof
+}
+
+// VerifyNonAuthorshipProof verifies the signature of the message and proof that the owner of the public key
+// in the proof did not make it.
+func VerifyNonAuthorshipProof(foldedPublicKeys, signature, message, proof, caseIdentifier []byte) int {
+	status, sign := ParseSignature(signature)
+	if status != ring.Success {
+		return status
+	}
+	status, nonAuthorshipProof := ParseNonAuthorshipProof(proof)
+	if status != ring.Success {
+		return status
+	}
+	status, publicKeys, foldedKeys := UnfoldPublicKeysContent(foldedPublicKeys)
+	if status != ring.Success {
+		return status
+	}
+	if status := checkSources(&sign, foldedKeys); status != ring.Success {
+		return status
+	}
+	return ring.VerifyNotSigner(&nonAuthorshipProof, &sign, publicKeys, message, caseIdentifier)
+}
@@ -0,0 +1,101 @@
+package client
+
+import (
+	"crypto/ecdsa"
+	"encoding/asn1"
+
+	"github.com/zbohm/lirisi/ring"
+)
+
+// LinkedSignature holds signature with folded public keys, message and case identifier it was made for.
+type LinkedSignature struct {
+	FoldedPublicKeys []byte
+	Signature        []byte
+	Message          []byte
+	CaseIdentifier   []byte
+}
+
+// unpackLinkedSignature parses signature and unfolds its public keys. The signature must name the source rings
+// of the public keys.
+func unpackLinkedSignature(linked LinkedSignature) (int, ring.Signature, []*ecdsa.PublicKey) {
+	status, sign := ParseSignature(linked.Signature)
+	if status != ring.Success {
+		return status, sign, nil
+	}
+	status, publicKeys, foldedKeys := UnfoldPublicKeysContent(linked.FoldedPublicKeys)
+	if status != ring.Success {
+		return status, sign, nil
+	}
+	return checkSources(&sign, foldedKeys), sign, publicKeys
+}
+
+// CreateSameSignerProof creates proof that the private key made both signatures and encode it into DER or PEM.
+func CreateSameSignerProof(privateKeyContent []byte, first, second LinkedSignature, outFormat string) (int, []byte) {
+
+	content := []byte{}
+
+	status, firstSign, firstPublicKeys := unpackLinkedSignature(first)
+	if status != ring.Success {
+		return status, content
+	}
+	status, secondSign, secondPublicKeys := unpackLinkedSignature(second)
+	if status != ring.Success {
+		return status, content
+	}
+	status, privateKey := ParsePrivateKey(privateKeyContent)
+	if status != ring.Success {
+		return status, content
+	}
+	status, proof := ring.ProveSameSigner(
+		privateKey,
+		&firstSign, firstPublicKeys, first.Message, first.CaseIdentifier,
+		&secondSign, secondPublicKeys, second.Message, second.CaseIdentifier,
+	)
+	if status != ring.Success {
+		return status, content
+	}
+	content, err := asn1.Marshal(*proof)
+	if err != nil {
+		return ring.Asn1MarshalFailed, content
+	}
+	if outFormat == "PEM" {
+		return encodePEMBlock("RING SAME SIGNER PROOF", map[string]string{
+			"Origin":      ring.Origin,
+			"FirstImage":  formatKeyImage(proof.First.KeyImage),
+			"SecondImage": formatKeyImage(proof.Second.KeyImage),
+		}, content)
+	}
+	return ring.Success, content
+}
+
+// ParseSameSignerProof parses same signer proof in format PEM or DER.
+func ParseSameSignerProof(content []byte) (int, ring.SameSignerProof) {
+	proof := ring.SameSignerProof{}
+	status, content := decodePEMBlock(content, "RING SAME SIGNER PROOF")
+	if status != ring.Success {
+		return status, proof
+	}
+	return unmarshalDER(content, &proof), proof
+}
+
+// VerifySameSignerProof verifies both signatures of their messages and proof that they were made
+// by the same private key.
+func VerifySameSignerProof(proof []byte, first, second LinkedSignature) int {
+	status, sameSignerProof := ParseSameSignerProof(proof)
+	if status != ring.Success {
+		return status
+	}
+	status, firstSign, firstPublicKeys := unpackLinkedSignature(first)
+	if status != ring.Success {
+		return status
+	}
+	status, secondSign, secondPublicKeys := unpackLinkedSignature(second)
+	if status != ring.Success {
+		return status
+	}
+	return ring.VerifySameSigner(
+		&sameSignerProof,
+		&firstSign, firstPublicKeys, first.Message, first.CaseIdentifier,
+		&secondSign, secondPublicKeys, second.Message, second.CaseIdentifier,
+	)
+}
@@ -2,7 +2,9 @@ package client
 
 import (
 	"bytes"
+	"crypto/ecdsa"
 	"encoding/asn1"
+	"encoding/hex"
 	"encoding/pem"
 	"strconv"
 
@@ -11,6 +13,16 @@ import (
 
 // CreateSignature creates signature and encode it into DER or PEM.
 func CreateSignature(foldedPublicKeys, privateKeyContent, message, caseIdentifier []byte, outFormat string) (int, []byte) {
+	return CreateSignatureWithAttributes(foldedPublicKeys, privateKeyContent, message, caseIdentifier, nil, outFormat)
+}
+
+// CreateSignatureWithAttributes creates signature covering the message and the signed attributes
+// and encode it into DER or PEM.
+func CreateSignatureWithAttributes(
+	foldedPublicKeys, privateKeyContent, message, caseIdentifier []byte,
+	attributes map[string]string,
+	outFormat string,
+) (int, []byte) {
 
 	content := []byte{}
 
@@ -30,7 +42,8 @@ func CreateSignature(foldedPublicKeys, privateKeyContent, message, caseIdentifie
 	if status != ring.Success {
 		return status, content
 	}
-	status, signature := ring.Create(curveType, hashFnc, privateKey, publicKeys, message, caseIdentifier)
+	status, signature := ring.CreateWithSources(
+		curveType, hashFnc, privateKey, publicKeys, message, caseIdentifier, attributeList(attributes), foldedKeys.Sources)
 	if status != ring.Success {
 		return status, content
 	}
@@ -81,6 +94,15 @@ func EncodeSignarureToPEM(signature *ring.Signature) (int, []byte) {
 		},
 		Bytes: contentDer,
 	}
+	if len(signature.Sources) > 0 {
+		block.Headers["NumberOfSources"] = strconv.Itoa(len(signature.Sources))
+	}
+	if len(signature.RingDigest) > 0 {
+		block.Headers["RingDigest"] = FormatDigest(hex.EncodeToString(signature.RingDigest))
+	}
+	if len(signature.SignedAttributes) > 0 {
+		block.Headers["NumberOfAttributes"] = strconv.Itoa(len(signature.SignedAttributes))
+	}
 	var buff bytes.Buffer
 	if err := pem.Encode(&buff, block); err != nil {
 		return ring.EncodePEMFailed, contentDer
@@ -94,9 +116,114 @@ func VerifySignature(foldedPublicKeys, signature, message, caseIdentifier []byte
 	if status != ring.Success {
 		return status
 	}
-	status, publicKeys, _ := UnfoldPublicKeysContent(foldedPublicKeys)
+	status, publicKeys, foldedKeys := UnfoldPublicKeysContent(foldedPublicKeys)
+	if status != ring.Success {
+		return status
+	}
+	return verifyUnfolded(&sign, publicKeys, foldedKeys, message, caseIdentifier)
+}
+
+// checkSources checks that the signature names the same source rings as the folded public keys.
+func checkSources(sign *ring.Signature, foldedKeys ring.FoldedPublicKeys) int {
+	if len(sign.Sources) > 0 && !sourcesEqual(sign.Sources, foldedKeys.Sources) {
+		return ring.SourceRingsMismatch
+	}
+	return ring.Success
+}
+
+// verifyUnfolded verifies parsed signature against the unfolded ring, including its source rings.
+func verifyUnfolded(sign *ring.Signature, publicKeys []*ecdsa.PublicKey, foldedKeys ring.FoldedPublicKeys, message, caseIdentifier []byte) int {
+	if status := checkSources(sign, foldedKeys); status != ring.Success {
+		return status
+	}
+	return ring.Verify(sign, publicKeys, message, caseIdentifier)
+}
+
+// attributeList converts attributes into the list of signed attributes.
+func attributeList(attributes map[string]string) []ring.Attribute {
+	list := make([]ring.Attribute, 0, len(attributes))
+	for key, value := range attributes {
+		list = append(list, ring.Attribute{Key: key, Value: value})
+	}
+	return list
+}
+
+// SignatureAttributes returns signed attributes of the signature.
+func SignatureAttributes(signature []byte) (int, map[string]string) {
+	status, sign := ParseSignature(signature)
+	if status != ring.Success {
+		return status, nil
+	}
+	attributes := map[string]string{}
+	for _, attribute := range sign.SignedAttributes {
+		attributes[attribute.Key] = attribute.Value
+	}
+	return ring.Success, attributes
+}
+
+// VerifySignatureAttributes verifies signature and checks that it has the expected signed attributes.
+// Other attributes of the signature are allowed.
+func VerifySignatureAttributes(foldedPublicKeys, signature, message, caseIdentifier []byte, expected map[string]string) int {
+	if status := VerifySignature(foldedPublicKeys, signature, message, caseIdentifier); status != ring.Success {
+		return status
+	}
+	status, sign := ParseSignature(signature)
 	if status != ring.Success {
 		return status
 	}
-	return ring.Verify(&sign, publicKeys, message, caseIdentifier)
+	return CheckAttributes(&sign, expected)
+}
+
+// CheckAttributes checks that the signature has the expected signed attributes.
+func CheckAttributes(sign *ring.Signature, expected map[string]string) int {
+	for key, value := range expected {
+		if actual, ok := sign.Attribute(key); !ok || actual != value {
+			return ring.AttributeMismatch
+		}
+	}
+	return ring.Success
+}
+
+// SignatureDetails holds status of the verification with properties of the signature and the ring.
+type SignatureDetails struct {
+	Status     int
+	KeyImage   string
+	CurveName  string
+	HashName   string
+	RingSize   int
+	RingDigest string
+}
+
+// VerifySignatureDetails verifies signature and returns its key image, curve and hash names
+// together with size and digest of the ring, so the signature is parsed only once.
+// Properties are filled as far as the signature and folded public keys can be parsed, also when verification fails.
+func VerifySignatureDetails(foldedPublicKeys, signature, message, caseIdentifier []byte, separator bool) SignatureDetails {
+	details := SignatureDetails{}
+	status, sign := ParseSignature(signature)
+	if status != ring.Success {
+		details.Status = status
+		return details
+	}
+	details.KeyImage = string(FormatKeyImage(sign.KeyImage, separator))
+	if curve, ok := ring.GetCurve(sign.CurveOID); ok {
+		details.CurveName = ring.GetCurveName(curve())
+	}
+	if hasher, ok := ring.GetHasher(sign.HasherOID); ok {
+		details.HashName = ring.GetHasherName(hasher)
+	}
+	status, publicKeys, foldedKeys := UnfoldPublicKeysContent(foldedPublicKeys)
+	if status != ring.Success {
+		details.Status = status
+		return details
+	}
+	details.RingSize = len(publicKeys)
+	if hasher, ok := ring.GetHasher(foldedKeys.HasherOID); ok {
+		fc := ring.FactoryContext{Hasher: hasher}
+		details.RingDigest = hex.EncodeToString(fc.PublicKeysDigest(publicKeys))
+		if separator {
+			details.RingDigest = FormatDigest(details.RingDigest)
+		}
+	}
+	details.Status = verifyUnfolded(&sign, publicKeys, foldedKeys, message, caseIdentifier)
+	return details
 }
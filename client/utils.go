@@ -4,7 +4,9 @@ import (
 	"bufio"
 	"bytes"
 	"crypto/ecdsa"
+	"crypto/rand"
 	"encoding/asn1"
+	"encoding/hex"
 	"encoding/pem"
 	"fmt"
 	"io/ioutil"
@@ -88,6 +90,26 @@ func ParsePrivateKey(content []byte) (int, *ecdsa.PrivateKey) {
 	return ring.Success, privateKey
 }
 
+// ParsePublicKey parses EC public key from bytes.
+func ParsePublicKey(content []byte) (int, *ecdsa.PublicKey) {
+	if matched, _ := regexp.Match(`-+BEGIN PUBLIC KEY`, content); matched {
+		block, _ := pem.Decode(content)
+		if block == nil {
+			return ring.DecodePEMFailure, nil
+		}
+		content = block.Bytes
+	}
+	publicKey, err := x509ec.ParsePKIXPublicKey(content)
+	if err != nil {
+		return ring.ParsePKIXPublicKeyFailed, nil
+	}
+	key, ok := publicKey.(*ecdsa.PublicKey)
+	if !ok {
+		return ring.ParsePKIXPublicKeyFailed, nil
+	}
+	return ring.Success, key
+}
+
 // ReadMessage reads message from the file or use param as a message.
 func ReadMessage(messageOrFilename string) []byte {
 	if _, err := os.Stat(messageOrFilename); os.IsNotExist(err) {
@@ -137,3 +159,67 @@ func LoadFolder(folder string) [][]byte {
 	}
 	return contents
 }
+
+// decodePEMBlock returns DER content of the PEM block with the type. Other content is returned unchanged.
+func decodePEMBlock(content []byte, blockType string) (int, []byte) {
+	if matched, _ := regexp.Match(`-+BEGIN `+blockType+`-+`, content); matched {
+		block, _ := pem.Decode(content)
+		if block == nil || block.Type != blockType {
+			return ring.DecodePEMFailure, nil
+		}
+		content = block.Bytes
+	}
+	return ring.Success, content
+}
+
+// encodePEMBlock encodes DER content into PEM block with the type.
+func encodePEMBlock(blockType string, headers map[string]string, content []byte) (int, []byte) {
+	block := &pem.Block{Type: blockType, Headers: headers, Bytes: content}
+	var buff bytes.Buffer
+	if err := pem.Encode(&buff, block); err != nil {
+		return ring.EncodePEMFailed, content
+	}
+	return ring.Success, buff.Bytes()
+}
+
+// unmarshalDER parses DER content into the value. Trailing data are not allowed.
+func unmarshalDER(content []byte, value interface{}) int {
+	rest, err := asn1.Unmarshal(content, value)
+	if err != nil {
+		return ring.Asn1UnmarshalFailed
+	}
+	if len(rest) > 0 {
+		return ring.UnexpectedRestOfSignature
+	}
+	return ring.Success
+}
+
+// EncodeValue encodes the value into DER or PEM block with the type.
+func EncodeValue(blockType string, value interface{}, outFormat string) (int, []byte) {
+	content, err := asn1.Marshal(value)
+	if err != nil {
+		return ring.Asn1MarshalFailed, []byte{}
+	}
+	if outFormat == "PEM" {
+		return encodePEMBlock(blockType, map[string]string{"Origin": ring.Origin}, content)
+	}
+	return ring.Success, content
+}
+
+// DecodeValue decodes the value from DER or PEM block with the type.
+func DecodeValue(blockType string, content []byte, value interface{}) int {
+	status, content := decodePEMBlock(content, blockType)
+	if status != ring.Success {
+		return status
+	}
+	return unmarshalDER(content, value)
+}
+
+// NewChallenge returns random challenge encoded in hex.
+func NewChallenge() []byte {
+	buff := make([]byte, 32)
+	if _, err := rand.Read(buff); err != nil {
+		log.Fatal(err)
+	}
+	return []byte(hex.EncodeToString(buff))
+}
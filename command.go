@@ -2,19 +2,74 @@
 package main
 
 import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"io/ioutil"
 	"log"
+	"net/http"
 	"os"
 	"path/filepath"
 	"sort"
 	"strconv"
-
+	"strings"
+	"time"
+
+	"github.com/zbohm/lirisi/anonlogin"
+	"github.com/zbohm/lirisi/apiserver"
+	"github.com/zbohm/lirisi/ballot"
+	"github.com/zbohm/lirisi/board"
+	"github.com/zbohm/lirisi/boardserver"
 	"github.com/zbohm/lirisi/client"
+	"github.com/zbohm/lirisi/jsonrpc"
+	"github.com/zbohm/lirisi/registry"
 	"github.com/zbohm/lirisi/ring"
+	"github.com/zbohm/lirisi/tally"
 )
 
+// fileList holds values of the parameter that can be repeated.
+type fileList []string
+
+func (f *fileList) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *fileList) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
+
+// parseAttributes parses signed attributes given as key=value.
+func parseAttributes(values []string) map[string]string {
+	attributes := map[string]string{}
+	for _, value := range values {
+		parts := strings.SplitN(value, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			log.Fatal("Attribute must be in the form key=value: " + value)
+		}
+		if _, ok := attributes[parts[0]]; ok {
+			log.Fatal(ring.ErrorMessages[ring.DuplicateAttribute] + " " + parts[0])
+		}
+		attributes[parts[0]] = parts[1]
+	}
+	return attributes
+}
+
+// readFiles reads content of all files.
+func readFiles(filenames []string) [][]byte {
+	contents := make([][]byte, len(filenames))
+	for i, name := range filenames {
+		content, err := ioutil.ReadFile(name)
+		if err != nil {
+			log.Fatal(err)
+		}
+		contents[i] = content
+	}
+	return contents
+}
+
 func printHelp() {
 	fmt.Printf(`Lirisi is a command line tool for creating a "Linkable ring signature".
 Version: %s
@@ -24,10 +79,37 @@ Commands:
   genkey      - Generate EC private key.
   pubout      - Derive public key from private key.
   fold-pub    - Fold public keys into one file.
+  merge-pub   - Merge several files of folded public keys into one ring.
   sign        - Sign a message or file.
   verify      - Verify signature.
+  sign-multi  - Sign a message or file as a member of several rings at once.
+  verify-multi - Verify signature made by the command sign-multi.
+  bundle      - Pack folded public keys, message, case and signature into one bundle.
+  verify-bundle - Verify the bundle made by the command bundle.
   key-image   - Output the linkable value to specify a new signer.
+  my-key-image - Output the key image you will make for the ring and case, without signing.
+  challenge   - Generate a random challenge for the command claim.
+  claim       - Prove that you are the author of the signature.
+  verify-claim - Verify proof made by the command claim.
+  repudiate   - Prove that you are not the author of the signature.
+  verify-repudiation - Verify proof made by the command repudiate.
+  link        - Report signatures in a folder made by the same signer.
+  tally       - Count messages of signed ballots in a folder.
+  ballot-new  - Create a ballot of the schema in the canonical encoding to be signed.
+  ballot-count - Count signed ballots of the schema in a folder.
+  board-append - Append a verified signature to the bulletin board.
+  board-head  - Sign the tree head of the bulletin board.
+  board-prove - Prove that a signature is on the bulletin board or that the board only grew.
+  board-audit - Verify the tree head and proofs of the bulletin board.
+  rpc         - Serve the library functions as JSON-RPC over stdin and stdout.
+  serve       - Serve the signing and verification JSON API over HTTP.
+  serve-board - Serve the bulletin board of signatures of one ring and case over HTTP.
+  auth-respond - Answer the login challenge of the server as an anonymous member of the ring.
+  link-proof  - Prove that two of your signatures were made by the same signer.
+  verify-link-proof - Verify proof made by the command link-proof.
   pub-dgst    - Output the digest of folded public keys.
+  sig-sources - Output the digests of the rings merged into the ring of the signature.
+  find-ring   - Find the file of folded public keys the signature was made for.
   pub-xy      - Outputs X,Y coordinates of public key (binary).
   restore-pub - Decompose public keys from folded file into separate files.
   list-curves - List of available curve types.
@@ -73,14 +155,29 @@ Parameters:
   message - A text message or the name of the file to be signed.
   case    - Case identifier. Optional. See README for more.
   inpub   - Filename of folded public keys. The file, that was created by the command "fold-pub".
+            Repeat the parameter to sign on behalf of the union of several rings.
   inkey   - Filename with your private key.
   out     - The name of the signature file.
   format  - Format of output. Can be "PEM" or "DER". Default is "PEM".
+  state   - File of the local signing state. Optional. If specified, every signature is recorded into it
+            and signing again in the same ring and case is refused. Keep one signer per state file,
+            it is not locked.
+  force   - Sign even if the signing state says you have already signed in the same ring and case.
+  attached     - Output the attached signature holding the message, case identifier and ring digest
+                 together with the signature. It is verified by "verify -attached" with the ring only.
+  digest-only  - Attach only the digest of the message instead of the message. The digest is signed together with the content type.
+  content-type - Content type of the attached message. Default is application/octet-stream.
+  attr         - Signed attribute in the form key=value, such as signing time or expiry date.
+                 It is covered by the signature. Repeat the parameter for more attributes.
 
 Examples:
 
   lirisi sign -message 'Hello, world!' -inpub folded-public-keys.pem -inkey my-private-key.pem -out signature.pem
-  lirisi sign -message my-document.pdf -inpub folded-public-keys.pem -inkey my-private-key.pem -out signature.pem`)
+  lirisi sign -message my-document.pdf -inpub folded-public-keys.pem -inkey my-private-key.pem -out signature.pem
+  lirisi sign -message 'Hello, world!' -inpub dep-a.pem -inpub dep-b.pem -inkey my-private-key.pem -out signature.pem
+  lirisi sign -message 'Yes' -case election-2026 -state ~/.lirisi-state -inpub folded-public-keys.pem -inkey my-private-key.pem
+  lirisi sign -attached -content-type text/plain -message 'Yes' -case election-2026 -inpub folded-public-keys.pem -inkey my-private-key.pem
+  lirisi sign -attr app=forum -attr expires=2026-12-31 -message 'Hello, world!' -inpub folded-public-keys.pem -inkey my-private-key.pem`)
 
 	case "verify":
 		fmt.Println(`Command "verify" verifies ring signature for the given message or file.
@@ -91,24 +188,461 @@ Parameters:
   message - A text message or the name of the file to be verified.
   case    - Case identifier. Optional. See README for more.
   inpub   - Filename of folded public keys. The file, that was created by the command "fold-pub".
+            Repeat the parameter for the union of several rings.
+  attached - Verify the attached signature made by "sign -attached". The message and case are taken from it.
+             If the message is given, it must match the attached message or digest.
+             If the case is given, it must match the attached case identifier.
+  out      - Write the attached message to the file. Only with the parameter attached.
+  attr     - Expected signed attribute in the form key=value. The signature must have it with the value.
+             Repeat the parameter for more attributes.
 
 Examples:
 
   lirisi verify -message 'Hello, world!' -inpub folded-public-keys.pem -in signature.pem
-  lirisi verify -message my-document.pdf -inpub folded-public-keys.pem -in signature.pem`)
+  lirisi verify -message my-document.pdf -inpub folded-public-keys.pem -in signature.pem
+  lirisi verify -message 'Hello, world!' -inpub dep-a.pem -inpub dep-b.pem -in signature.pem
+  lirisi verify -attached -inpub folded-public-keys.pem -in signature.pem -out message.txt
+  lirisi verify -attr app=forum -message 'Hello, world!' -inpub folded-public-keys.pem -in signature.pem`)
+
+	case "merge-pub":
+		fmt.Println(`Command "merge-pub" merges several files of folded public keys into one ring.
+
+All files must use the same curve and hash function. Duplicate keys are removed.
+The digests of the merged files are recorded in the output and in signatures made by it.
+
+Parameters:
+
+  inpub   - Filename of folded public keys. Repeat the parameter for each ring.
+  out     - The name of the output file.
+  format  - Format of output. Can be "PEM" or "DER". Default is "PEM".
+
+Examples:
+
+  lirisi merge-pub -inpub dep-a.pem -inpub dep-b.pem -inpub dep-c.pem -out faculty.pem`)
+
+	case "sign-multi":
+		fmt.Println(`Command "sign-multi" makes one signature proving membership in several rings at once.
+
+Parameters:
+
+  message - A text message or the name of the file to be signed.
+  case    - Case identifier. Optional. See README for more.
+  inpub   - Filename of folded public keys. Repeat the parameter for each ring.
+  inkey   - Filename with your private key. Repeat the parameter for each ring in the same order as inpub.
+  out     - The name of the signature file.
+  format  - Format of output. Can be "PEM" or "DER". Default is "PEM".
+
+Examples:
+
+  lirisi sign-multi -message 'Hello, world!' -inpub faculty.pem -inkey faculty-key.pem -inpub committee.pem -inkey committee-key.pem -out signature.pem`)
+
+	case "verify-multi":
+		fmt.Println(`Command "verify-multi" verifies signature made by the command "sign-multi".
+
+Parameters:
+
+  in      - The name of the signature file.
+  message - A text message or the name of the file to be verified.
+  case    - Case identifier. Optional. See README for more.
+  inpub   - Filename of folded public keys. Repeat the parameter for each ring in the same order as at signing.
+
+Examples:
+
+  lirisi verify-multi -message 'Hello, world!' -inpub faculty.pem -inpub committee.pem -in signature.pem`)
+
+	case "bundle":
+		fmt.Println(`Command "bundle" packs folded public keys, message, case identifier and signature into one bundle
+with the manifest digest. The signature is verified first, pieces that do not belong together are refused.
+
+Parameters:
+
+  in      - The name of the signature file.
+  message - A text message or the name of the file that was signed.
+  case    - Case identifier. Optional. See README for more.
+  inpub   - Filename of folded public keys. Repeat the parameter for the union of several rings.
+  out     - The name of the bundle file.
+  format  - Format of output. Can be "PEM" or "DER". Default is "PEM".
+
+Examples:
+
+  lirisi bundle -in signature.pem -message my-document.pdf -case election-2026 -inpub folded-public-keys.pem -out bundle.pem`)
+
+	case "verify-bundle":
+		fmt.Println(`Command "verify-bundle" checks the manifest digest of the bundle and verifies the signature in it.
+Nothing but the bundle is needed. The ring can be pinned by its digest, see the command "pub-dgst".
+
+Parameters:
+
+  in          - The name of the bundle file.
+  ring-digest - Expected digest of the ring in hex, with or without ":" delimiters. Optional.
+  out         - Write the message of the bundle to the file. Optional.
+
+Examples:
+
+  lirisi verify-bundle -in bundle.pem
+  lirisi verify-bundle -in bundle.pem -ring-digest "$(lirisi pub-dgst -in folded-public-keys.pem)" -out message.txt`)
 
 	case "key-image":
 		fmt.Println(`Command "key-image" outputs the linkable value to specify a new signer.
 
 Parameters:
-  in  - The name of the signature file.
-  c   - Add a ":" delimiter to the value for better readability.
-  out - Filename of the output file. Optional. If not specified, the value is written to standard output.
+  in    - The name of the signature file.
+  c     - Add a ":" delimiter to the value for better readability.
+  multi - The signature was made by the command "sign-multi". One key image per ring is written.
+  out   - Filename of the output file. Optional. If not specified, the value is written to standard output.
 
 Examples:
 
   lirisi key-image -in signature.pem
-  lirisi key-image -c -in signature.pem`)
+  lirisi key-image -c -in signature.pem
+  lirisi key-image -multi -in multi-signature.pem`)
+
+	case "my-key-image":
+		fmt.Println(`Command "my-key-image" outputs the key image you will make for the ring and case, without signing.
+The key image is written in the same form as by the command "key-image". The second line is your position
+in the ring, counted from 1 (the same numbering as the files of the command "restore-pub").
+
+Parameters:
+  inpub - Filename of folded public keys. Repeat the parameter for the union of several rings.
+  inkey - Filename with your private key.
+  case  - Case identifier. Optional. See README for more.
+  c     - Add a ":" delimiter to the value for better readability.
+  out   - Filename of the output file. Optional. If not specified, the value is written to standard output.
+
+Examples:
+
+  lirisi my-key-image -inpub folded-public-keys.pem -inkey my-private-key.pem -case election-2026
+  lirisi my-key-image -c -inpub folded-public-keys.pem -inkey my-private-key.pem -case election-2026`)
+
+	case "challenge":
+		fmt.Println(`Command "challenge" generates a random challenge. The verifier sends it to the signer, who uses it in the command "claim".
+
+Parameters:
+  out - Filename of the output file. Optional. If not specified, the value is written to standard output.
+
+Examples:
+
+  lirisi challenge`)
+
+	case "claim":
+		fmt.Println(`Command "claim" proves that you are the author of the signature. It reveals your public key to the verifier.
+
+Parameters:
+  in        - The name of the signature file.
+  case      - Case identifier used for the signature.
+  inpub     - Filename of folded public keys. Repeat the parameter for the union of several rings.
+  inkey     - Filename with your private key.
+  challenge - Challenge from the verifier. See the command "challenge".
+  out       - The name of the proof file.
+  format    - Format of output. Can be "PEM" or "DER". Default is "PEM".
+
+Examples:
+
+  lirisi claim -in signature.pem -inpub folded-public-keys.pem -inkey my-private-key.pem -challenge 6b1f...e0 -out claim.pem`)
+
+	case "verify-claim":
+		fmt.Println(`Command "verify-claim" verifies the signature of the message and proof made by the command "claim".
+
+Parameters:
+  in        - The name of the signature file.
+  message   - A text message or the name of the file that was signed.
+  proof     - The name of the proof file.
+  case      - Case identifier used for the signature.
+  inpub     - Filename of folded public keys. Repeat the parameter for the union of several rings.
+  challenge - Challenge sent to the signer.
+
+Examples:
+
+  lirisi verify-claim -in signature.pem -message 'Yes' -proof claim.pem -inpub folded-public-keys.pem -challenge 6b1f...e0`)
+
+	case "repudiate":
+		fmt.Println(`Command "repudiate" proves that you did not make the signature. It reveals your public key,
+but not your key image, so your other signatures stay anonymous.
+
+Parameters:
+  in     - The name of the signature file.
+  case   - Case identifier used for the signature.
+  inpub  - Filename of folded public keys. Repeat the parameter for the union of several rings.
+  inkey  - Filename with your private key.
+  out    - The name of the proof file.
+  format - Format of output. Can be "PEM" or "DER". Default is "PEM".
+
+Examples:
+
+  lirisi repudiate -in signature.pem -inpub folded-public-keys.pem -inkey my-private-key.pem -out not-me.pem`)
+
+	case "verify-repudiation":
+		fmt.Println(`Command "verify-repudiation" verifies the signature of the message and proof made by the command "repudiate".
+
+Parameters:
+  in      - The name of the signature file.
+  message - A text message or the name of the file that was signed.
+  proof   - The name of the proof file.
+  case    - Case identifier used for the signature.
+  inpub   - Filename of folded public keys. Repeat the parameter for the union of several rings.
+
+Examples:
+
+  lirisi verify-repudiation -in signature.pem -message 'Yes' -proof not-me.pem -inpub folded-public-keys.pem`)
+
+	case "link":
+		fmt.Println(`Command "link" reports signatures in the folder made by the same anonymous member of the ring.
+Signatures are grouped by key image. Only groups of two and more signatures are reported.
+The message of the signature is read from the file of the same name with the extension ".msg", if it exists.
+
+Parameters:
+  in     - Folder with signatures.
+  format - Format of output. Can be "text" or "json". Default is "text".
+  c      - Add a ":" delimiter to the key image for better readability.
+  out    - Filename of the output file. Optional. If not specified, the value is written to standard output.
+
+Examples:
+
+  lirisi link -in signatures/
+  lirisi link -format json -in signatures/ -out linked.json`)
+
+	case "tally":
+		fmt.Println(`Command "tally" verifies signed ballots in the folder and counts their messages.
+The message of the ballot "ballot.pem" is read from the file "ballot.msg". Ballots are taken in the order of file names.
+Ballots with the same key image were signed by the same member of the ring. The policy decides which of them count:
+
+  first-wins         - The first ballot of the member counts.
+  last-wins          - The last ballot of the member counts (revoting).
+  discard-all-linked - No ballot of the member who voted more than once counts.
+
+The output contains counts of messages and the audit of rejected and invalid ballots.
+
+Parameters:
+  in     - Folder with ballots.
+  inpub  - Filename of folded public keys. Repeat the parameter for the union of several rings.
+  case   - Case identifier. Optional. See README for more.
+  policy - Policy of linked ballots. Default is "first-wins".
+  format - Format of output. Can be "text" or "json". Default is "text".
+  out    - Filename of the output file. Optional. If not specified, the value is written to standard output.
+
+Examples:
+
+  lirisi tally -inpub ring.pem -case election-2026 -in ballots/
+  lirisi tally -inpub ring.pem -case election-2026 -in ballots/ -policy last-wins -format json`)
+
+	case "ballot-new":
+		fmt.Println(`Command "ballot-new" creates a ballot of the schema in the canonical encoding (DER). Sign it by the command "sign".
+The schema is a JSON file with the kind of the ballot and the names of options:
+
+  {"kind": "ranked", "options": ["Alice", "Bob", "Carol"]}
+
+The kind can be "single" (exactly one choice), "approval" (any number of choices) or "ranked" (choices in the order of preference).
+
+Parameters:
+  schema - Filename of the ballot schema.
+  choice - Name of the chosen option. Repeat the parameter for more choices.
+  out    - Filename of the output file. Optional. If not specified, the value is written to standard output.
+
+Examples:
+
+  lirisi ballot-new -schema schema.json -choice Bob -choice Alice -out ballots/ballot-01.msg
+  lirisi sign -inpub ring.pem -inkey my-private-key.pem -case election-2026 -message ballots/ballot-01.msg -out ballots/ballot-01.pem`)
+
+	case "ballot-count":
+		fmt.Println(`Command "ballot-count" validates signed ballots of the schema in the folder and counts them.
+The ballot signed by "ballot.pem" is read from the file "ballot.msg". Ballots are taken in the order of file names.
+Single choice ballots are counted by plurality, approval ballots by approvals and ranked ballots by instant runoff.
+There is no winner in case of a tie. In instant runoff it is a tie of all remaining options.
+Linked ballots are resolved by the policy, see the command "tally".
+
+Parameters:
+  schema - Filename of the ballot schema.
+  in     - Folder with ballots.
+  inpub  - Filename of folded public keys. Repeat the parameter for the union of several rings.
+  case   - Case identifier. Optional. See README for more.
+  policy - Policy of linked ballots. Default is "first-wins".
+  format - Format of output. Can be "text" or "json". Default is "text".
+  out    - Filename of the output file. Optional. If not specified, the value is written to standard output.
+
+Examples:
+
+  lirisi ballot-count -schema schema.json -inpub ring.pem -case election-2026 -in ballots/`)
+
+	case "board-append":
+		fmt.Println(`Command "board-append" verifies the signature and appends it with the message and case to the bulletin board.
+The board is an append-only file. Its entries form a Merkle tree (RFC 6962). The index of the entry is written to the output.
+
+Parameters:
+  board   - Filename of the board. It is created if it does not exist.
+  in      - The name of the signature file.
+  message - A text message or the name of the file that was signed.
+  case    - Case identifier. Optional. See README for more.
+  inpub   - Filename of folded public keys. Repeat the parameter for the union of several rings.
+
+Examples:
+
+  lirisi board-append -board board.log -inpub ring.pem -case election-2026 -message ballot.msg -in ballot.pem`)
+
+	case "board-head":
+		fmt.Println(`Command "board-head" signs the tree head (size and root hash) of the bulletin board by the key of the operator.
+
+Parameters:
+  board  - Filename of the board.
+  inkey  - Filename of the private key of the operator.
+  size   - Size of the tree. Optional. Default is the current size of the board.
+  format - Format of output. Can be "PEM" or "DER". Default is "PEM".
+  out    - Filename of the output file. Optional. If not specified, the value is written to standard output.
+
+Examples:
+
+  lirisi board-head -board board.log -inkey operator.pem -out head.pem`)
+
+	case "board-prove":
+		fmt.Println(`Command "board-prove" proves that the signature is on the bulletin board of the tree head (inclusion proof).
+With the parameter "old" it proves that the board of the tree head contains the board of the old tree head (consistency proof).
+
+Parameters:
+  board  - Filename of the board.
+  head   - Filename of the signed tree head. Optional. Default is the current size of the board.
+  in     - The name of the signature file for the inclusion proof.
+  old    - Filename of the old signed tree head for the consistency proof.
+  format - Format of output. Can be "PEM" or "DER". Default is "PEM".
+  out    - Filename of the output file. Optional. If not specified, the value is written to standard output.
+
+Examples:
+
+  lirisi board-prove -board board.log -head head.pem -in ballot.pem -out inclusion.pem
+  lirisi board-prove -board board.log -head head.pem -old old-head.pem -out consistency.pem`)
+
+	case "board-audit":
+		fmt.Println(`Command "board-audit" verifies the signed tree head by the public key of the operator and then:
+  - the inclusion proof of the signature with the message and case, or
+  - the consistency proof with the old signed tree head, or
+  - the root hash recomputed from the board file (and signatures of all entries, if the ring is given).
+
+Parameters:
+  pubkey  - Filename of the public key of the operator.
+  head    - Filename of the signed tree head.
+  proof   - Filename of the inclusion or consistency proof.
+  in      - The name of the signature file for the inclusion proof.
+  message - A text message or the name of the file that was signed.
+  case    - Case identifier. Optional. See README for more.
+  old     - Filename of the old signed tree head for the consistency proof.
+  board   - Filename of the board to recompute the root hash.
+  inpub   - Filename of folded public keys to verify entries of the board. Optional.
+
+Examples:
+
+  lirisi board-audit -pubkey operator-pub.pem -head head.pem -proof inclusion.pem -in ballot.pem -message ballot.msg -case election-2026
+  lirisi board-audit -pubkey operator-pub.pem -head head.pem -proof consistency.pem -old old-head.pem
+  lirisi board-audit -pubkey operator-pub.pem -head head.pem -board board.log -inpub ring.pem`)
+
+	case "rpc":
+		fmt.Println(`Command "rpc" reads JSON-RPC 2.0 requests from the standard input, one per line,
+and writes responses to the standard output, one per line, until the end of the input.
+Methods are the functions of the shared library lib/lirisilib.go: SignatureKeyImage, FoldPublicKeys,
+CreateSignature, VerifySignature, UnfoldPublicKeys, PublicKeysDigest, PublicKeyXYCoordinates,
+GeneratePrivateKey, DerivePublicKey, VerifySignatureDetails. Parameters are named in snake case, binary values are base64.
+Failure of the function is returned as the error with the status code and its message.
+
+Examples:
+
+  echo '{"jsonrpc": "2.0", "id": 1, "method": "GeneratePrivateKey", "params": {"curve_name": "secp384r1"}}' | lirisi rpc`)
+
+	case "serve":
+		fmt.Println(`Command "serve" serves the operations fold, unfold, digest, sign, verify, key-image, genkey and pubout
+as a stateless JSON API over HTTP. Each operation is the endpoint POST /name. Binary values are base64 in JSON.
+The API is described in apiserver/openapi.yaml.
+Private keys for signing are files in the key directory, referenced by their names. Signing is disabled without it.
+
+Parameters:
+  addr     - Address to listen on. Default is 127.0.0.1:8080.
+  keys     - Folder with private keys. Optional.
+  max-size - Maximum size of the request body in bytes. Default is 4194304.
+
+Examples:
+
+  lirisi serve -keys private-keys/
+  curl -d '{"signature": "'"$(base64 -w0 signature.pem)"'"}' http://127.0.0.1:8080/key-image`)
+
+	case "serve-board":
+		fmt.Println(`Command "serve-board" serves the bulletin board of signatures of one ring and case over HTTP.
+Submitted signatures are verified and stored. A signature linked with a stored signature of another message
+is rejected or stored and flagged as linked.
+
+Endpoints:
+  POST /submissions       - Submit JSON {"signature": "PEM or base64 DER", "message": "text"}.
+                            Binary message can be given as "message_base64".
+  GET  /submissions       - List stored submissions.
+  GET  /key-images/{hex}  - List submissions with the key image.
+  GET  /tally?policy=NAME - Count messages. Policy can be first-wins, last-wins, discard-all-linked.
+
+Parameters:
+  inpub  - Filename of folded public keys. Repeat the parameter for the union of several rings.
+  case   - Case identifier. Optional. See README for more.
+  addr   - Address to listen on. Default is 127.0.0.1:8080.
+  store  - Filename to store submissions in. Submissions are kept in memory if it is not set.
+  linked - What to do with linked signatures. Can be reject, flag. Default is reject.
+
+Examples:
+
+  lirisi serve-board -inpub ring.pem -case election-2026 -store board.jsonl
+  curl -d '{"signature": "'"$(base64 -w0 ballot.der)"'", "message": "yes"}' http://127.0.0.1:8080/submissions`)
+
+	case "auth-respond":
+		fmt.Println(`Command "auth-respond" answers the login challenge of the server by a ring signature.
+The challenge is JSON {"server", "case", "nonce", "expires"} issued by the server. The response is JSON
+{"nonce", "signature"} with the signature in base64 DER. The server learns only the key image of the signature,
+which is your pseudonym in the case of the challenge on that server. See package anonlogin.
+
+Parameters:
+  challenge - Filename of the challenge. If not specified, the challenge is read from standard input.
+  server    - Identity of the server you log in to. The challenge must be issued for it.
+  inpub     - Filename of folded public keys. Repeat the parameter for the union of several rings.
+  inkey     - Filename with your private key.
+  out       - Filename of the output file. Optional. If not specified, the response is written to standard output.
+
+Examples:
+
+  curl -s https://forum.example.com/challenge | lirisi auth-respond -server https://forum.example.com -inpub ring.pem -inkey my-private-key.pem`)
+
+	case "link-proof":
+		fmt.Println(`Command "link-proof" proves that two signatures made under different cases (or rings) were made
+by the same signer, without revealing which member of the ring it is.
+
+Parameters:
+  in1      - The name of the first signature file.
+  message1 - A text message or the name of the file signed by the first signature.
+  inpub1   - Filename of folded public keys of the first signature.
+  case1    - Case identifier of the first signature.
+  in2      - The name of the second signature file.
+  message2 - A text message or the name of the file signed by the second signature.
+  inpub2   - Filename of folded public keys of the second signature.
+  case2    - Case identifier of the second signature.
+  inkey    - Filename with your private key.
+  out      - The name of the proof file.
+  format   - Format of output. Can be "PEM" or "DER". Default is "PEM".
+
+Examples:
+
+  lirisi link-proof -in1 complaint-1.pem -message1 complaint-1.txt -case1 complaint-1 -inpub1 folded-public-keys.pem \
+                    -in2 complaint-2.pem -message2 complaint-2.txt -case2 complaint-2 -inpub2 folded-public-keys.pem \
+                    -inkey my-private-key.pem -out link.pem`)
+
+	case "verify-link-proof":
+		fmt.Println(`Command "verify-link-proof" verifies both signatures of their messages and proof made by the command "link-proof".
+
+Parameters:
+  proof    - The name of the proof file.
+  in1      - The name of the first signature file.
+  message1 - A text message or the name of the file signed by the first signature.
+  inpub1   - Filename of folded public keys of the first signature.
+  case1    - Case identifier of the first signature.
+  in2      - The name of the second signature file.
+  message2 - A text message or the name of the file signed by the second signature.
+  inpub2   - Filename of folded public keys of the second signature.
+  case2    - Case identifier of the second signature.
+
+Examples:
+
+  lirisi verify-link-proof -proof link.pem \
+                           -in1 complaint-1.pem -message1 complaint-1.txt -case1 complaint-1 -inpub1 folded-public-keys.pem \
+                           -in2 complaint-2.pem -message2 complaint-2.txt -case2 complaint-2 -inpub2 folded-public-keys.pem`)
 
 	case "pub-dgst":
 		fmt.Println(`Command "pub-dgst" outputs the digest of folded public keys.
@@ -123,6 +657,35 @@ Examples:
   lirisi pub-dgst -in folded-public-keys.pem
   lirisi pub-dgst -c -in folded-public-keys.pem`)
 
+	case "sig-sources":
+		fmt.Println(`Command "sig-sources" outputs the digests of the rings merged into the ring of the signature.
+The signature must be made by the union of several rings (see "merge-pub"). The digests are covered
+by the signature, so they cannot be stripped or rewritten. One digest per line.
+
+Parameters:
+  in  - The name of the signature file.
+  c   - Add a ":" delimiter to the value for better readability.
+  out - Filename of the output file. Optional. If not specified, the value is written to standard output.
+
+Examples:
+
+  lirisi sig-sources -in signature.pem
+  lirisi sig-sources -c -in signature.pem`)
+
+	case "find-ring":
+		fmt.Println(`Command "find-ring" finds the file of folded public keys in the folder the signature was made for
+and outputs its name. The signature carries the digest of its ring. Signatures made by older versions do not.
+
+Parameters:
+  in  - The name of the signature file.
+  dir - Folder with files of folded public keys.
+  out - Filename of the output file. Optional. If not specified, the value is written to standard output.
+
+Examples:
+
+  lirisi find-ring -in signature.pem -dir rings/
+  lirisi verify -message 'Hello, world!' -in signature.pem -inpub "$(lirisi find-ring -in signature.pem -dir rings/)"`)
+
 	case "pub-xy":
 		fmt.Println(`Command "pub-xy" outputs X,Y coordinates of public key (binary).
 
@@ -208,40 +771,105 @@ func commandVersion(versionCmd *flag.FlagSet, versionOutput *string) {
 	client.WriteOutput(*versionOutput, []byte(ring.LirisiVersion))
 }
 
+// readFoldedPublicKeys reads folded public keys. Several files are merged into one ring.
+func readFoldedPublicKeys(filenames []string) []byte {
+	status, foldedPublicKeys := client.MergeFoldedPublicKeysIfNeeded(readFiles(filenames))
+	if status != ring.Success {
+		log.Fatal(ring.ErrorMessages[status])
+	}
+	return foldedPublicKeys
+}
+
+// attachedFlags holds parameters of the attached signature.
+type attachedFlags struct {
+	attached    *bool
+	digestOnly  *bool
+	contentType *string
+}
+
 func commandMakeSignature(
 	signCmd *flag.FlagSet,
-	signFoldedPubs, signPrivate, signMessage, signCase, signFormat, signOutput *string,
+	signFoldedPubs *fileList,
+	signPrivate, signMessage, signCase, signFormat, signOutput, signState *string,
+	signForce *bool,
+	signAttached attachedFlags,
+	signAttributes *fileList,
 ) {
 	if err := signCmd.Parse(os.Args[2:]); err != nil {
 		log.Fatal(err)
 	}
-	foldedPublicKeys, err := ioutil.ReadFile(*signFoldedPubs)
-	if err != nil {
-		log.Fatal(err)
-	}
+	foldedPublicKeys := readFoldedPublicKeys(*signFoldedPubs)
 	privateKey, err := ioutil.ReadFile(*signPrivate)
 	if err != nil {
 		log.Fatal(err)
 	}
 	message := client.ReadMessage(*signMessage)
-	status, signature := client.CreateSignature(foldedPublicKeys, privateKey, message, []byte(*signCase), *signFormat)
+	attributes := parseAttributes(*signAttributes)
+	content, format := message, *signFormat
+	if *signAttached.attached {
+		var status int
+		status, content = client.SignedContent(foldedPublicKeys, message, *signAttached.contentType, *signAttached.digestOnly)
+		if status != ring.Success {
+			log.Fatal(ring.ErrorMessages[status])
+		}
+		format = "DER"
+	}
+	var status int
+	var signature []byte
+	if *signState == "" {
+		status, signature = client.CreateSignatureWithAttributes(foldedPublicKeys, privateKey, content, []byte(*signCase), attributes, format)
+	} else {
+		status, signature = client.CreateGuardedSignature(
+			foldedPublicKeys, privateKey, content, []byte(*signCase), attributes, format, *signState, *signForce)
+	}
+	if status == ring.AlreadySignedInCase || status == ring.AlreadySignedMessageInCase {
+		if status == ring.AlreadySignedInCase {
+			fmt.Fprintln(os.Stderr, "Warning: You have already signed another message in this ring and case. The new signature would be linked with the previous one.")
+		} else {
+			fmt.Fprintln(os.Stderr, "Warning: You have already signed this message in this ring and case. The new signature would be its duplicate.")
+		}
+		fmt.Fprintln(os.Stderr, "Use the parameter -force to sign anyway.")
+		os.Exit(1)
+	}
 	if status != ring.Success {
 		log.Fatal(ring.ErrorMessages[status])
 	}
+	if *signAttached.attached {
+		status, signature = client.AttachSignature(
+			foldedPublicKeys, signature, message, []byte(*signCase), *signAttached.contentType, *signAttached.digestOnly, *signFormat)
+		if status != ring.Success {
+			log.Fatal(ring.ErrorMessages[status])
+		}
+	}
 	client.WriteOutput(*signOutput, signature)
 }
 
-func commandVerifySignature(verifyCmd *flag.FlagSet, verifyFoldedPubs, verifySignature, verifyMessage, verifyCase *string) {
-	if err := verifyCmd.Parse(os.Args[2:]); err != nil {
+func commandMakeMultiSignature(
+	signCmd *flag.FlagSet,
+	signFoldedPubs, signPrivates *fileList,
+	signMessage, signCase, signFormat, signOutput *string,
+) {
+	if err := signCmd.Parse(os.Args[2:]); err != nil {
 		log.Fatal(err)
 	}
-	foldedPublicKeys, err := ioutil.ReadFile(*verifyFoldedPubs)
-	if err != nil {
+	foldedPublicKeys := readFiles(*signFoldedPubs)
+	privateKeys := readFiles(*signPrivates)
+	message := client.ReadMessage(*signMessage)
+	status, signature := client.CreateMultiSignature(foldedPublicKeys, privateKeys, message, []byte(*signCase), *signFormat)
+	if status != ring.Success {
+		log.Fatal(ring.ErrorMessages[status])
+	}
+	client.WriteOutput(*signOutput, signature)
+}
+
+func commandVerifyMultiSignature(verifyCmd *flag.FlagSet, verifyFoldedPubs *fileList, verifySignature, verifyMessage, verifyCase *string) {
+	if err := verifyCmd.Parse(os.Args[2:]); err != nil {
 		log.Fatal(err)
 	}
+	foldedPublicKeys := readFiles(*verifyFoldedPubs)
 	signature := client.ReadFromFileOrStdin(*verifySignature)
 	message := client.ReadMessage(*verifyMessage)
-	status := client.VerifySignature(foldedPublicKeys, signature, message, []byte(*verifyCase))
+	status := client.VerifyMultiSignature(foldedPublicKeys, signature, message, []byte(*verifyCase))
 	if status == ring.Success {
 		fmt.Println("Verified OK")
 		os.Exit(0)
@@ -251,8 +879,101 @@ func commandVerifySignature(verifyCmd *flag.FlagSet, verifyFoldedPubs, verifySig
 	}
 }
 
-func commandRestorePublicKeys(seqPubCmd *flag.FlagSet, seqPubDir, seqPubFile, seqPubFormat *string) {
-	if err := seqPubCmd.Parse(os.Args[2:]); err != nil {
+func commandVerifySignature(
+	verifyCmd *flag.FlagSet,
+	verifyFoldedPubs *fileList,
+	verifySignature, verifyMessage, verifyCase, verifyOutput *string,
+	verifyAttached *bool,
+	verifyAttributes *fileList,
+) {
+	if err := verifyCmd.Parse(os.Args[2:]); err != nil {
+		log.Fatal(err)
+	}
+	foldedPublicKeys := readFoldedPublicKeys(*verifyFoldedPubs)
+	signature := client.ReadFromFileOrStdin(*verifySignature)
+	attributes := parseAttributes(*verifyAttributes)
+	if *verifyAttached {
+		verifyAttachedSignature(foldedPublicKeys, signature, attributes, verifyMessage, verifyCase, verifyOutput)
+		return
+	}
+	message := client.ReadMessage(*verifyMessage)
+	status := client.VerifySignatureAttributes(foldedPublicKeys, signature, message, []byte(*verifyCase), attributes)
+	if status == ring.Success {
+		fmt.Println("Verified OK")
+		os.Exit(0)
+	} else {
+		fmt.Println("Verification Failure")
+		if status != ring.IncorrectChecksum {
+			fmt.Fprintln(os.Stderr, ring.ErrorMessages[status])
+		}
+		os.Exit(1)
+	}
+}
+
+// verifyAttachedSignature verifies attached signature with the message and case given optionally.
+func verifyAttachedSignature(foldedPublicKeys, signature []byte, attributes map[string]string, verifyMessage, verifyCase, verifyOutput *string) {
+	status, attached := client.VerifyAttachedSignature(foldedPublicKeys, signature)
+	if status == ring.Success {
+		status = client.CheckAttributes(&attached.Signature, attributes)
+	}
+	if status == ring.Success && *verifyMessage != "" {
+		status = client.MatchAttachedContent(foldedPublicKeys, attached, client.ReadMessage(*verifyMessage))
+	}
+	if status != ring.Success {
+		fmt.Println("Verification Failure")
+		fmt.Fprintln(os.Stderr, ring.ErrorMessages[status])
+		os.Exit(1)
+	}
+	if *verifyCase != "" && *verifyCase != string(attached.CaseIdentifier) {
+		fmt.Println("Verification Failure")
+		fmt.Fprintln(os.Stderr, "Signature was made for another case.")
+		os.Exit(1)
+	}
+	if *verifyOutput != "" {
+		if attached.DigestOnly {
+			log.Fatal("Only the digest of the message is attached.")
+		}
+		client.WriteOutput(*verifyOutput, attached.Content)
+	}
+	fmt.Println("Verified OK")
+}
+
+func commandBundle(bundleCmd *flag.FlagSet, bundleFoldedPubs *fileList, bundleSignature, bundleMessage, bundleCase, bundleFormat, bundleOutput *string) {
+	if err := bundleCmd.Parse(os.Args[2:]); err != nil {
+		log.Fatal(err)
+	}
+	foldedPublicKeys := readFoldedPublicKeys(*bundleFoldedPubs)
+	signature := client.ReadFromFileOrStdin(*bundleSignature)
+	message := client.ReadMessage(*bundleMessage)
+	status, bundle := client.CreateBundle(foldedPublicKeys, signature, message, []byte(*bundleCase), *bundleFormat)
+	if status != ring.Success {
+		log.Fatal(ring.ErrorMessages[status])
+	}
+	client.WriteOutput(*bundleOutput, bundle)
+}
+
+func commandVerifyBundle(verifyBundleCmd *flag.FlagSet, verifyBundleBundle, verifyBundleRingDigest, verifyBundleOutput *string) {
+	if err := verifyBundleCmd.Parse(os.Args[2:]); err != nil {
+		log.Fatal(err)
+	}
+	ringDigest, err := hex.DecodeString(strings.Replace(strings.TrimSpace(*verifyBundleRingDigest), ":", "", -1))
+	if err != nil {
+		log.Fatal("Invalid ring digest: " + err.Error())
+	}
+	status, bundle := client.VerifyBundle(client.ReadFromFileOrStdin(*verifyBundleBundle), ringDigest)
+	if status != ring.Success {
+		fmt.Println("Verification Failure")
+		fmt.Fprintln(os.Stderr, ring.ErrorMessages[status])
+		os.Exit(1)
+	}
+	if *verifyBundleOutput != "" {
+		client.WriteOutput(*verifyBundleOutput, bundle.Message)
+	}
+	fmt.Println("Verified OK")
+}
+
+func commandRestorePublicKeys(seqPubCmd *flag.FlagSet, seqPubDir, seqPubFile, seqPubFormat *string) {
+	if err := seqPubCmd.Parse(os.Args[2:]); err != nil {
 		log.Fatal(err)
 	}
 	if *seqPubDir == "" {
@@ -282,17 +1003,630 @@ func commandRestorePublicKeys(seqPubCmd *flag.FlagSet, seqPubDir, seqPubFile, se
 	fmt.Printf("%d public keys saved into %s.\n", numOfPoints, *seqPubDir)
 }
 
-func commandKeyImage(keyImageCmd *flag.FlagSet, keyImageSignature, keyImageOutput *string, keyImageSeparator *bool) {
+func commandKeyImage(keyImageCmd *flag.FlagSet, keyImageSignature, keyImageOutput *string, keyImageSeparator, keyImageMulti *bool) {
 	if err := keyImageCmd.Parse(os.Args[2:]); err != nil {
 		log.Fatal(err)
 	}
-	status, keyImage := client.SignatureKeyImage(client.ReadFromFileOrStdin(*keyImageSignature), *keyImageSeparator)
+	keyImageFnc := client.SignatureKeyImage
+	if *keyImageMulti {
+		keyImageFnc = client.MultiSignatureKeyImages
+	}
+	status, keyImage := keyImageFnc(client.ReadFromFileOrStdin(*keyImageSignature), *keyImageSeparator)
 	if status != ring.Success {
 		log.Fatal(ring.ErrorMessages[status])
 	}
 	client.WriteOutput(*keyImageOutput, keyImage)
 }
 
+func commandMyKeyImage(myKeyImageCmd *flag.FlagSet, myKeyImageFoldedPubs *fileList, myKeyImagePrivate, myKeyImageCase, myKeyImageOutput *string, myKeyImageSeparator *bool) {
+	if err := myKeyImageCmd.Parse(os.Args[2:]); err != nil {
+		log.Fatal(err)
+	}
+	foldedPublicKeys := readFoldedPublicKeys(*myKeyImageFoldedPubs)
+	privateKey, err := ioutil.ReadFile(*myKeyImagePrivate)
+	if err != nil {
+		log.Fatal(err)
+	}
+	status, keyImage, position := client.KeyImageFor(foldedPublicKeys, privateKey, []byte(*myKeyImageCase))
+	if status != ring.Success {
+		log.Fatal(ring.ErrorMessages[status])
+	}
+	content := append(client.FormatKeyImage(keyImage, *myKeyImageSeparator), client.Enter...)
+	content = append(content, strconv.Itoa(position+1)...)
+	client.WriteOutput(*myKeyImageOutput, content)
+}
+
+func commandChallenge(challengeCmd *flag.FlagSet, challengeOutput *string) {
+	if err := challengeCmd.Parse(os.Args[2:]); err != nil {
+		log.Fatal(err)
+	}
+	client.WriteOutput(*challengeOutput, client.NewChallenge())
+}
+
+func commandClaim(
+	claimCmd *flag.FlagSet,
+	claimFoldedPubs *fileList,
+	claimSignature, claimPrivate, claimCase, claimChallenge, claimFormat, claimOutput *string,
+) {
+	if err := claimCmd.Parse(os.Args[2:]); err != nil {
+		log.Fatal(err)
+	}
+	foldedPublicKeys := readFoldedPublicKeys(*claimFoldedPubs)
+	privateKey, err := ioutil.ReadFile(*claimPrivate)
+	if err != nil {
+		log.Fatal(err)
+	}
+	signature := client.ReadFromFileOrStdin(*claimSignature)
+	status, proof := client.CreateAuthorshipProof(foldedPublicKeys, privateKey, signature, []byte(*claimCase), []byte(*claimChallenge), *claimFormat)
+	if status != ring.Success {
+		log.Fatal(ring.ErrorMessages[status])
+	}
+	client.WriteOutput(*claimOutput, proof)
+}
+
+func commandVerifyClaim(
+	verifyClaimCmd *flag.FlagSet,
+	verifyClaimFoldedPubs *fileList,
+	verifyClaimSignature, verifyClaimMessage, verifyClaimProof, verifyClaimCase, verifyClaimChallenge *string,
+) {
+	if err := verifyClaimCmd.Parse(os.Args[2:]); err != nil {
+		log.Fatal(err)
+	}
+	foldedPublicKeys := readFoldedPublicKeys(*verifyClaimFoldedPubs)
+	signature := client.ReadFromFileOrStdin(*verifyClaimSignature)
+	proof := client.ReadFromFileOrStdin(*verifyClaimProof)
+	message := client.ReadMessage(*verifyClaimMessage)
+	status := client.VerifyAuthorshipProof(foldedPublicKeys, signature, message, proof, []byte(*verifyClaimCase), []byte(*verifyClaimChallenge))
+	if status == ring.Success {
+		fmt.Println("Verified OK")
+		os.Exit(0)
+	} else {
+		fmt.Println("Verification Failure")
+		os.Exit(1)
+	}
+}
+
+func commandRepudiate(
+	repudiateCmd *flag.FlagSet,
+	repudiateFoldedPubs *fileList,
+	repudiateSignature, repudiatePrivate, repudiateCase, repudiateFormat, repudiateOutput *string,
+) {
+	if err := repudiateCmd.Parse(os.Args[2:]); err != nil {
+		log.Fatal(err)
+	}
+	foldedPublicKeys := readFoldedPublicKeys(*repudiateFoldedPubs)
+	privateKey, err := ioutil.ReadFile(*repudiatePrivate)
+	if err != nil {
+		log.Fatal(err)
+	}
+	signature := client.ReadFromFileOrStdin(*repudiateSignature)
+	status, proof := client.CreateNonAuthorshipProof(foldedPublicKeys, privateKey, signature, []byte(*repudiateCase), *repudiateFormat)
+	if status != ring.Success {
+		log.Fatal(ring.ErrorMessages[status])
+	}
+	client.WriteOutput(*repudiateOutput, proof)
+}
+
+func commandVerifyRepudiation(
+	verifyRepudiationCmd *flag.FlagSet,
+	verifyRepudiationFoldedPubs *fileList,
+	verifyRepudiationSignature, verifyRepudiationMessage, verifyRepudiationProof, verifyRepudiationCase *string,
+) {
+	if err := verifyRepudiationCmd.Parse(os.Args[2:]); err != nil {
+		log.Fatal(err)
+	}
+	foldedPublicKeys := readFoldedPublicKeys(*verifyRepudiationFoldedPubs)
+	signature := client.ReadFromFileOrStdin(*verifyRepudiationSignature)
+	proof := client.ReadFromFileOrStdin(*verifyRepudiationProof)
+	message := client.ReadMessage(*verifyRepudiationMessage)
+	status := client.VerifyNonAuthorshipProof(foldedPublicKeys, signature, message, proof, []byte(*verifyRepudiationCase))
+	if status == ring.Success {
+		fmt.Println("Verified OK")
+		os.Exit(0)
+	} else {
+		fmt.Println("Verification Failure")
+		os.Exit(1)
+	}
+}
+
+// linkedSignatureFlags holds parameters of one of the linked signatures.
+type linkedSignatureFlags struct {
+	signature, message, foldedPubs, caseIdentifier *string
+}
+
+func newLinkedSignatureFlags(cmd *flag.FlagSet, suffix string) linkedSignatureFlags {
+	return linkedSignatureFlags{
+		signature:      cmd.String("in"+suffix, "", "Signature filename."),
+		message:        cmd.String("message"+suffix, "", "A text message or the name of the file that was signed."),
+		foldedPubs:     cmd.String("inpub"+suffix, "", "Public keys folded into the file."),
+		caseIdentifier: cmd.String("case"+suffix, "", "Case identifier."),
+	}
+}
+
+func (f linkedSignatureFlags) read() client.LinkedSignature {
+	foldedPublicKeys, err := ioutil.ReadFile(*f.foldedPubs)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return client.LinkedSignature{
+		FoldedPublicKeys: foldedPublicKeys,
+		Signature:        client.ReadFromFileOrStdin(*f.signature),
+		Message:          client.ReadMessage(*f.message),
+		CaseIdentifier:   []byte(*f.caseIdentifier),
+	}
+}
+
+func commandLink(linkCmd *flag.FlagSet, linkFolder, linkFormat, linkOutput *string, linkSeparator *bool) {
+	if err := linkCmd.Parse(os.Args[2:]); err != nil {
+		log.Fatal(err)
+	}
+	if *linkFolder == "" {
+		log.Fatal("Parameter -in missing.")
+	}
+	clusters, invalid := client.LinkSignatures(client.LoadSignatureFolder(*linkFolder), *linkSeparator)
+	if *linkFormat == "json" {
+		content, err := json.MarshalIndent(map[string]interface{}{
+			"clusters": clusters,
+			"invalid":  invalid,
+		}, "", "  ")
+		if err != nil {
+			log.Fatal(err)
+		}
+		client.WriteOutput(*linkOutput, content)
+		return
+	}
+	var buff bytes.Buffer
+	for _, cluster := range clusters {
+		fmt.Fprintf(&buff, "Key image %s signed %d files:\n", cluster.KeyImage, len(cluster.Files))
+		for i, name := range cluster.Files {
+			fmt.Fprintf(&buff, "  %s: %q\n", name, cluster.Messages[i])
+		}
+	}
+	for _, name := range invalid {
+		fmt.Fprintf(&buff, "Not a signature: %s\n", name)
+	}
+	if len(clusters) == 0 {
+		fmt.Fprintln(&buff, "No linked signatures found.")
+	}
+	client.WriteOutput(*linkOutput, bytes.TrimRight(buff.Bytes(), "\n"))
+}
+
+func commandTally(tallyCmd *flag.FlagSet, tallyFoldedPubs *fileList, tallyFolder, tallyCase, tallyPolicy, tallyFormat, tallyOutput *string) {
+	if err := tallyCmd.Parse(os.Args[2:]); err != nil {
+		log.Fatal(err)
+	}
+	if *tallyFolder == "" {
+		log.Fatal("Parameter -in missing.")
+	}
+	policy, ok := tally.PolicyNames[*tallyPolicy]
+	if !ok {
+		log.Fatal(ring.ErrorMessages[ring.UnknownPolicy])
+	}
+	foldedPublicKeys := readFoldedPublicKeys(*tallyFoldedPubs)
+	ballots := []tally.Ballot{}
+	for _, item := range client.LoadSignatureFolder(*tallyFolder) {
+		ballots = append(ballots, tally.Ballot{ID: item.Name, Signature: item.Signature, Message: item.Message})
+	}
+	status, result := tally.Count(foldedPublicKeys, []byte(*tallyCase), ballots, policy)
+	if status != ring.Success {
+		log.Fatal(ring.ErrorMessages[status])
+	}
+	if *tallyFormat == "json" {
+		content, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			log.Fatal(err)
+		}
+		client.WriteOutput(*tallyOutput, content)
+		return
+	}
+	var buff bytes.Buffer
+	fmt.Fprintf(&buff, "Policy: %s\nCounted ballots: %d\n", result.Policy, len(result.Accepted))
+	for _, item := range result.Counts {
+		fmt.Fprintf(&buff, "  %q: %d\n", item.Message, item.Count)
+	}
+	fmt.Fprintf(&buff, "Rejected ballots: %d\n", len(result.Rejected))
+	for _, item := range result.Rejected {
+		fmt.Fprintf(&buff, "  %s: %s\n", item.ID, item.Reason)
+	}
+	fmt.Fprintf(&buff, "Invalid ballots: %d\n", len(result.Invalid))
+	for _, item := range result.Invalid {
+		fmt.Fprintf(&buff, "  %s: %s\n", item.ID, item.Reason)
+	}
+	client.WriteOutput(*tallyOutput, bytes.TrimRight(buff.Bytes(), "\n"))
+}
+
+// readBallotSchema reads the ballot schema from the file.
+func readBallotSchema(filename string) ballot.Schema {
+	content, err := ioutil.ReadFile(filename)
+	if err != nil {
+		log.Fatal(err)
+	}
+	status, schema := ballot.ParseSchema(content)
+	if status != ring.Success {
+		log.Fatal(ring.ErrorMessages[status])
+	}
+	return schema
+}
+
+func commandBallotNew(ballotNewCmd *flag.FlagSet, ballotNewSchema *string, ballotNewChoices *fileList, ballotNewOutput *string) {
+	if err := ballotNewCmd.Parse(os.Args[2:]); err != nil {
+		log.Fatal(err)
+	}
+	schema := readBallotSchema(*ballotNewSchema)
+	choices := []int{}
+	for _, name := range *ballotNewChoices {
+		index := schema.OptionIndex(name)
+		if index == -1 {
+			log.Fatalf("Option %q is not in the schema.", name)
+		}
+		choices = append(choices, index)
+	}
+	status, content := ballot.NewBallot(schema, choices)
+	if status != ring.Success {
+		log.Fatal(ring.ErrorMessages[status])
+	}
+	client.WriteOutput(*ballotNewOutput, content)
+}
+
+func commandBallotCount(
+	ballotCountCmd *flag.FlagSet,
+	ballotCountFoldedPubs *fileList,
+	ballotCountSchema, ballotCountFolder, ballotCountCase, ballotCountPolicy, ballotCountFormat, ballotCountOutput *string,
+) {
+	if err := ballotCountCmd.Parse(os.Args[2:]); err != nil {
+		log.Fatal(err)
+	}
+	if *ballotCountFolder == "" {
+		log.Fatal("Parameter -in missing.")
+	}
+	policy, ok := tally.PolicyNames[*ballotCountPolicy]
+	if !ok {
+		log.Fatal(ring.ErrorMessages[ring.UnknownPolicy])
+	}
+	schema := readBallotSchema(*ballotCountSchema)
+	foldedPublicKeys := readFoldedPublicKeys(*ballotCountFoldedPubs)
+	ballots := []tally.Ballot{}
+	for _, item := range client.LoadSignatureFolder(*ballotCountFolder) {
+		ballots = append(ballots, tally.Ballot{ID: item.Name, Signature: item.Signature, Message: item.Message})
+	}
+	status, result := ballot.Count(schema, foldedPublicKeys, []byte(*ballotCountCase), ballots, policy)
+	if status != ring.Success {
+		log.Fatal(ring.ErrorMessages[status])
+	}
+	if *ballotCountFormat == "json" {
+		content, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			log.Fatal(err)
+		}
+		client.WriteOutput(*ballotCountOutput, content)
+		return
+	}
+	var buff bytes.Buffer
+	fmt.Fprintf(&buff, "Kind: %s\nPolicy: %s\nCounted ballots: %d\n", result.Kind, result.Audit.Policy, len(result.Audit.Accepted))
+	for i, round := range result.Rounds {
+		fmt.Fprintf(&buff, "Round %d:\n", i+1)
+		for j, count := range round.Counts {
+			fmt.Fprintf(&buff, "  %q: %d\n", schema.Options[j], count)
+		}
+		if round.Eliminated != "" {
+			fmt.Fprintf(&buff, "  Eliminated: %q\n", round.Eliminated)
+		}
+	}
+	if len(result.Rounds) == 0 {
+		for i, count := range result.Counts {
+			fmt.Fprintf(&buff, "  %q: %d\n", schema.Options[i], count)
+		}
+	}
+	if result.Winner == "" {
+		fmt.Fprintln(&buff, "Winner: none (tie)")
+	} else {
+		fmt.Fprintf(&buff, "Winner: %q\n", result.Winner)
+	}
+	fmt.Fprintf(&buff, "Rejected ballots: %d\n", len(result.Audit.Rejected))
+	for _, item := range result.Audit.Rejected {
+		fmt.Fprintf(&buff, "  %s: %s\n", item.ID, item.Reason)
+	}
+	fmt.Fprintf(&buff, "Invalid ballots: %d\n", len(result.Audit.Invalid))
+	for _, item := range result.Audit.Invalid {
+		fmt.Fprintf(&buff, "  %s: %s\n", item.ID, item.Reason)
+	}
+	client.WriteOutput(*ballotCountOutput, bytes.TrimRight(buff.Bytes(), "\n"))
+}
+
+// openBoard opens the bulletin board file.
+func openBoard(filename string) *board.Board {
+	if filename == "" {
+		log.Fatal("Parameter -board missing.")
+	}
+	b, err := board.Open(filename)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return b
+}
+
+// readSignedTreeHead reads the signed tree head from the file.
+func readSignedTreeHead(filename string) board.SignedTreeHead {
+	head := board.SignedTreeHead{}
+	content, err := ioutil.ReadFile(filename)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if status := client.DecodeValue(board.TreeHeadBlock, content, &head); status != ring.Success {
+		log.Fatal(ring.ErrorMessages[status])
+	}
+	return head
+}
+
+// readSignatureDER reads the signature and converts it into DER, the form stored on the board.
+func readSignatureDER(filename string) []byte {
+	status, sign := client.ParseSignature(client.ReadFromFileOrStdin(filename))
+	if status != ring.Success {
+		log.Fatal(ring.ErrorMessages[status])
+	}
+	status, signature := client.EncodeSignarureToDER(&sign)
+	if status != ring.Success {
+		log.Fatal(ring.ErrorMessages[status])
+	}
+	return signature
+}
+
+func commandBoardAppend(boardAppendCmd *flag.FlagSet, boardAppendFoldedPubs *fileList, boardAppendBoard, boardAppendSignature, boardAppendMessage, boardAppendCase *string) {
+	if err := boardAppendCmd.Parse(os.Args[2:]); err != nil {
+		log.Fatal(err)
+	}
+	foldedPublicKeys := readFoldedPublicKeys(*boardAppendFoldedPubs)
+	signature := readSignatureDER(*boardAppendSignature)
+	message := client.ReadMessage(*boardAppendMessage)
+	if status := client.VerifySignature(foldedPublicKeys, signature, message, []byte(*boardAppendCase)); status != ring.Success {
+		log.Fatal(ring.ErrorMessages[status])
+	}
+	b := openBoard(*boardAppendBoard)
+	defer b.Close()
+	index := b.Find(signature)
+	if index == -1 {
+		var err error
+		index, err = b.Append(board.Entry{Signature: signature, Message: message, Case: []byte(*boardAppendCase)})
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+	fmt.Println(index)
+}
+
+func commandBoardHead(boardHeadCmd *flag.FlagSet, boardHeadBoard, boardHeadPrivate, boardHeadFormat, boardHeadOutput *string, boardHeadSize *int) {
+	if err := boardHeadCmd.Parse(os.Args[2:]); err != nil {
+		log.Fatal(err)
+	}
+	privateKeyContent, err := ioutil.ReadFile(*boardHeadPrivate)
+	if err != nil {
+		log.Fatal(err)
+	}
+	status, privateKey := client.ParsePrivateKey(privateKeyContent)
+	if status != ring.Success {
+		log.Fatal(ring.ErrorMessages[status])
+	}
+	b := openBoard(*boardHeadBoard)
+	defer b.Close()
+	size := *boardHeadSize
+	if size < 0 {
+		size = b.Size()
+	}
+	head, err := b.Head(size, time.Now().Unix())
+	if err != nil {
+		log.Fatal(err)
+	}
+	signed, err := board.SignTreeHead(head, privateKey)
+	if err != nil {
+		log.Fatal(err)
+	}
+	status, content := client.EncodeValue(board.TreeHeadBlock, signed, *boardHeadFormat)
+	if status != ring.Success {
+		log.Fatal(ring.ErrorMessages[status])
+	}
+	client.WriteOutput(*boardHeadOutput, content)
+}
+
+func commandBoardProve(boardProveCmd *flag.FlagSet, boardProveBoard, boardProveHead, boardProveSignature, boardProveOld, boardProveFormat, boardProveOutput *string) {
+	if err := boardProveCmd.Parse(os.Args[2:]); err != nil {
+		log.Fatal(err)
+	}
+	b := openBoard(*boardProveBoard)
+	defer b.Close()
+	size := b.Size()
+	if *boardProveHead != "" {
+		size = readSignedTreeHead(*boardProveHead).Head.TreeSize
+	}
+	var status int
+	var content []byte
+	if *boardProveOld != "" {
+		proof, err := b.ConsistencyProof(readSignedTreeHead(*boardProveOld).Head.TreeSize, size)
+		if err != nil {
+			log.Fatal(err)
+		}
+		status, content = client.EncodeValue(board.ConsistencyProofBlock, proof, *boardProveFormat)
+	} else {
+		index := b.Find(readSignatureDER(*boardProveSignature))
+		if index == -1 {
+			log.Fatal("Signature is not on the board.")
+		}
+		proof, err := b.InclusionProof(index, size)
+		if err != nil {
+			log.Fatal(err)
+		}
+		status, content = client.EncodeValue(board.InclusionProofBlock, proof, *boardProveFormat)
+	}
+	if status != ring.Success {
+		log.Fatal(ring.ErrorMessages[status])
+	}
+	client.WriteOutput(*boardProveOutput, content)
+}
+
+func commandBoardAudit(
+	boardAuditCmd *flag.FlagSet,
+	boardAuditFoldedPubs *fileList,
+	boardAuditPublic, boardAuditHead, boardAuditProof, boardAuditSignature, boardAuditMessage, boardAuditCase, boardAuditOld, boardAuditBoard *string,
+) {
+	if err := boardAuditCmd.Parse(os.Args[2:]); err != nil {
+		log.Fatal(err)
+	}
+	publicKeyContent, err := ioutil.ReadFile(*boardAuditPublic)
+	if err != nil {
+		log.Fatal(err)
+	}
+	status, publicKey := client.ParsePublicKey(publicKeyContent)
+	if status != ring.Success {
+		log.Fatal(ring.ErrorMessages[status])
+	}
+	head := readSignedTreeHead(*boardAuditHead)
+	verified := board.VerifyTreeHead(head, publicKey)
+
+	switch {
+	case !verified:
+	case *boardAuditOld != "":
+		old := readSignedTreeHead(*boardAuditOld)
+		proof := board.ConsistencyProof{}
+		if status := client.DecodeValue(board.ConsistencyProofBlock, client.ReadFromFileOrStdin(*boardAuditProof), &proof); status != ring.Success {
+			log.Fatal(ring.ErrorMessages[status])
+		}
+		verified = board.VerifyTreeHead(old, publicKey) && proof.Verify(old.Head, head.Head)
+	case *boardAuditProof != "":
+		proof := board.InclusionProof{}
+		if status := client.DecodeValue(board.InclusionProofBlock, client.ReadFromFileOrStdin(*boardAuditProof), &proof); status != ring.Success {
+			log.Fatal(ring.ErrorMessages[status])
+		}
+		entry := board.Entry{
+			Signature: readSignatureDER(*boardAuditSignature),
+			Message:   client.ReadMessage(*boardAuditMessage),
+			Case:      []byte(*boardAuditCase),
+		}
+		verified = proof.Verify(entry, head.Head)
+	case *boardAuditBoard != "":
+		b := openBoard(*boardAuditBoard)
+		defer b.Close()
+		current, err := b.Head(head.Head.TreeSize, head.Head.Timestamp)
+		verified = err == nil && bytes.Equal(current.RootHash, head.Head.RootHash)
+		if verified && len(*boardAuditFoldedPubs) > 0 {
+			foldedPublicKeys := readFoldedPublicKeys(*boardAuditFoldedPubs)
+			for i, entry := range b.Entries()[:head.Head.TreeSize] {
+				if status := client.VerifySignature(foldedPublicKeys, entry.Signature, entry.Message, entry.Case); status != ring.Success {
+					fmt.Printf("Entry %d: %s\n", i, ring.ErrorMessages[status])
+					verified = false
+				}
+			}
+		}
+	}
+	if verified {
+		fmt.Println("Verified OK")
+		os.Exit(0)
+	} else {
+		fmt.Println("Verification Failure")
+		os.Exit(1)
+	}
+}
+
+func commandRPC() {
+	if err := jsonrpc.Serve(os.Stdin, os.Stdout); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func commandServe(serveCmd *flag.FlagSet, serveAddr, serveKeys *string, serveMaxSize *int64) {
+	if err := serveCmd.Parse(os.Args[2:]); err != nil {
+		log.Fatal(err)
+	}
+	server := apiserver.New(*serveKeys)
+	server.MaxRequestSize = *serveMaxSize
+	log.Println("Listening on " + *serveAddr)
+	log.Fatal(http.ListenAndServe(*serveAddr, server))
+}
+
+func commandServeBoard(serveBoardCmd *flag.FlagSet, serveBoardFoldedPubs *fileList, serveBoardCase, serveBoardAddr, serveBoardStore, serveBoardLinked *string) {
+	if err := serveBoardCmd.Parse(os.Args[2:]); err != nil {
+		log.Fatal(err)
+	}
+	var rejectLinked bool
+	switch *serveBoardLinked {
+	case "reject":
+		rejectLinked = true
+	case "flag":
+		rejectLinked = false
+	default:
+		log.Fatal("Unknown value of parameter linked: " + *serveBoardLinked)
+	}
+	var storage registry.Storage
+	if *serveBoardStore == "" {
+		storage = registry.NewMemoryStorage()
+	} else {
+		fileStorage, err := registry.OpenFileStorage(*serveBoardStore)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer fileStorage.Close()
+		storage = fileStorage
+	}
+	status, server := boardserver.New(readFoldedPublicKeys(*serveBoardFoldedPubs), []byte(*serveBoardCase), storage, rejectLinked)
+	if status != ring.Success {
+		log.Fatal(ring.ErrorMessages[status])
+	}
+	log.Println("Listening on " + *serveBoardAddr)
+	log.Fatal(http.ListenAndServe(*serveBoardAddr, server))
+}
+
+func commandAuthRespond(authRespondCmd *flag.FlagSet, authRespondFoldedPubs *fileList, authRespondChallenge, authRespondServer, authRespondPrivate, authRespondOutput *string) {
+	if err := authRespondCmd.Parse(os.Args[2:]); err != nil {
+		log.Fatal(err)
+	}
+	foldedPublicKeys := readFoldedPublicKeys(*authRespondFoldedPubs)
+	privateKey, err := ioutil.ReadFile(*authRespondPrivate)
+	if err != nil {
+		log.Fatal(err)
+	}
+	status, challenge := anonlogin.ParseChallenge(client.ReadFromFileOrStdin(*authRespondChallenge))
+	if status != ring.Success {
+		log.Fatal(ring.ErrorMessages[status])
+	}
+	status, response := anonlogin.Respond(challenge, *authRespondServer, foldedPublicKeys, privateKey)
+	if status != ring.Success {
+		log.Fatal(ring.ErrorMessages[status])
+	}
+	content, err := json.Marshal(response)
+	if err != nil {
+		log.Fatal(err)
+	}
+	client.WriteOutput(*authRespondOutput, content)
+}
+
+func commandLinkProof(linkProofCmd *flag.FlagSet, first, second linkedSignatureFlags, linkProofPrivate, linkProofFormat, linkProofOutput *string) {
+	if err := linkProofCmd.Parse(os.Args[2:]); err != nil {
+		log.Fatal(err)
+	}
+	privateKey, err := ioutil.ReadFile(*linkProofPrivate)
+	if err != nil {
+		log.Fatal(err)
+	}
+	status, proof := client.CreateSameSignerProof(privateKey, first.read(), second.read(), *linkProofFormat)
+	if status != ring.Success {
+		log.Fatal(ring.ErrorMessages[status])
+	}
+	client.WriteOutput(*linkProofOutput, proof)
+}
+
+func commandVerifyLinkProof(verifyLinkProofCmd *flag.FlagSet, first, second linkedSignatureFlags, verifyLinkProofProof *string) {
+	if err := verifyLinkProofCmd.Parse(os.Args[2:]); err != nil {
+		log.Fatal(err)
+	}
+	proof := client.ReadFromFileOrStdin(*verifyLinkProofProof)
+	status := client.VerifySameSignerProof(proof, first.read(), second.read())
+	if status == ring.Success {
+		fmt.Println("Verified OK")
+		os.Exit(0)
+	} else {
+		fmt.Println("Verification Failure")
+		os.Exit(1)
+	}
+}
+
 func commandFoldPublicKeys(pubSeqCmd *flag.FlagSet, pubSeqPubDir, pubSeqHash, pubSeqFormat, pubSeqOrder, pubSeqOutput *string) {
 	if err := pubSeqCmd.Parse(os.Args[2:]); err != nil {
 		log.Fatal(err)
@@ -305,6 +1639,39 @@ func commandFoldPublicKeys(pubSeqCmd *flag.FlagSet, pubSeqPubDir, pubSeqHash, pu
 	client.WriteOutput(*pubSeqOutput, foldedPublicKeys)
 }
 
+func commandMergePublicKeys(mergeCmd *flag.FlagSet, mergeFoldedPubs *fileList, mergeFormat, mergeOutput *string) {
+	if err := mergeCmd.Parse(os.Args[2:]); err != nil {
+		log.Fatal(err)
+	}
+	status, foldedPublicKeys := client.MergeFoldedPublicKeys(readFiles(*mergeFoldedPubs), *mergeFormat)
+	if status != ring.Success {
+		log.Fatal(ring.ErrorMessages[status])
+	}
+	client.WriteOutput(*mergeOutput, foldedPublicKeys)
+}
+
+func commandSignatureSources(sourcesCmd *flag.FlagSet, sourcesSignature, sourcesOutput *string, sourcesSeparator *bool) {
+	if err := sourcesCmd.Parse(os.Args[2:]); err != nil {
+		log.Fatal(err)
+	}
+	status, sources := client.SignatureSources(client.ReadFromFileOrStdin(*sourcesSignature), *sourcesSeparator)
+	if status != ring.Success {
+		log.Fatal(ring.ErrorMessages[status])
+	}
+	client.WriteOutput(*sourcesOutput, sources)
+}
+
+func commandFindRing(findRingCmd *flag.FlagSet, findRingSignature, findRingDir, findRingOutput *string) {
+	if err := findRingCmd.Parse(os.Args[2:]); err != nil {
+		log.Fatal(err)
+	}
+	status, filename, _ := client.FindRing(client.ReadFromFileOrStdin(*findRingSignature), *findRingDir)
+	if status != ring.Success {
+		log.Fatal(ring.ErrorMessages[status])
+	}
+	client.WriteOutput(*findRingOutput, []byte(filename))
+}
+
 func commandPublicKeysDigest(pubDgstCmd *flag.FlagSet, pubDgstFile, pubDgstOutput *string, pubDgstSeparator *bool) {
 	if err := pubDgstCmd.Parse(os.Args[2:]); err != nil {
 		log.Fatal(err)
@@ -366,22 +1733,215 @@ func main() {
 	signCmd := flag.NewFlagSet("sign", flag.ExitOnError)
 	signMessage := signCmd.String("message", "", "A text message or the name of the file to be signed.")
 	signCase := signCmd.String("case", "", "Case identifier.")
-	signFoldedPubs := signCmd.String("inpub", "", "Public keys folded into the file.")
+	signFoldedPubs := &fileList{}
+	signCmd.Var(signFoldedPubs, "inpub", "Public keys folded into the file. Repeat for the union of several rings.")
 	signPrivate := signCmd.String("inkey", "", "Filename to the private key.")
 	signOutput := signCmd.String("out", "", "Output to the file.")
 	signFormat := signCmd.String("format", "PEM", "Format of output. Can be PEM, DER. Default is PEM.")
+	signState := signCmd.String("state", "", "File of the local signing state.")
+	signForce := signCmd.Bool("force", false, "Sign again in the same ring and case.")
+	signAttached := attachedFlags{
+		attached:    signCmd.Bool("attached", false, "Output the attached signature with the message."),
+		digestOnly:  signCmd.Bool("digest-only", false, "Attach only the digest of the message."),
+		contentType: signCmd.String("content-type", client.DefaultContentType, "Content type of the attached message."),
+	}
+	signAttributes := &fileList{}
+	signCmd.Var(signAttributes, "attr", "Signed attribute key=value. Repeat for more attributes.")
 
 	verifyCmd := flag.NewFlagSet("verify", flag.ExitOnError)
 	verifySignature := verifyCmd.String("in", "", "Signature filename.")
 	verifyMessage := verifyCmd.String("message", "", "A text message or the name of the file to be verified.")
 	verifyCase := verifyCmd.String("case", "", "Case identifier.")
-	verifyFoldedPubs := verifyCmd.String("inpub", "", "Public keys folded into the file.")
+	verifyFoldedPubs := &fileList{}
+	verifyCmd.Var(verifyFoldedPubs, "inpub", "Public keys folded into the file. Repeat for the union of several rings.")
+	verifyAttached := verifyCmd.Bool("attached", false, "Verify the attached signature.")
+	verifyOutput := verifyCmd.String("out", "", "Write the attached message to the file.")
+	verifyAttributes := &fileList{}
+	verifyCmd.Var(verifyAttributes, "attr", "Expected signed attribute key=value. Repeat for more attributes.")
 
 	keyImageCmd := flag.NewFlagSet("key-image", flag.ExitOnError)
 	keyImageSignature := keyImageCmd.String("in", "", "Signature filename.")
 	keyImageSeparator := keyImageCmd.Bool("c", false, "Print the digest with separating colons.")
+	keyImageMulti := keyImageCmd.Bool("multi", false, "Signature was made by the command sign-multi.")
 	keyImageOutput := keyImageCmd.String("out", "", "Output to the file.")
 
+	signMultiCmd := flag.NewFlagSet("sign-multi", flag.ExitOnError)
+	signMultiMessage := signMultiCmd.String("message", "", "A text message or the name of the file to be signed.")
+	signMultiCase := signMultiCmd.String("case", "", "Case identifier.")
+	signMultiFoldedPubs := &fileList{}
+	signMultiCmd.Var(signMultiFoldedPubs, "inpub", "Public keys folded into the file. Repeat for each ring.")
+	signMultiPrivates := &fileList{}
+	signMultiCmd.Var(signMultiPrivates, "inkey", "Filename to the private key. Repeat for each ring.")
+	signMultiOutput := signMultiCmd.String("out", "", "Output to the file.")
+	signMultiFormat := signMultiCmd.String("format", "PEM", "Format of output. Can be PEM, DER. Default is PEM.")
+
+	verifyMultiCmd := flag.NewFlagSet("verify-multi", flag.ExitOnError)
+	verifyMultiSignature := verifyMultiCmd.String("in", "", "Signature filename.")
+	verifyMultiMessage := verifyMultiCmd.String("message", "", "A text message or the name of the file to be verified.")
+	verifyMultiCase := verifyMultiCmd.String("case", "", "Case identifier.")
+	verifyMultiFoldedPubs := &fileList{}
+	verifyMultiCmd.Var(verifyMultiFoldedPubs, "inpub", "Public keys folded into the file. Repeat for each ring.")
+
+	bundleCmd := flag.NewFlagSet("bundle", flag.ExitOnError)
+	bundleSignature := bundleCmd.String("in", "", "Signature filename.")
+	bundleMessage := bundleCmd.String("message", "", "A text message or the name of the file that was signed.")
+	bundleCase := bundleCmd.String("case", "", "Case identifier.")
+	bundleFoldedPubs := &fileList{}
+	bundleCmd.Var(bundleFoldedPubs, "inpub", "Public keys folded into the file. Repeat for the union of several rings.")
+	bundleOutput := bundleCmd.String("out", "", "Output to the file.")
+	bundleFormat := bundleCmd.String("format", "PEM", "Format of output. Can be PEM, DER. Default is PEM.")
+
+	verifyBundleCmd := flag.NewFlagSet("verify-bundle", flag.ExitOnError)
+	verifyBundleBundle := verifyBundleCmd.String("in", "", "Bundle filename.")
+	verifyBundleRingDigest := verifyBundleCmd.String("ring-digest", "", "Expected digest of the ring.")
+	verifyBundleOutput := verifyBundleCmd.String("out", "", "Write the message to the file.")
+
+	myKeyImageCmd := flag.NewFlagSet("my-key-image", flag.ExitOnError)
+	myKeyImageFoldedPubs := &fileList{}
+	myKeyImageCmd.Var(myKeyImageFoldedPubs, "inpub", "Public keys folded into the file. Repeat for the union of several rings.")
+	myKeyImagePrivate := myKeyImageCmd.String("inkey", "", "Filename to the private key.")
+	myKeyImageCase := myKeyImageCmd.String("case", "", "Case identifier.")
+	myKeyImageSeparator := myKeyImageCmd.Bool("c", false, "Print the digest with separating colons.")
+	myKeyImageOutput := myKeyImageCmd.String("out", "", "Output to the file.")
+
+	challengeCmd := flag.NewFlagSet("challenge", flag.ExitOnError)
+	challengeOutput := challengeCmd.String("out", "", "Output to the file.")
+
+	claimCmd := flag.NewFlagSet("claim", flag.ExitOnError)
+	claimSignature := claimCmd.String("in", "", "Signature filename.")
+	claimCase := claimCmd.String("case", "", "Case identifier.")
+	claimFoldedPubs := &fileList{}
+	claimCmd.Var(claimFoldedPubs, "inpub", "Public keys folded into the file. Repeat for the union of several rings.")
+	claimPrivate := claimCmd.String("inkey", "", "Filename to the private key.")
+	claimChallenge := claimCmd.String("challenge", "", "Challenge from the verifier.")
+	claimOutput := claimCmd.String("out", "", "Output to the file.")
+	claimFormat := claimCmd.String("format", "PEM", "Format of output. Can be PEM, DER. Default is PEM.")
+
+	verifyClaimCmd := flag.NewFlagSet("verify-claim", flag.ExitOnError)
+	verifyClaimSignature := verifyClaimCmd.String("in", "", "Signature filename.")
+	verifyClaimMessage := verifyClaimCmd.String("message", "", "A text message or the name of the file that was signed.")
+	verifyClaimProof := verifyClaimCmd.String("proof", "", "Proof filename.")
+	verifyClaimCase := verifyClaimCmd.String("case", "", "Case identifier.")
+	verifyClaimFoldedPubs := &fileList{}
+	verifyClaimCmd.Var(verifyClaimFoldedPubs, "inpub", "Public keys folded into the file. Repeat for the union of several rings.")
+	verifyClaimChallenge := verifyClaimCmd.String("challenge", "", "Challenge sent to the signer.")
+
+	repudiateCmd := flag.NewFlagSet("repudiate", flag.ExitOnError)
+	repudiateSignature := repudiateCmd.String("in", "", "Signature filename.")
+	repudiateCase := repudiateCmd.String("case", "", "Case identifier.")
+	repudiateFoldedPubs := &fileList{}
+	repudiateCmd.Var(repudiateFoldedPubs, "inpub", "Public keys folded into the file. Repeat for the union of several rings.")
+	repudiatePrivate := repudiateCmd.String("inkey", "", "Filename to the private key.")
+	repudiateOutput := repudiateCmd.String("out", "", "Output to the file.")
+	repudiateFormat := repudiateCmd.String("format", "PEM", "Format of output. Can be PEM, DER. Default is PEM.")
+
+	verifyRepudiationCmd := flag.NewFlagSet("verify-repudiation", flag.ExitOnError)
+	verifyRepudiationSignature := verifyRepudiationCmd.String("in", "", "Signature filename.")
+	verifyRepudiationMessage := verifyRepudiationCmd.String("message", "", "A text message or the name of the file that was signed.")
+	verifyRepudiationProof := verifyRepudiationCmd.String("proof", "", "Proof filename.")
+	verifyRepudiationCase := verifyRepudiationCmd.String("case", "", "Case identifier.")
+	verifyRepudiationFoldedPubs := &fileList{}
+	verifyRepudiationCmd.Var(verifyRepudiationFoldedPubs, "inpub", "Public keys folded into the file. Repeat for the union of several rings.")
+
+	linkCmd := flag.NewFlagSet("link", flag.ExitOnError)
+	linkFolder := linkCmd.String("in", "", "Folder with signatures.")
+	linkFormat := linkCmd.String("format", "text", "Format of output. Can be text, json. Default is text.")
+	linkSeparator := linkCmd.Bool("c", false, "Print the key image with separating colons.")
+	linkOutput := linkCmd.String("out", "", "Output to the file.")
+
+	tallyCmd := flag.NewFlagSet("tally", flag.ExitOnError)
+	tallyFolder := tallyCmd.String("in", "", "Folder with ballots.")
+	tallyFoldedPubs := &fileList{}
+	tallyCmd.Var(tallyFoldedPubs, "inpub", "Public keys folded into the file. Repeat for the union of several rings.")
+	tallyCase := tallyCmd.String("case", "", "Case identifier.")
+	tallyPolicy := tallyCmd.String("policy", "first-wins", "Policy of linked ballots. Can be first-wins, last-wins, discard-all-linked.")
+	tallyFormat := tallyCmd.String("format", "text", "Format of output. Can be text, json. Default is text.")
+	tallyOutput := tallyCmd.String("out", "", "Output to the file.")
+
+	ballotNewCmd := flag.NewFlagSet("ballot-new", flag.ExitOnError)
+	ballotNewSchema := ballotNewCmd.String("schema", "", "Filename of the ballot schema.")
+	ballotNewChoices := &fileList{}
+	ballotNewCmd.Var(ballotNewChoices, "choice", "Name of the chosen option. Repeat for more choices.")
+	ballotNewOutput := ballotNewCmd.String("out", "", "Output to the file.")
+
+	ballotCountCmd := flag.NewFlagSet("ballot-count", flag.ExitOnError)
+	ballotCountSchema := ballotCountCmd.String("schema", "", "Filename of the ballot schema.")
+	ballotCountFolder := ballotCountCmd.String("in", "", "Folder with ballots.")
+	ballotCountFoldedPubs := &fileList{}
+	ballotCountCmd.Var(ballotCountFoldedPubs, "inpub", "Public keys folded into the file. Repeat for the union of several rings.")
+	ballotCountCase := ballotCountCmd.String("case", "", "Case identifier.")
+	ballotCountPolicy := ballotCountCmd.String("policy", "first-wins", "Policy of linked ballots. Can be first-wins, last-wins, discard-all-linked.")
+	ballotCountFormat := ballotCountCmd.String("format", "text", "Format of output. Can be text, json. Default is text.")
+	ballotCountOutput := ballotCountCmd.String("out", "", "Output to the file.")
+
+	boardAppendCmd := flag.NewFlagSet("board-append", flag.ExitOnError)
+	boardAppendBoard := boardAppendCmd.String("board", "", "Filename of the board.")
+	boardAppendSignature := boardAppendCmd.String("in", "", "Signature filename.")
+	boardAppendMessage := boardAppendCmd.String("message", "", "A text message or the name of the file that was signed.")
+	boardAppendCase := boardAppendCmd.String("case", "", "Case identifier.")
+	boardAppendFoldedPubs := &fileList{}
+	boardAppendCmd.Var(boardAppendFoldedPubs, "inpub", "Public keys folded into the file. Repeat for the union of several rings.")
+
+	boardHeadCmd := flag.NewFlagSet("board-head", flag.ExitOnError)
+	boardHeadBoard := boardHeadCmd.String("board", "", "Filename of the board.")
+	boardHeadPrivate := boardHeadCmd.String("inkey", "", "Filename to the private key of the operator.")
+	boardHeadSize := boardHeadCmd.Int("size", -1, "Size of the tree. Default is the current size of the board.")
+	boardHeadFormat := boardHeadCmd.String("format", "PEM", "Format of output. Can be PEM, DER. Default is PEM.")
+	boardHeadOutput := boardHeadCmd.String("out", "", "Output to the file.")
+
+	boardProveCmd := flag.NewFlagSet("board-prove", flag.ExitOnError)
+	boardProveBoard := boardProveCmd.String("board", "", "Filename of the board.")
+	boardProveHead := boardProveCmd.String("head", "", "Filename of the signed tree head.")
+	boardProveSignature := boardProveCmd.String("in", "", "Signature filename.")
+	boardProveOld := boardProveCmd.String("old", "", "Filename of the old signed tree head.")
+	boardProveFormat := boardProveCmd.String("format", "PEM", "Format of output. Can be PEM, DER. Default is PEM.")
+	boardProveOutput := boardProveCmd.String("out", "", "Output to the file.")
+
+	boardAuditCmd := flag.NewFlagSet("board-audit", flag.ExitOnError)
+	boardAuditPublic := boardAuditCmd.String("pubkey", "", "Filename of the public key of the operator.")
+	boardAuditHead := boardAuditCmd.String("head", "", "Filename of the signed tree head.")
+	boardAuditProof := boardAuditCmd.String("proof", "", "Filename of the proof.")
+	boardAuditSignature := boardAuditCmd.String("in", "", "Signature filename.")
+	boardAuditMessage := boardAuditCmd.String("message", "", "A text message or the name of the file that was signed.")
+	boardAuditCase := boardAuditCmd.String("case", "", "Case identifier.")
+	boardAuditOld := boardAuditCmd.String("old", "", "Filename of the old signed tree head.")
+	boardAuditBoard := boardAuditCmd.String("board", "", "Filename of the board.")
+	boardAuditFoldedPubs := &fileList{}
+	boardAuditCmd.Var(boardAuditFoldedPubs, "inpub", "Public keys folded into the file. Repeat for the union of several rings.")
+
+	serveCmd := flag.NewFlagSet("serve", flag.ExitOnError)
+	serveAddr := serveCmd.String("addr", "127.0.0.1:8080", "Address to listen on.")
+	serveKeys := serveCmd.String("keys", "", "Folder with private keys.")
+	serveMaxSize := serveCmd.Int64("max-size", apiserver.DefaultMaxRequestSize, "Maximum size of the request body in bytes.")
+
+	serveBoardCmd := flag.NewFlagSet("serve-board", flag.ExitOnError)
+	serveBoardFoldedPubs := &fileList{}
+	serveBoardCmd.Var(serveBoardFoldedPubs, "inpub", "Public keys folded into the file. Repeat for the union of several rings.")
+	serveBoardCase := serveBoardCmd.String("case", "", "Case identifier.")
+	serveBoardAddr := serveBoardCmd.String("addr", "127.0.0.1:8080", "Address to listen on.")
+	serveBoardStore := serveBoardCmd.String("store", "", "Filename to store submissions in. Default is memory.")
+	serveBoardLinked := serveBoardCmd.String("linked", "reject", "Linked signatures. Can be reject, flag. Default is reject.")
+
+	authRespondCmd := flag.NewFlagSet("auth-respond", flag.ExitOnError)
+	authRespondChallenge := authRespondCmd.String("challenge", "", "Challenge filename.")
+	authRespondServer := authRespondCmd.String("server", "", "Identity of the server.")
+	authRespondFoldedPubs := &fileList{}
+	authRespondCmd.Var(authRespondFoldedPubs, "inpub", "Public keys folded into the file. Repeat for the union of several rings.")
+	authRespondPrivate := authRespondCmd.String("inkey", "", "Filename to the private key.")
+	authRespondOutput := authRespondCmd.String("out", "", "Output to the file.")
+
+	linkProofCmd := flag.NewFlagSet("link-proof", flag.ExitOnError)
+	linkProofFirst := newLinkedSignatureFlags(linkProofCmd, "1")
+	linkProofSecond := newLinkedSignatureFlags(linkProofCmd, "2")
+	linkProofPrivate := linkProofCmd.String("inkey", "", "Filename to the private key.")
+	linkProofOutput := linkProofCmd.String("out", "", "Output to the file.")
+	linkProofFormat := linkProofCmd.String("format", "PEM", "Format of output. Can be PEM, DER. Default is PEM.")
+
+	verifyLinkProofCmd := flag.NewFlagSet("verify-link-proof", flag.ExitOnError)
+	verifyLinkProofFirst := newLinkedSignatureFlags(verifyLinkProofCmd, "1")
+	verifyLinkProofSecond := newLinkedSignatureFlags(verifyLinkProofCmd, "2")
+	verifyLinkProofProof := verifyLinkProofCmd.String("proof", "", "Proof filename.")
+
 	pubSeqCmd := flag.NewFlagSet("fold-pub", flag.ExitOnError)
 	pubSeqHash := pubSeqCmd.String("hash", "sha3-256", "Hash type.")
 	pubSeqPubDir := pubSeqCmd.String("inpath", "", "Folder with public keys.")
@@ -389,6 +1949,12 @@ func main() {
 	pubSeqFormat := pubSeqCmd.String("format", "PEM", "Format of output. Can be PEM, DER. Default is PEM.")
 	pubSeqOrder := pubSeqCmd.String("order", "hashes", "Public keys order. It can be hashes or alphabetical. Default is hashes.")
 
+	mergeCmd := flag.NewFlagSet("merge-pub", flag.ExitOnError)
+	mergeFoldedPubs := &fileList{}
+	mergeCmd.Var(mergeFoldedPubs, "inpub", "Public keys folded into the file. Repeat for each ring.")
+	mergeOutput := mergeCmd.String("out", "", "Output to the file.")
+	mergeFormat := mergeCmd.String("format", "PEM", "Format of output. Can be PEM, DER. Default is PEM.")
+
 	seqPubCmd := flag.NewFlagSet("restore-pub", flag.ExitOnError)
 	seqPubFile := seqPubCmd.String("in", "", "Public keys sequence filename.")
 	seqPubDir := seqPubCmd.String("outpath", "", "Path to save public keys.")
@@ -399,6 +1965,16 @@ func main() {
 	pubDgstSeparator := pubDgstCmd.Bool("c", false, "Print the digest with separating colons.")
 	pubDgstOutput := pubDgstCmd.String("out", "", "Output to the file.")
 
+	sourcesCmd := flag.NewFlagSet("sig-sources", flag.ExitOnError)
+	sourcesSignature := sourcesCmd.String("in", "", "Signature filename.")
+	sourcesSeparator := sourcesCmd.Bool("c", false, "Print the digest with separating colons.")
+	sourcesOutput := sourcesCmd.String("out", "", "Output to the file.")
+
+	findRingCmd := flag.NewFlagSet("find-ring", flag.ExitOnError)
+	findRingSignature := findRingCmd.String("in", "", "Signature filename.")
+	findRingDir := findRingCmd.String("dir", "", "Folder with folded public keys.")
+	findRingOutput := findRingCmd.String("out", "", "Output to the file.")
+
 	pubCoordinatesCmd := flag.NewFlagSet("pub-xy", flag.ExitOnError)
 	pubCoordinatesFile := pubCoordinatesCmd.String("in", "", "Public key filename.")
 
@@ -431,20 +2007,101 @@ func main() {
 			commandVersion(versionCmd, versionOutput)
 
 		case "sign":
-			commandMakeSignature(signCmd, signFoldedPubs, signPrivate, signMessage, signCase, signFormat, signOutput)
+			commandMakeSignature(signCmd, signFoldedPubs, signPrivate, signMessage, signCase, signFormat, signOutput, signState, signForce, signAttached, signAttributes)
 
 		case "verify":
-			commandVerifySignature(verifyCmd, verifyFoldedPubs, verifySignature, verifyMessage, verifyCase)
+			commandVerifySignature(verifyCmd, verifyFoldedPubs, verifySignature, verifyMessage, verifyCase, verifyOutput, verifyAttached, verifyAttributes)
 
 		case "key-image":
-			commandKeyImage(keyImageCmd, keyImageSignature, keyImageOutput, keyImageSeparator)
+			commandKeyImage(keyImageCmd, keyImageSignature, keyImageOutput, keyImageSeparator, keyImageMulti)
+
+		case "sign-multi":
+			commandMakeMultiSignature(signMultiCmd, signMultiFoldedPubs, signMultiPrivates, signMultiMessage, signMultiCase, signMultiFormat, signMultiOutput)
+
+		case "verify-multi":
+			commandVerifyMultiSignature(verifyMultiCmd, verifyMultiFoldedPubs, verifyMultiSignature, verifyMultiMessage, verifyMultiCase)
+
+		case "bundle":
+			commandBundle(bundleCmd, bundleFoldedPubs, bundleSignature, bundleMessage, bundleCase, bundleFormat, bundleOutput)
+
+		case "verify-bundle":
+			commandVerifyBundle(verifyBundleCmd, verifyBundleBundle, verifyBundleRingDigest, verifyBundleOutput)
+
+		case "my-key-image":
+			commandMyKeyImage(myKeyImageCmd, myKeyImageFoldedPubs, myKeyImagePrivate, myKeyImageCase, myKeyImageOutput, myKeyImageSeparator)
+
+		case "challenge":
+			commandChallenge(challengeCmd, challengeOutput)
+
+		case "claim":
+			commandClaim(claimCmd, claimFoldedPubs, claimSignature, claimPrivate, claimCase, claimChallenge, claimFormat, claimOutput)
+
+		case "verify-claim":
+			commandVerifyClaim(verifyClaimCmd, verifyClaimFoldedPubs, verifyClaimSignature, verifyClaimMessage, verifyClaimProof, verifyClaimCase, verifyClaimChallenge)
+
+		case "repudiate":
+			commandRepudiate(repudiateCmd, repudiateFoldedPubs, repudiateSignature, repudiatePrivate, repudiateCase, repudiateFormat, repudiateOutput)
+
+		case "verify-repudiation":
+			commandVerifyRepudiation(verifyRepudiationCmd, verifyRepudiationFoldedPubs, verifyRepudiationSignature, verifyRepudiationMessage, verifyRepudiationProof, verifyRepudiationCase)
+
+		case "link":
+			commandLink(linkCmd, linkFolder, linkFormat, linkOutput, linkSeparator)
+
+		case "tally":
+			commandTally(tallyCmd, tallyFoldedPubs, tallyFolder, tallyCase, tallyPolicy, tallyFormat, tallyOutput)
+
+		case "ballot-new":
+			commandBallotNew(ballotNewCmd, ballotNewSchema, ballotNewChoices, ballotNewOutput)
+
+		case "ballot-count":
+			commandBallotCount(ballotCountCmd, ballotCountFoldedPubs, ballotCountSchema, ballotCountFolder, ballotCountCase, ballotCountPolicy, ballotCountFormat, ballotCountOutput)
+
+		case "board-append":
+			commandBoardAppend(boardAppendCmd, boardAppendFoldedPubs, boardAppendBoard, boardAppendSignature, boardAppendMessage, boardAppendCase)
+
+		case "board-head":
+			commandBoardHead(boardHeadCmd, boardHeadBoard, boardHeadPrivate, boardHeadFormat, boardHeadOutput, boardHeadSize)
+
+		case "board-prove":
+			commandBoardProve(boardProveCmd, boardProveBoard, boardProveHead, boardProveSignature, boardProveOld, boardProveFormat, boardProveOutput)
+
+		case "board-audit":
+			commandBoardAudit(boardAuditCmd, boardAuditFoldedPubs, boardAuditPublic, boardAuditHead, boardAuditProof, boardAuditSignature, boardAuditMessage, boardAuditCase, boardAuditOld, boardAuditBoard)
+
+		case "rpc":
+			commandRPC()
+
+		case "serve":
+			commandServe(serveCmd, serveAddr, serveKeys, serveMaxSize)
+
+		case "serve-board":
+			commandServeBoard(serveBoardCmd, serveBoardFoldedPubs, serveBoardCase, serveBoardAddr, serveBoardStore, serveBoardLinked)
+
+		case "auth-respond":
+			commandAuthRespond(authRespondCmd, authRespondFoldedPubs, authRespondChallenge, authRespondServer, authRespondPrivate, authRespondOutput)
+
+		case "link-proof":
+			commandLinkProof(linkProofCmd, linkProofFirst, linkProofSecond, linkProofPrivate, linkProofFormat, linkProofOutput)
+
+		case "verify-link-proof":
+			commandVerifyLinkProof(verifyLinkProofCmd, verifyLinkProofFirst, verifyLinkProofSecond, verifyLinkProofProof)
 
 		case "fold-pub":
 			commandFoldPublicKeys(pubSeqCmd, pubSeqPubDir, pubSeqHash, pubSeqFormat, pubSeqOrder, pubSeqOutput)
 
+		case "merge-pub":
+			commandMergePublicKeys(mergeCmd, mergeFoldedPubs, mergeFormat, mergeOutput)
+
 		case "pub-dgst":
 			commandPublicKeysDigest(pubDgstCmd, pubDgstFile, pubDgstOutput, pubDgstSeparator)
 
+		case "sig-sources":
+			commandSignatureSources(sourcesCmd, sourcesSignature, sourcesOutput, sourcesSeparator)
+
+		case "find-ring":
+			commandFindRing(findRingCmd, findRingSignature, findRingDir, findRingOutput)
+
 		case "pub-xy":
 			commandPublicKeyCoordinates(pubCoordinatesCmd, pubCoordinatesFile, pubDgstOutput)
 
@@ -0,0 +1,335 @@
+// Package evoting encrypts ballots for the election key, so running results are not revealed before the election closes.
+// Voters ring-sign encrypted ballots, the tally is computed homomorphically over non-linked ballots
+// and the trustee decrypts only the tally with the proof of correct decryption.
+package evoting
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"encoding/asn1"
+	"hash"
+	"math/big"
+	"strconv"
+
+	"github.com/zbohm/lirisi/client"
+	"github.com/zbohm/lirisi/ring"
+)
+
+// PEM block types.
+const (
+	BallotBlock     = "ENCRYPTED BALLOT"
+	TallyBlock      = "ENCRYPTED TALLY"
+	DecryptionBlock = "TALLY DECRYPTION"
+)
+
+// Ciphertext of exponential ElGamal.
+type Ciphertext struct {
+	A ring.PointData
+	B ring.PointData
+}
+
+// EncryptedOption holds encrypted vote for the option and the proof that it is 0 or 1.
+type EncryptedOption struct {
+	Ciphertext Ciphertext
+	Challenge0 []byte
+	Challenge1 []byte
+	Response0  []byte
+	Response1  []byte
+}
+
+// EncryptedBallot holds encrypted votes for all options and the proof that exactly one option is chosen.
+// Proofs are bound to the election key, the case and the key image of the voter, so a ballot copied
+// and signed by another voter is rejected.
+type EncryptedBallot struct {
+	Name        string
+	Version     int
+	CurveOID    asn1.ObjectIdentifier
+	HasherOID   asn1.ObjectIdentifier
+	ElectionKey ring.PointData
+	Case        []byte
+	KeyImage    ring.PointData
+	Options     []EncryptedOption
+	SumChecksum []byte
+	SumResponse []byte
+}
+
+// EncryptedTally holds product of ciphertexts of counted ballots for each option.
+type EncryptedTally struct {
+	Name            string
+	Version         int
+	CurveOID        asn1.ObjectIdentifier
+	HasherOID       asn1.ObjectIdentifier
+	ElectionKey     ring.PointData
+	NumberOfBallots int
+	Options         []Ciphertext
+}
+
+// DecryptedOption holds decryption share of the option with its proof and the count.
+type DecryptedOption struct {
+	Share    ring.PointData
+	Checksum []byte
+	Response []byte
+	Count    int
+}
+
+// Decryption holds the tally decrypted by the trustee.
+type Decryption struct {
+	Name    string
+	Version int
+	Tally   EncryptedTally
+	Options []DecryptedOption
+}
+
+// ballotBinding holds data of the ballot the proofs of the ballot are bound to.
+type ballotBinding struct {
+	ElectionKey ring.PointData
+	Case        []byte
+	KeyImage    ring.PointData
+}
+
+// ballotContext returns context of the proofs of the ballot.
+func ballotContext(ballot *EncryptedBallot) (int, []byte) {
+	content, err := asn1.Marshal(ballotBinding{ElectionKey: ballot.ElectionKey, Case: ballot.Case, KeyImage: ballot.KeyImage})
+	if err != nil {
+		return ring.Asn1MarshalFailed, nil
+	}
+	return ring.Success, content
+}
+
+func optionContext(context []byte, index int) []byte {
+	return append(append([]byte{}, context...), "option "+strconv.Itoa(index)...)
+}
+
+func decryptionContext(index int) []byte {
+	return []byte("decryption " + strconv.Itoa(index))
+}
+
+func sumContext(context []byte) []byte {
+	return append(append([]byte{}, context...), "sum"...)
+}
+
+// electionFromKey creates election context from the public key of the trustee.
+func electionFromKey(publicKey *ecdsa.PublicKey, hasher func() hash.Hash) (int, election, asn1.ObjectIdentifier) {
+	curveOID, status := ring.GetCurveOIDForCurve(publicKey.Curve)
+	if status != ring.Success {
+		return status, election{}, curveOID
+	}
+	curve, _ := ring.GetCurve(curveOID)
+	if !ring.CurveHashSupportedCombination(curve, hasher) {
+		return ring.UnsupportedCurveHashCombination, election{}, curveOID
+	}
+	fc := ring.FactoryContext{Curve: publicKey.Curve, Hasher: hasher}
+	return ring.Success, newElection(fc, ring.NewPoint(publicKey.X, publicKey.Y)), curveOID
+}
+
+// electionFromData creates election context from the serialized election key.
+func electionFromData(curveOID, hasherOID asn1.ObjectIdentifier, electionKey ring.PointData) (int, election) {
+	status, fc := ring.GetFactoryContext(curveOID, hasherOID)
+	if status != ring.Success {
+		return status, election{}
+	}
+	x, y := electionKey.Point().Coordinates()
+	if !fc.Curve.IsOnCurve(x, y) {
+		return ring.InvalidPointCoordinates, election{}
+	}
+	return ring.Success, newElection(fc, electionKey.Point())
+}
+
+// EncryptBallot encrypts the choice (counted from zero) for the election key of the trustee.
+// The ballot is encrypted with the hash function of the ring and bound to the case and to the key image
+// of the voter, who signs it in the ring.
+func EncryptBallot(foldedPublicKeys, privateKey, electionKey, caseIdentifier []byte, numberOfOptions, choice int, outFormat string) (int, []byte) {
+	status, _, foldedKeys := client.UnfoldPublicKeysContent(foldedPublicKeys)
+	if status != ring.Success {
+		return status, []byte{}
+	}
+	hasher, ok := ring.GetHasher(foldedKeys.HasherOID)
+	if !ok {
+		return ring.UnexpectedHashType, []byte{}
+	}
+	status, keyImage, _ := client.KeyImageFor(foldedPublicKeys, privateKey, caseIdentifier)
+	if status != ring.Success {
+		return status, []byte{}
+	}
+	status, ballot := encryptBallot(electionKey, hasher, caseIdentifier, keyImage, numberOfOptions, choice)
+	if status != ring.Success {
+		return status, []byte{}
+	}
+	return client.EncodeValue(BallotBlock, *ballot, outFormat)
+}
+
+func encryptBallot(
+	electionKey []byte,
+	hasher func() hash.Hash,
+	caseIdentifier []byte,
+	keyImage ring.PointData,
+	numberOfOptions, choice int,
+) (int, *EncryptedBallot) {
+	if numberOfOptions < 2 {
+		return ring.IncorrectNumberOfOptions, nil
+	}
+	if choice < 0 || choice >= numberOfOptions {
+		return ring.ChoiceOutOfRange, nil
+	}
+	status, publicKey := client.ParsePublicKey(electionKey)
+	if status != ring.Success {
+		return status, nil
+	}
+	status, e, curveOID := electionFromKey(publicKey, hasher)
+	if status != ring.Success {
+		return status, nil
+	}
+	hasherOID, status := ring.GetHasherOID(hasher)
+	if status != ring.Success {
+		return status, nil
+	}
+
+	ballot := EncryptedBallot{
+		Name:        ring.Origin + " Encrypted ballot",
+		Version:     ring.SignatureVersion,
+		CurveOID:    curveOID,
+		HasherOID:   hasherOID,
+		ElectionKey: e.y.PointData(),
+		Case:        caseIdentifier,
+		KeyImage:    keyImage,
+		Options:     make([]EncryptedOption, numberOfOptions),
+	}
+	randomness := make([]*big.Int, numberOfOptions)
+	for i := range randomness {
+		randomness[i] = e.random()
+	}
+	if status := proveBallot(e, &ballot, choice, randomness); status != ring.Success {
+		return status, nil
+	}
+	return ring.Success, &ballot
+}
+
+// proveBallot encrypts options of the ballot with the randomness and adds proofs bound to the ballot.
+func proveBallot(e election, ballot *EncryptedBallot, choice int, randomness []*big.Int) int {
+	status, context := ballotContext(ballot)
+	if status != ring.Success {
+		return status
+	}
+	sumR := new(big.Int)
+	sumA, sumB := ring.Point{}, ring.Point{}
+	for i, r := range randomness {
+		a, b := e.encryptBit(i == choice, r)
+		challenges, responses := e.proveBit(a, b, i == choice, r, optionContext(context, i))
+		ballot.Options[i] = EncryptedOption{
+			Ciphertext: Ciphertext{A: a.PointData(), B: b.PointData()},
+			Challenge0: challenges[0].Bytes(),
+			Challenge1: challenges[1].Bytes(),
+			Response0:  responses[0].Bytes(),
+			Response1:  responses[1].Bytes(),
+		}
+		sumR.Add(sumR, r)
+		if i == 0 {
+			sumA, sumB = a, b
+		} else {
+			sumA, sumB = e.fc.PointAdd(sumA, a), e.fc.PointAdd(sumB, b)
+		}
+	}
+	sumR.Mod(sumR, e.q)
+	ballot.SumChecksum, ballot.SumResponse = e.fc.ProveEqualDiscreteLogs(e.g, sumA, e.y, e.shifted(sumB, 1), sumR, sumContext(context))
+	return ring.Success
+}
+
+// ParseBallot parses encrypted ballot in format PEM or DER.
+func ParseBallot(content []byte) (int, EncryptedBallot) {
+	ballot := EncryptedBallot{}
+	return client.DecodeValue(BallotBlock, content, &ballot), ballot
+}
+
+// verifyBallot verifies proofs of the ballot for the election key and the case.
+func verifyBallot(
+	ballot *EncryptedBallot,
+	curveOID, hasherOID asn1.ObjectIdentifier,
+	electionKey ring.PointData,
+	caseIdentifier []byte,
+	numberOfOptions int,
+) int {
+	if !ballot.CurveOID.Equal(curveOID) {
+		return ring.UnexpectedCurveType
+	}
+	if !ballot.HasherOID.Equal(hasherOID) {
+		return ring.UnexpectedHashType
+	}
+	if !bytes.Equal(ballot.ElectionKey.Bytes(), electionKey.Bytes()) {
+		return ring.ElectionKeyMismatch
+	}
+	if !bytes.Equal(ballot.Case, caseIdentifier) {
+		return ring.BallotBindingMismatch
+	}
+	if len(ballot.Options) != numberOfOptions {
+		return ring.IncorrectNumberOfOptions
+	}
+	status, e := electionFromData(ballot.CurveOID, ballot.HasherOID, ballot.ElectionKey)
+	if status != ring.Success {
+		return status
+	}
+	status, context := ballotContext(ballot)
+	if status != ring.Success {
+		return status
+	}
+	sumA, sumB := ring.Point{}, ring.Point{}
+	for i, option := range ballot.Options {
+		a, b := option.Ciphertext.A.Point(), option.Ciphertext.B.Point()
+		if !e.fc.Curve.IsOnCurve(a.Coordinates()) || !e.fc.Curve.IsOnCurve(b.Coordinates()) {
+			return ring.InvalidPointCoordinates
+		}
+		challenges := [2]*big.Int{ring.BuffToInt(option.Challenge0), ring.BuffToInt(option.Challenge1)}
+		responses := [2]*big.Int{ring.BuffToInt(option.Response0), ring.BuffToInt(option.Response1)}
+		if !e.verifyBit(a, b, challenges, responses, optionContext(context, i)) {
+			return ring.InvalidProof
+		}
+		if i == 0 {
+			sumA, sumB = a, b
+		} else {
+			sumA, sumB = e.fc.PointAdd(sumA, a), e.fc.PointAdd(sumB, b)
+		}
+	}
+	if !e.fc.VerifyEqualDiscreteLogs(e.g, sumA, e.y, e.shifted(sumB, 1), ballot.SumChecksum, ballot.SumResponse, sumContext(context)) {
+		return ring.InvalidProof
+	}
+	return ring.Success
+}
+
+// VerifyBallot verifies that the ballot was encrypted for the election key and the case and exactly one option is chosen.
+// The key image the ballot is bound to is checked against the signature of the ballot by CountBallots.
+func VerifyBallot(content, electionKey, caseIdentifier []byte, hashName string, numberOfOptions int) int {
+	hasher, ok := ring.HashCodes[hashName]
+	if !ok {
+		return ring.UnexpectedHashType
+	}
+	status, ballot := ParseBallot(content)
+	if status != ring.Success {
+		return status
+	}
+	status, publicKey := client.ParsePublicKey(electionKey)
+	if status != ring.Success {
+		return status
+	}
+	status, e, curveOID := electionFromKey(publicKey, hasher)
+	if status != ring.Success {
+		return status
+	}
+	hasherOID, status := ring.GetHasherOID(hasher)
+	if status != ring.Success {
+		return status
+	}
+	return verifyBallot(&ballot, curveOID, hasherOID, e.y.PointData(), caseIdentifier, numberOfOptions)
+}
+
+// CastBallot encrypts the choice and signs the encrypted ballot in the ring.
+// The ballot is encrypted with the hash function of the ring. Returns encrypted ballot and signature.
+func CastBallot(foldedPublicKeys, privateKey, electionKey, caseIdentifier []byte, numberOfOptions, choice int, outFormat string) (int, []byte, []byte) {
+	status, content := EncryptBallot(foldedPublicKeys, privateKey, electionKey, caseIdentifier, numberOfOptions, choice, outFormat)
+	if status != ring.Success {
+		return status, []byte{}, []byte{}
+	}
+	status, signature := client.CreateSignature(foldedPublicKeys, privateKey, content, caseIdentifier, outFormat)
+	if status != ring.Success {
+		return status, []byte{}, []byte{}
+	}
+	return ring.Success, content, signature
+}
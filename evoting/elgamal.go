@@ -0,0 +1,140 @@
+// # Exponential ElGamal encryption of votes.
+
+// The trustee holds the election private key *x* and publishes *Y = g<sup>x</sup>*.
+// The vote *m ∈ {0, 1}* of one option is encrypted as *(A, B) = (g<sup>r</sup>, g<sup>m</sup> Y<sup>r</sup>)*.
+// Ciphertexts are additively homomorphic: the product of ciphertexts encrypts the sum of votes.
+//
+// The voter proves that each ciphertext encrypts 0 or 1 by the disjunction of two proofs
+// *log<sub>g</sub>(A) = log<sub>Y</sub>(B / g<sup>j</sup>)* for *j ∈ {0, 1}*, where the branch
+// the voter does not know is simulated. The product of all ciphertexts of the ballot
+// encrypts 1, so exactly one option is chosen.
+//
+// The trustee decrypts the tally *(A, B)* by the share *D = A<sup>x</sup>* and proves
+// *log<sub>g</sub>(Y) = log<sub>A</sub>(D)*. The count *m* is found from *g<sup>m</sup> = B / D*.
+
+package evoting
+
+import (
+	"bytes"
+	"crypto/rand"
+	"math/big"
+
+	"github.com/zbohm/lirisi/ring"
+)
+
+// election holds context of the election key.
+type election struct {
+	fc ring.FactoryContext
+	q  *big.Int
+	g  ring.Point
+	y  ring.Point
+}
+
+func newElection(fc ring.FactoryContext, electionKey ring.Point) election {
+	return election{fc: fc, q: fc.Curve.Params().N, g: fc.Generator(), y: electionKey}
+}
+
+// random returns random scalar.
+func (e election) random() *big.Int {
+	value, err := rand.Int(rand.Reader, e.q)
+	if err != nil {
+		panic(err)
+	}
+	return value
+}
+
+// challenge returns hash of the points and context reduced into scalar.
+func (e election) challenge(points []ring.Point, context []byte) *big.Int {
+	buff := ring.PointsToBytes(points)
+	buff = append(buff, context...)
+	return new(big.Int).Mod(ring.BuffToInt(e.fc.MakeDigest(buff)), e.q)
+}
+
+// sub returns a - b mod q.
+func (e election) sub(a, b *big.Int) *big.Int {
+	return new(big.Int).Mod(new(big.Int).Sub(a, b), e.q)
+}
+
+// commit returns g^s h^c.
+func (e election) commit(g, h ring.Point, s, c *big.Int) ring.Point {
+	return e.fc.PointAdd(e.fc.PointScalarMult(g, s.Bytes()), e.fc.PointScalarMult(h, c.Bytes()))
+}
+
+// encryptBit encrypts the vote by the randomness r.
+func (e election) encryptBit(vote bool, r *big.Int) (ring.Point, ring.Point) {
+	a := e.fc.PointScalarMult(e.g, r.Bytes())
+	b := e.fc.PointScalarMult(e.y, r.Bytes())
+	if vote {
+		b = e.fc.PointAdd(b, e.g)
+	}
+	return a, b
+}
+
+// shifted returns B / g^j.
+func (e election) shifted(b ring.Point, j int) ring.Point {
+	if j == 0 {
+		return b
+	}
+	return e.fc.PointAdd(b, e.fc.PointNeg(e.g))
+}
+
+// proveBit creates the disjunctive proof that (a, b) encrypts the vote 0 or 1.
+func (e election) proveBit(a, b ring.Point, vote bool, r *big.Int, context []byte) ([2]*big.Int, [2]*big.Int) {
+	var challenges, responses [2]*big.Int
+	var commits [4]ring.Point
+
+	known := 0
+	if vote {
+		known = 1
+	}
+	simulated := 1 - known
+
+	challenges[simulated] = e.random()
+	responses[simulated] = e.random()
+	commits[2*simulated] = e.commit(e.g, a, responses[simulated], challenges[simulated])
+	commits[2*simulated+1] = e.commit(e.y, e.shifted(b, simulated), responses[simulated], challenges[simulated])
+
+	w := e.random()
+	commits[2*known] = e.fc.PointScalarMult(e.g, w.Bytes())
+	commits[2*known+1] = e.fc.PointScalarMult(e.y, w.Bytes())
+
+	c := e.challenge(append([]ring.Point{e.g, e.y, a, b}, commits[:]...), context)
+	challenges[known] = e.sub(c, challenges[simulated])
+	responses[known] = e.sub(w, new(big.Int).Mul(r, challenges[known]))
+	return challenges, responses
+}
+
+// verifyBit verifies the disjunctive proof that (a, b) encrypts the vote 0 or 1.
+func (e election) verifyBit(a, b ring.Point, challenges, responses [2]*big.Int, context []byte) bool {
+	var commits [4]ring.Point
+	for j := 0; j < 2; j++ {
+		commits[2*j] = e.commit(e.g, a, responses[j], challenges[j])
+		commits[2*j+1] = e.commit(e.y, e.shifted(b, j), responses[j], challenges[j])
+	}
+	c := e.challenge(append([]ring.Point{e.g, e.y, a, b}, commits[:]...), context)
+	sum := new(big.Int).Mod(new(big.Int).Add(challenges[0], challenges[1]), e.q)
+	return sum.Cmp(c) == 0
+}
+
+// proveDecryption returns share D = A^x and the proof log_g(Y) = log_A(D).
+func (e election) proveDecryption(a ring.Point, x *big.Int, context []byte) (ring.Point, []byte, []byte) {
+	share := e.fc.PointScalarMult(a, x.Bytes())
+	c, s := e.fc.ProveEqualDiscreteLogs(e.g, e.y, a, share, x, context)
+	return share, c, s
+}
+
+// findCount returns m such that g^m = B / D for m from 0 to max.
+func (e election) findCount(b, share ring.Point, max int) (int, bool) {
+	target := e.fc.PointAdd(b, e.fc.PointNeg(share))
+	if target.IsInfinity() {
+		return 0, true
+	}
+	point := e.g
+	for m := 1; m <= max; m++ {
+		if bytes.Equal(point.Bytes(), target.Bytes()) {
+			return m, true
+		}
+		point = e.fc.PointAdd(point, e.g)
+	}
+	return 0, false
+}
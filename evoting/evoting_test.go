@@ -0,0 +1,242 @@
+package evoting
+
+import (
+	"math/big"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/zbohm/lirisi/client"
+	"github.com/zbohm/lirisi/internal/testring"
+	"github.com/zbohm/lirisi/ring"
+	"github.com/zbohm/lirisi/tally"
+)
+
+var caseIdentifier = []byte("election-2026")
+
+// createTrustee returns private key of the trustee and the public election key.
+func createTrustee(t *testing.T) ([]byte, []byte) {
+	privateKeys, _ := testring.Create(t, 1)
+	status, publicKey := client.DerivePublicKey(privateKeys[0], "PEM")
+	if status != ring.Success {
+		t.Fatal(ring.ErrorMessages[status])
+	}
+	return privateKeys[0], publicKey
+}
+
+func createElection(t *testing.T, curveName, hashName string, size int) ([][]byte, []byte, []byte, []byte) {
+	privateKeys, folded := testring.CreateWith(t, size, curveName, hashName)
+	trusteePrivate, trusteePublic := createTrustee(t)
+	return privateKeys, folded, trusteePrivate, trusteePublic
+}
+
+func castBallot(t *testing.T, id string, folded, privateKey, electionKey []byte, choice int) tally.Ballot {
+	status, ballot, signature := CastBallot(folded, privateKey, electionKey, caseIdentifier, 3, choice, "PEM")
+	if status != ring.Success {
+		t.Fatal(ring.ErrorMessages[status])
+	}
+	return tally.Ballot{ID: id, Signature: signature, Message: ballot}
+}
+
+func TestElection(t *testing.T) {
+	for curveName := range ring.CurveCodes {
+		privateKeys, folded, trusteePrivate, electionKey := createElection(t, curveName, "sha3-256", 4)
+		ballots := []tally.Ballot{
+			castBallot(t, "first", folded, privateKeys[0], electionKey, 2),
+			castBallot(t, "second", folded, privateKeys[1], electionKey, 0),
+			castBallot(t, "third", folded, privateKeys[2], electionKey, 2),
+			castBallot(t, "revote", folded, privateKeys[0], electionKey, 1),
+		}
+
+		status, encrypted, result := CountBallots(folded, electionKey, caseIdentifier, 3, ballots, tally.LastWins, "PEM")
+		if status != ring.Success {
+			t.Fatalf("%s: %s", curveName, ring.ErrorMessages[status])
+		}
+		if !reflect.DeepEqual(result.Accepted, []string{"second", "third", "revote"}) {
+			t.Errorf("%s: unexpected accepted ballots %v.", curveName, result.Accepted)
+		}
+		status, decryption := DecryptTally(trusteePrivate, encrypted, "PEM")
+		if status != ring.Success {
+			t.Fatalf("%s: %s", curveName, ring.ErrorMessages[status])
+		}
+		status, counts := VerifyDecryption(decryption, encrypted)
+		if status != ring.Success {
+			t.Fatalf("%s: %s", curveName, ring.ErrorMessages[status])
+		}
+		if !reflect.DeepEqual(counts, []int{1, 1, 1}) {
+			t.Errorf("%s: unexpected counts %v.", curveName, counts)
+		}
+	}
+}
+
+func TestEmptyElection(t *testing.T) {
+	_, folded, trusteePrivate, electionKey := createElection(t, "prime256v1", "sha3-256", 2)
+	status, encrypted, _ := CountBallots(folded, electionKey, caseIdentifier, 2, []tally.Ballot{}, tally.FirstWins, "DER")
+	if status != ring.Success {
+		t.Fatal(ring.ErrorMessages[status])
+	}
+	status, decryption := DecryptTally(trusteePrivate, encrypted, "DER")
+	if status != ring.Success {
+		t.Fatal(ring.ErrorMessages[status])
+	}
+	status, counts := VerifyDecryption(decryption, encrypted)
+	if status != ring.Success {
+		t.Fatal(ring.ErrorMessages[status])
+	}
+	if !reflect.DeepEqual(counts, []int{0, 0}) {
+		t.Errorf("Unexpected counts %v.", counts)
+	}
+}
+
+func TestVerifyBallot(t *testing.T) {
+	privateKeys, folded, _, electionKey := createElection(t, "prime256v1", "sha3-256", 2)
+	_, otherKey := createTrustee(t)
+	status, ballot := EncryptBallot(folded, privateKeys[0], electionKey, caseIdentifier, 3, 1, "PEM")
+	if status != ring.Success {
+		t.Fatal(ring.ErrorMessages[status])
+	}
+	if status := VerifyBallot(ballot, electionKey, caseIdentifier, "sha3-256", 3); status != ring.Success {
+		t.Errorf("Valid ballot: %s", ring.ErrorMessages[status])
+	}
+	if status := VerifyBallot(ballot, otherKey, caseIdentifier, "sha3-256", 3); status != ring.ElectionKeyMismatch {
+		t.Errorf("Other election key: %s", ring.ErrorMessages[status])
+	}
+	if status := VerifyBallot(ballot, electionKey, []byte("other"), "sha3-256", 3); status != ring.BallotBindingMismatch {
+		t.Errorf("Other case: %s", ring.ErrorMessages[status])
+	}
+	if status := VerifyBallot(ballot, electionKey, caseIdentifier, "sha3-256", 2); status != ring.IncorrectNumberOfOptions {
+		t.Errorf("Other number of options: %s", ring.ErrorMessages[status])
+	}
+	if status, _ := EncryptBallot(folded, privateKeys[0], electionKey, caseIdentifier, 3, 3, "PEM"); status != ring.ChoiceOutOfRange {
+		t.Errorf("Choice out of range: %s", ring.ErrorMessages[status])
+	}
+}
+
+func TestInvalidBallots(t *testing.T) {
+	privateKeys, folded, _, electionKey := createElection(t, "prime256v1", "sha3-256", 3)
+
+	// Ballot voting for two options has valid proofs of options, but not of the sum.
+	status, publicKey := client.ParsePublicKey(electionKey)
+	if status != ring.Success {
+		t.Fatal(ring.ErrorMessages[status])
+	}
+	hasher := ring.HashCodes["sha3-256"]
+	status, e, _ := electionFromKey(publicKey, hasher)
+	if status != ring.Success {
+		t.Fatal(ring.ErrorMessages[status])
+	}
+	status, keyImage, _ := client.KeyImageFor(folded, privateKeys[0], caseIdentifier)
+	if status != ring.Success {
+		t.Fatal(ring.ErrorMessages[status])
+	}
+	status, ballot := encryptBallot(electionKey, hasher, caseIdentifier, keyImage, 3, 0)
+	if status != ring.Success {
+		t.Fatal(ring.ErrorMessages[status])
+	}
+	_, context := ballotContext(ballot)
+	r := e.random()
+	a, b := e.encryptBit(true, r)
+	challenges, responses := e.proveBit(a, b, true, r, optionContext(context, 1))
+	ballot.Options[1] = EncryptedOption{
+		Ciphertext: Ciphertext{A: a.PointData(), B: b.PointData()},
+		Challenge0: challenges[0].Bytes(),
+		Challenge1: challenges[1].Bytes(),
+		Response0:  responses[0].Bytes(),
+		Response1:  responses[1].Bytes(),
+	}
+	status, content := client.EncodeValue(BallotBlock, *ballot, "PEM")
+	if status != ring.Success {
+		t.Fatal(ring.ErrorMessages[status])
+	}
+	status, signature := client.CreateSignature(folded, privateKeys[0], content, caseIdentifier, "PEM")
+	if status != ring.Success {
+		t.Fatal(ring.ErrorMessages[status])
+	}
+	ballots := []tally.Ballot{
+		{ID: "double", Signature: signature, Message: content},
+		castBallot(t, "valid", folded, privateKeys[0], electionKey, 1),
+	}
+	status, _, result := CountBallots(folded, electionKey, caseIdentifier, 3, ballots, tally.FirstWins, "PEM")
+	if status != ring.Success {
+		t.Fatal(ring.ErrorMessages[status])
+	}
+	if !reflect.DeepEqual(result.Accepted, []string{"valid"}) {
+		t.Errorf("Unexpected accepted ballots %v.", result.Accepted)
+	}
+	if len(result.Invalid) != 1 || result.Invalid[0].Reason != ring.ErrorMessages[ring.InvalidProof] {
+		t.Errorf("Unexpected invalid ballots %v.", result.Invalid)
+	}
+}
+
+func TestCopiedBallot(t *testing.T) {
+	privateKeys, folded, _, electionKey := createElection(t, "prime256v1", "sha3-256", 3)
+	original := castBallot(t, "original", folded, privateKeys[0], electionKey, 2)
+
+	// Another voter signs the ballot of the first voter.
+	status, signature := client.CreateSignature(folded, privateKeys[1], original.Message, caseIdentifier, "PEM")
+	if status != ring.Success {
+		t.Fatal(ring.ErrorMessages[status])
+	}
+	copied := tally.Ballot{ID: "copied", Signature: signature, Message: original.Message}
+
+	for _, ballots := range [][]tally.Ballot{{original, copied}, {copied, original}} {
+		status, _, result := CountBallots(folded, electionKey, caseIdentifier, 3, ballots, tally.FirstWins, "PEM")
+		if status != ring.Success {
+			t.Fatal(ring.ErrorMessages[status])
+		}
+		if !reflect.DeepEqual(result.Accepted, []string{"original"}) {
+			t.Errorf("Unexpected accepted ballots %v.", result.Accepted)
+		}
+		if len(result.Invalid) != 1 || result.Invalid[0].Reason != ring.ErrorMessages[ring.BallotBindingMismatch] {
+			t.Errorf("Unexpected invalid ballots %v.", result.Invalid)
+		}
+	}
+}
+
+func TestDuplicateCiphertext(t *testing.T) {
+	privateKeys, folded, _, electionKey := createElection(t, "prime256v1", "sha3-256", 3)
+	status, publicKey := client.ParsePublicKey(electionKey)
+	if status != ring.Success {
+		t.Fatal(ring.ErrorMessages[status])
+	}
+	hasher := ring.HashCodes["sha3-256"]
+	status, e, _ := electionFromKey(publicKey, hasher)
+	if status != ring.Success {
+		t.Fatal(ring.ErrorMessages[status])
+	}
+	// Two voters share the randomness, so their ballots have the same ciphertexts with valid proofs.
+	randomness := []*big.Int{e.random(), e.random(), e.random()}
+	ballots := make([]tally.Ballot, 2)
+	for i := range ballots {
+		status, keyImage, _ := client.KeyImageFor(folded, privateKeys[i], caseIdentifier)
+		if status != ring.Success {
+			t.Fatal(ring.ErrorMessages[status])
+		}
+		status, ballot := encryptBallot(electionKey, hasher, caseIdentifier, keyImage, 3, 1)
+		if status != ring.Success {
+			t.Fatal(ring.ErrorMessages[status])
+		}
+		if status := proveBallot(e, ballot, 1, randomness); status != ring.Success {
+			t.Fatal(ring.ErrorMessages[status])
+		}
+		status, content := client.EncodeValue(BallotBlock, *ballot, "PEM")
+		if status != ring.Success {
+			t.Fatal(ring.ErrorMessages[status])
+		}
+		status, signature := client.CreateSignature(folded, privateKeys[i], content, caseIdentifier, "PEM")
+		if status != ring.Success {
+			t.Fatal(ring.ErrorMessages[status])
+		}
+		ballots[i] = tally.Ballot{ID: strconv.Itoa(i), Signature: signature, Message: content}
+	}
+	status, _, result := CountBallots(folded, electionKey, caseIdentifier, 3, ballots, tally.FirstWins, "PEM")
+	if status != ring.Success {
+		t.Fatal(ring.ErrorMessages[status])
+	}
+	if !reflect.DeepEqual(result.Accepted, []string{"0"}) {
+		t.Errorf("Unexpected accepted ballots %v.", result.Accepted)
+	}
+	if len(result.Invalid) != 1 || result.Invalid[0].Reason != ring.ErrorMessages[ring.DuplicateCiphertext] {
+		t.Errorf("Unexpected invalid ballots %v.", result.Invalid)
+	}
+}
@@ -0,0 +1,212 @@
+package evoting
+
+import (
+	"bytes"
+	"encoding/asn1"
+	"encoding/hex"
+	"math/big"
+
+	"github.com/zbohm/lirisi/client"
+	"github.com/zbohm/lirisi/ring"
+	"github.com/zbohm/lirisi/tally"
+)
+
+// CountBallots verifies signatures and proofs of encrypted ballots and multiplies ciphertexts of the counted ballots.
+// The message of each ballot is the encrypted ballot. Linked ballots are resolved by the policy.
+// Returns the encrypted tally and the audit of rejected and invalid ballots. The result has no counts,
+// they are known after decryption.
+func CountBallots(
+	foldedPublicKeys, electionKey, caseIdentifier []byte,
+	numberOfOptions int,
+	ballots []tally.Ballot,
+	policy tally.Policy,
+	outFormat string,
+) (int, []byte, tally.Result) {
+
+	status, _, foldedKeys := client.UnfoldPublicKeysContent(foldedPublicKeys)
+	if status != ring.Success {
+		return status, []byte{}, tally.Result{}
+	}
+	hasher, ok := ring.GetHasher(foldedKeys.HasherOID)
+	if !ok {
+		return ring.UnexpectedHashType, []byte{}, tally.Result{}
+	}
+	status, publicKey := client.ParsePublicKey(electionKey)
+	if status != ring.Success {
+		return status, []byte{}, tally.Result{}
+	}
+	status, e, curveOID := electionFromKey(publicKey, hasher)
+	if status != ring.Success {
+		return status, []byte{}, tally.Result{}
+	}
+
+	// Ballots with invalid proofs are excluded before linked ballots are resolved,
+	// so they do not displace a valid ballot of the same member.
+	// A ballot is bound to the key image of its signature and its ciphertexts must not repeat
+	// ciphertexts of another voter, so copied ballots are not counted.
+	invalid := []tally.Rejection{}
+	valid := []tally.Ballot{}
+	parsed := map[string]EncryptedBallot{}
+	voters := map[string]string{}
+	for _, item := range ballots {
+		status, ballot := ParseBallot(item.Message)
+		if status == ring.Success {
+			status = verifyBallot(&ballot, curveOID, foldedKeys.HasherOID, e.y.PointData(), caseIdentifier, numberOfOptions)
+		}
+		if status == ring.Success {
+			status = checkBallotVoter(&ballot, item.Signature, voters)
+		}
+		if status != ring.Success {
+			invalid = append(invalid, tally.Rejection{ID: item.ID, Reason: ring.ErrorMessages[status]})
+			continue
+		}
+		parsed[item.ID] = ballot
+		valid = append(valid, item)
+	}
+	status, result := tally.Count(foldedPublicKeys, caseIdentifier, valid, policy)
+	if status != ring.Success {
+		return status, []byte{}, result
+	}
+	result.Counts = []tally.MessageCount{}
+	result.Invalid = append(invalid, result.Invalid...)
+
+	encrypted := EncryptedTally{
+		Name:            ring.Origin + " Encrypted tally",
+		Version:         ring.SignatureVersion,
+		CurveOID:        curveOID,
+		HasherOID:       foldedKeys.HasherOID,
+		ElectionKey:     e.y.PointData(),
+		NumberOfBallots: len(result.Accepted),
+		Options:         make([]Ciphertext, numberOfOptions),
+	}
+	for i := range encrypted.Options {
+		// Encryption of zero by zero randomness, i.e. both points at infinity.
+		a, b := ring.NewPoint(big.NewInt(0), big.NewInt(0)), ring.NewPoint(big.NewInt(0), big.NewInt(0))
+		for _, id := range result.Accepted {
+			option := parsed[id].Options[i].Ciphertext
+			a = e.fc.PointAdd(a, option.A.Point())
+			b = e.fc.PointAdd(b, option.B.Point())
+		}
+		encrypted.Options[i] = Ciphertext{A: a.PointData(), B: b.PointData()}
+	}
+	status, content := client.EncodeValue(TallyBlock, encrypted, outFormat)
+	return status, content, result
+}
+
+// checkBallotVoter checks that the ballot is bound to the key image of its signature and that its ciphertexts
+// were not cast by another voter. Voters maps the ciphertexts to the key images of the checked ballots.
+func checkBallotVoter(ballot *EncryptedBallot, signature []byte, voters map[string]string) int {
+	status, sign := client.ParseSignature(signature)
+	if status != ring.Success {
+		return status
+	}
+	keyImage := pointKey(sign.KeyImage)
+	if keyImage != pointKey(ballot.KeyImage) {
+		return ring.BallotBindingMismatch
+	}
+	for _, option := range ballot.Options {
+		if voter, ok := voters[pointKey(option.Ciphertext.A)]; ok && voter != keyImage {
+			return ring.DuplicateCiphertext
+		}
+	}
+	for _, option := range ballot.Options {
+		voters[pointKey(option.Ciphertext.A)] = keyImage
+	}
+	return ring.Success
+}
+
+// pointKey returns the point as a key of the map.
+func pointKey(point ring.PointData) string {
+	return hex.EncodeToString(point.X) + ":" + hex.EncodeToString(point.Y)
+}
+
+// ParseTally parses encrypted tally in format PEM or DER.
+func ParseTally(content []byte) (int, EncryptedTally) {
+	encrypted := EncryptedTally{}
+	return client.DecodeValue(TallyBlock, content, &encrypted), encrypted
+}
+
+// DecryptTally decrypts the tally by the election private key of the trustee and proves correct decryption.
+func DecryptTally(privateKeyContent, encryptedTally []byte, outFormat string) (int, []byte) {
+	status, encrypted := ParseTally(encryptedTally)
+	if status != ring.Success {
+		return status, []byte{}
+	}
+	status, privateKey := client.ParsePrivateKey(privateKeyContent)
+	if status != ring.Success {
+		return status, []byte{}
+	}
+	status, e := electionFromData(encrypted.CurveOID, encrypted.HasherOID, encrypted.ElectionKey)
+	if status != ring.Success {
+		return status, []byte{}
+	}
+	if !bytes.Equal(ring.NewPoint(privateKey.X, privateKey.Y).Bytes(), e.y.Bytes()) {
+		return ring.ElectionKeyMismatch, []byte{}
+	}
+
+	decryption := Decryption{
+		Name:    ring.Origin + " Tally decryption",
+		Version: ring.SignatureVersion,
+		Tally:   encrypted,
+		Options: make([]DecryptedOption, len(encrypted.Options)),
+	}
+	for i, option := range encrypted.Options {
+		share, c, s := e.proveDecryption(option.A.Point(), privateKey.D, decryptionContext(i))
+		count, found := e.findCount(option.B.Point(), share, encrypted.NumberOfBallots)
+		if !found {
+			return ring.DecryptionFailure, []byte{}
+		}
+		decryption.Options[i] = DecryptedOption{Share: share.PointData(), Checksum: c, Response: s, Count: count}
+	}
+	return client.EncodeValue(DecryptionBlock, decryption, outFormat)
+}
+
+// ParseDecryption parses decrypted tally in format PEM or DER.
+func ParseDecryption(content []byte) (int, Decryption) {
+	decryption := Decryption{}
+	return client.DecodeValue(DecryptionBlock, content, &decryption), decryption
+}
+
+// VerifyDecryption verifies that the trustee decrypted the encrypted tally correctly. Returns counts of options.
+func VerifyDecryption(decryptionContent, encryptedTally []byte) (int, []int) {
+	counts := []int{}
+	status, decryption := ParseDecryption(decryptionContent)
+	if status != ring.Success {
+		return status, counts
+	}
+	status, encrypted := ParseTally(encryptedTally)
+	if status != ring.Success {
+		return status, counts
+	}
+	expected, err := asn1.Marshal(encrypted)
+	if err != nil {
+		return ring.Asn1MarshalFailed, counts
+	}
+	actual, err := asn1.Marshal(decryption.Tally)
+	if err != nil {
+		return ring.Asn1MarshalFailed, counts
+	}
+	if !bytes.Equal(expected, actual) {
+		return ring.TallyMismatch, counts
+	}
+	if len(decryption.Options) != len(encrypted.Options) {
+		return ring.IncorrectNumberOfOptions, counts
+	}
+	status, e := electionFromData(encrypted.CurveOID, encrypted.HasherOID, encrypted.ElectionKey)
+	if status != ring.Success {
+		return status, counts
+	}
+	for i, option := range decryption.Options {
+		a, b := encrypted.Options[i].A.Point(), encrypted.Options[i].B.Point()
+		share := option.Share.Point()
+		if !e.fc.VerifyEqualDiscreteLogs(e.g, e.y, a, share, option.Checksum, option.Response, decryptionContext(i)) {
+			return ring.InvalidProof, counts
+		}
+		count, found := e.findCount(b, share, encrypted.NumberOfBallots)
+		if !found || count != option.Count {
+			return ring.DecryptionFailure, counts
+		}
+		counts = append(counts, count)
+	}
+	return ring.Success, counts
+}
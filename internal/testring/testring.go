@@ -0,0 +1,40 @@
+// Package testring provides rings of members for tests of the packages built on the client.
+package testring
+
+import (
+	"testing"
+
+	"github.com/zbohm/lirisi/client"
+	"github.com/zbohm/lirisi/ring"
+)
+
+// Create generates private keys of the members and folds their public keys into the ring.
+// Keys are on the curve prime256v1, the ring uses sha3-256 and all contents are in PEM.
+func Create(t testing.TB, size int) ([][]byte, []byte) {
+	t.Helper()
+	return CreateWith(t, size, "prime256v1", "sha3-256")
+}
+
+// CreateWith generates the ring like Create on the curve and with the hash function of the names.
+func CreateWith(t testing.TB, size int, curveName, hashName string) ([][]byte, []byte) {
+	t.Helper()
+	privateKeys := make([][]byte, size)
+	publicKeys := make([][]byte, size)
+	for i := 0; i < size; i++ {
+		status, privateKey := client.GeneratePrivateKey(curveName, "PEM")
+		if status != ring.Success {
+			t.Fatal(ring.ErrorMessages[status])
+		}
+		status, publicKey := client.DerivePublicKey(privateKey, "PEM")
+		if status != ring.Success {
+			t.Fatal(ring.ErrorMessages[status])
+		}
+		privateKeys[i] = privateKey
+		publicKeys[i] = publicKey
+	}
+	status, folded := client.FoldPublicKeys(publicKeys, hashName, "PEM", "hashes")
+	if status != ring.Success {
+		t.Fatal(ring.ErrorMessages[status])
+	}
+	return privateKeys, folded
+}
@@ -0,0 +1,322 @@
+// Package jsonrpc serves the functions of the shared library (lib/lirisilib.go) as JSON-RPC 2.0
+// over newline-delimited JSON, one request per line. Other languages can run lirisi as a long-lived subprocess
+// instead of loading the shared library.
+//
+// Methods have the names of the library functions and take named parameters. Binary values are base64.
+// Failure of the function is returned as the error with the code and message from ring.ErrorMessages:
+//
+//	{"jsonrpc": "2.0", "id": 1, "method": "GeneratePrivateKey", "params": {"curve_name": "prime256v1", "format": "PEM"}}
+//	{"jsonrpc": "2.0", "id": 1, "result": {"content": "LS0tLS1CRUdJTi..."}}
+//	{"jsonrpc": "2.0", "id": 2, "error": {"code": 4, "message": "Unexpected curve type."}}
+package jsonrpc
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+
+	"github.com/zbohm/lirisi/client"
+	"github.com/zbohm/lirisi/ring"
+)
+
+// Version of the JSON-RPC protocol.
+const Version = "2.0"
+
+// Error codes of the protocol. Failures of the functions have the status codes of package ring.
+const (
+	ParseError     = -32700
+	InvalidRequest = -32600
+	MethodNotFound = -32601
+	InvalidParams  = -32602
+	InternalError  = -32603
+)
+
+// Request of the method.
+type Request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// Error of the response.
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// Response to the request.
+type Response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+// ContentResult is the result of functions returning content.
+type ContentResult struct {
+	Content []byte `json:"content"`
+}
+
+// ContentsResult is the result of functions returning list of contents.
+type ContentsResult struct {
+	Contents [][]byte `json:"contents"`
+}
+
+// VerifyResult is the result of VerifySignature. Invalid signature is not an error.
+type VerifyResult struct {
+	Valid   bool   `json:"valid"`
+	Status  int    `json:"status"`
+	Message string `json:"message"`
+}
+
+// DetailsResult is the result of VerifySignatureDetails.
+type DetailsResult struct {
+	VerifyResult
+	KeyImage   string `json:"key_image"`
+	CurveName  string `json:"curve_name"`
+	HashName   string `json:"hash_name"`
+	RingSize   int    `json:"ring_size"`
+	RingDigest string `json:"ring_digest"`
+}
+
+type signatureKeyImageParams struct {
+	Content   []byte `json:"content"`
+	Separator bool   `json:"separator"`
+}
+
+type foldPublicKeysParams struct {
+	PublicKeys [][]byte `json:"public_keys"`
+	HashName   string   `json:"hash_name"`
+	OutFormat  string   `json:"out_format"`
+	Order      string   `json:"order"`
+}
+
+type createSignatureParams struct {
+	FoldedPublicKeys []byte `json:"folded_public_keys"`
+	PrivateKey       []byte `json:"private_key"`
+	Message          []byte `json:"message"`
+	CaseIdentifier   []byte `json:"case_identifier"`
+	OutFormat        string `json:"out_format"`
+}
+
+type verifySignatureParams struct {
+	FoldedPublicKeys []byte `json:"folded_public_keys"`
+	Signature        []byte `json:"signature"`
+	Message          []byte `json:"message"`
+	CaseIdentifier   []byte `json:"case_identifier"`
+}
+
+type verifySignatureDetailsParams struct {
+	FoldedPublicKeys []byte `json:"folded_public_keys"`
+	Signature        []byte `json:"signature"`
+	Message          []byte `json:"message"`
+	CaseIdentifier   []byte `json:"case_identifier"`
+	Separator        bool   `json:"separator"`
+}
+
+type unfoldPublicKeysParams struct {
+	FoldedPublicKeys []byte `json:"folded_public_keys"`
+	OutFormat        string `json:"out_format"`
+}
+
+type publicKeysDigestParams struct {
+	FoldedPublicKeys []byte `json:"folded_public_keys"`
+	Separator        bool   `json:"separator"`
+}
+
+type publicKeyXYCoordinatesParams struct {
+	PublicKey []byte `json:"public_key"`
+}
+
+type generatePrivateKeyParams struct {
+	CurveName string `json:"curve_name"`
+	Format    string `json:"format"`
+}
+
+type derivePublicKeyParams struct {
+	PrivateKey []byte `json:"private_key"`
+	Format     string `json:"format"`
+}
+
+// method decodes parameters into the value and calls the function.
+type method struct {
+	params func() interface{}
+	call   func(params interface{}) (int, interface{})
+}
+
+func content(status int, content []byte) (int, interface{}) {
+	return status, ContentResult{Content: content}
+}
+
+var methods = map[string]method{
+	"SignatureKeyImage": {
+		func() interface{} { return &signatureKeyImageParams{} },
+		func(params interface{}) (int, interface{}) {
+			p := params.(*signatureKeyImageParams)
+			return content(client.SignatureKeyImage(p.Content, p.Separator))
+		},
+	},
+	"FoldPublicKeys": {
+		func() interface{} {
+			return &foldPublicKeysParams{HashName: "sha3-256", OutFormat: "PEM", Order: "hashes"}
+		},
+		func(params interface{}) (int, interface{}) {
+			p := params.(*foldPublicKeysParams)
+			return content(client.FoldPublicKeys(p.PublicKeys, p.HashName, p.OutFormat, p.Order))
+		},
+	},
+	"CreateSignature": {
+		func() interface{} { return &createSignatureParams{OutFormat: "PEM"} },
+		func(params interface{}) (int, interface{}) {
+			p := params.(*createSignatureParams)
+			return content(client.CreateSignature(p.FoldedPublicKeys, p.PrivateKey, p.Message, p.CaseIdentifier, p.OutFormat))
+		},
+	},
+	"VerifySignature": {
+		func() interface{} { return &verifySignatureParams{} },
+		func(params interface{}) (int, interface{}) {
+			p := params.(*verifySignatureParams)
+			status := client.VerifySignature(p.FoldedPublicKeys, p.Signature, p.Message, p.CaseIdentifier)
+			return ring.Success, VerifyResult{Valid: status == ring.Success, Status: status, Message: ring.ErrorMessages[status]}
+		},
+	},
+	"VerifySignatureDetails": {
+		func() interface{} { return &verifySignatureDetailsParams{} },
+		func(params interface{}) (int, interface{}) {
+			p := params.(*verifySignatureDetailsParams)
+			details := client.VerifySignatureDetails(p.FoldedPublicKeys, p.Signature, p.Message, p.CaseIdentifier, p.Separator)
+			return ring.Success, DetailsResult{
+				VerifyResult: VerifyResult{Valid: details.Status == ring.Success, Status: details.Status, Message: ring.ErrorMessages[details.Status]},
+				KeyImage:     details.KeyImage,
+				CurveName:    details.CurveName,
+				HashName:     details.HashName,
+				RingSize:     details.RingSize,
+				RingDigest:   details.RingDigest,
+			}
+		},
+	},
+	"UnfoldPublicKeys": {
+		func() interface{} { return &unfoldPublicKeysParams{OutFormat: "PEM"} },
+		func(params interface{}) (int, interface{}) {
+			p := params.(*unfoldPublicKeysParams)
+			status, contents := client.UnfoldPublicKeysIntoBytes(p.FoldedPublicKeys, p.OutFormat)
+			return status, ContentsResult{Contents: contents}
+		},
+	},
+	"PublicKeysDigest": {
+		func() interface{} { return &publicKeysDigestParams{} },
+		func(params interface{}) (int, interface{}) {
+			p := params.(*publicKeysDigestParams)
+			return content(client.PublicKeysDigest(p.FoldedPublicKeys, p.Separator))
+		},
+	},
+	"PublicKeyXYCoordinates": {
+		func() interface{} { return &publicKeyXYCoordinatesParams{} },
+		func(params interface{}) (int, interface{}) {
+			p := params.(*publicKeyXYCoordinatesParams)
+			return content(client.PublicKeyXYCoordinates(p.PublicKey))
+		},
+	},
+	"GeneratePrivateKey": {
+		func() interface{} { return &generatePrivateKeyParams{CurveName: "prime256v1", Format: "PEM"} },
+		func(params interface{}) (int, interface{}) {
+			p := params.(*generatePrivateKeyParams)
+			return content(client.GeneratePrivateKey(p.CurveName, p.Format))
+		},
+	},
+	"DerivePublicKey": {
+		func() interface{} { return &derivePublicKeyParams{Format: "PEM"} },
+		func(params interface{}) (int, interface{}) {
+			p := params.(*derivePublicKeyParams)
+			return content(client.DerivePublicKey(p.PrivateKey, p.Format))
+		},
+	},
+}
+
+// Methods returns names of the methods.
+func Methods() []string {
+	names := make([]string, 0, len(methods))
+	for name := range methods {
+		names = append(names, name)
+	}
+	return names
+}
+
+func failure(id json.RawMessage, code int, message string) *Response {
+	if id == nil {
+		id = json.RawMessage("null")
+	}
+	return &Response{JSONRPC: Version, ID: id, Error: &Error{Code: code, Message: message}}
+}
+
+// Handle processes one request. It returns nil for notification (request without id).
+func Handle(line []byte) *Response {
+	request := Request{}
+	if err := json.Unmarshal(line, &request); err != nil {
+		return failure(nil, ParseError, "Parse error: "+err.Error())
+	}
+	if request.JSONRPC != Version || request.Method == "" {
+		return failure(request.ID, InvalidRequest, "Invalid request.")
+	}
+	response := handle(&request)
+	if request.ID == nil {
+		return nil
+	}
+	return response
+}
+
+// handle calls the method. Panic of the function is returned as InternalError without its details,
+// so the server keeps running.
+func handle(request *Request) (response *Response) {
+	defer func() {
+		if err := recover(); err != nil {
+			response = failure(request.ID, InternalError, "Internal error.")
+		}
+	}()
+	m, ok := methods[request.Method]
+	if !ok {
+		return failure(request.ID, MethodNotFound, "Method not found: "+request.Method)
+	}
+	params := m.params()
+	if len(request.Params) > 0 && !bytes.Equal(request.Params, []byte("null")) {
+		decoder := json.NewDecoder(bytes.NewReader(request.Params))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(params); err != nil {
+			return failure(request.ID, InvalidParams, "Invalid params: "+err.Error())
+		}
+	}
+	status, result := m.call(params)
+	if status != ring.Success {
+		return failure(request.ID, status, ring.ErrorMessages[status])
+	}
+	return &Response{JSONRPC: Version, ID: request.ID, Result: result}
+}
+
+// Serve reads requests from the input, one per line, and writes responses to the output, one per line,
+// until the end of the input.
+func Serve(in io.Reader, out io.Writer) error {
+	reader := bufio.NewReader(in)
+	writer := bufio.NewWriter(out)
+	encoder := json.NewEncoder(writer)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			if response := Handle(line); response != nil {
+				if err := encoder.Encode(response); err != nil {
+					return err
+				}
+				if err := writer.Flush(); err != nil {
+					return err
+				}
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
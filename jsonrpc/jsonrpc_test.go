@@ -0,0 +1,192 @@
+package jsonrpc
+
+import (
+	"bytes"
+	"crypto/elliptic"
+	"encoding/asn1"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/zbohm/lirisi/ring"
+	"golang.org/x/crypto/sha3"
+)
+
+type response struct {
+	ID     int             `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *Error          `json:"error"`
+}
+
+// session sends requests to the server and returns responses by id.
+func session(t *testing.T, requests ...string) map[int]response {
+	out := &bytes.Buffer{}
+	if err := Serve(strings.NewReader(strings.Join(requests, "\n")), out); err != nil {
+		t.Fatal(err)
+	}
+	responses := map[int]response{}
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		r := response{}
+		if err := json.Unmarshal([]byte(line), &r); err != nil {
+			t.Fatal(err)
+		}
+		responses[r.ID] = r
+	}
+	return responses
+}
+
+func request(t *testing.T, id int, method string, params interface{}) string {
+	content, err := json.Marshal(map[string]interface{}{"jsonrpc": Version, "id": id, "method": method, "params": params})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(content)
+}
+
+func call(t *testing.T, method string, params, result interface{}) {
+	r := session(t, request(t, 1, method, params))[1]
+	if r.Error != nil {
+		t.Fatalf("%s failed: %s", method, r.Error.Message)
+	}
+	if err := json.Unmarshal(r.Result, result); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSignAndVerify(t *testing.T) {
+	privateKeys := make([][]byte, 3)
+	publicKeys := make([][]byte, 3)
+	for i := range privateKeys {
+		key, pub := ContentResult{}, ContentResult{}
+		call(t, "GeneratePrivateKey", map[string]string{"curve_name": "secp384r1"}, &key)
+		call(t, "DerivePublicKey", map[string][]byte{"private_key": key.Content}, &pub)
+		privateKeys[i], publicKeys[i] = key.Content, pub.Content
+	}
+	folded := ContentResult{}
+	call(t, "FoldPublicKeys", map[string]interface{}{"public_keys": publicKeys, "hash_name": "sha3-384"}, &folded)
+
+	unfolded := ContentsResult{}
+	call(t, "UnfoldPublicKeys", map[string]interface{}{"folded_public_keys": folded.Content, "out_format": "DER"}, &unfolded)
+	if len(unfolded.Contents) != 3 {
+		t.Errorf("Unexpected number of public keys %d.", len(unfolded.Contents))
+	}
+	digest, coordinates := ContentResult{}, ContentResult{}
+	call(t, "PublicKeysDigest", map[string]interface{}{"folded_public_keys": folded.Content, "separator": true}, &digest)
+	call(t, "PublicKeyXYCoordinates", map[string][]byte{"public_key": publicKeys[0]}, &coordinates)
+	if len(digest.Content) == 0 || len(coordinates.Content) == 0 {
+		t.Errorf("Missing digest or coordinates.")
+	}
+
+	signature := ContentResult{}
+	call(t, "CreateSignature", map[string]interface{}{
+		"folded_public_keys": folded.Content, "private_key": privateKeys[2], "message": []byte("Hello"), "case_identifier": []byte("case"),
+	}, &signature)
+	verified := VerifyResult{}
+	call(t, "VerifySignature", map[string]interface{}{
+		"folded_public_keys": folded.Content, "signature": signature.Content, "message": []byte("Hello"), "case_identifier": []byte("case"),
+	}, &verified)
+	if !verified.Valid || verified.Status != ring.Success {
+		t.Errorf("Signature is not valid: %+v", verified)
+	}
+	call(t, "VerifySignature", map[string]interface{}{
+		"folded_public_keys": folded.Content, "signature": signature.Content, "message": []byte("Hello"),
+	}, &verified)
+	if verified.Valid || verified.Message != ring.ErrorMessages[verified.Status] {
+		t.Errorf("Signature of another case is valid: %+v", verified)
+	}
+	keyImage := ContentResult{}
+	call(t, "SignatureKeyImage", map[string]interface{}{"content": signature.Content}, &keyImage)
+	if len(keyImage.Content) == 0 {
+		t.Errorf("Missing key image.")
+	}
+	details := DetailsResult{}
+	call(t, "VerifySignatureDetails", map[string]interface{}{
+		"folded_public_keys": folded.Content, "signature": signature.Content, "message": []byte("Hello"), "case_identifier": []byte("case"),
+	}, &details)
+	if !details.Valid || details.KeyImage != string(keyImage.Content) || details.CurveName != "secp384r1" ||
+		details.HashName != "sha3-384" || details.RingSize != 3 || details.RingDigest != strings.Replace(string(digest.Content), ":", "", -1) {
+		t.Errorf("Unexpected details %+v.", details)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	responses := session(t,
+		request(t, 1, "GeneratePrivateKey", map[string]string{"curve_name": "unknown"}),
+		request(t, 2, "Unknown", nil),
+		request(t, 3, "DerivePublicKey", map[string]string{"key": "unknown param"}),
+		`{"jsonrpc": "2.0", "method": "GeneratePrivateKey"}`,
+		`{"jsonrpc": "1.0", "id": 5, "method": "GeneratePrivateKey"}`,
+		``,
+		`{"jsonrpc": "2.0", "id": 7, "method": "GeneratePrivateKey"}`,
+		`not json`,
+	)
+	expected := map[int]int{1: ring.UnexpectedCurveType, 2: MethodNotFound, 3: InvalidParams, 5: InvalidRequest, 0: ParseError}
+	for id, code := range expected {
+		r, ok := responses[id]
+		if !ok || r.Error == nil || r.Error.Code != code {
+			t.Errorf("Unexpected response %d: %+v", id, r)
+		}
+	}
+	if responses[1].Error.Message != ring.ErrorMessages[ring.UnexpectedCurveType] {
+		t.Errorf("Unexpected message %s.", responses[1].Error.Message)
+	}
+	if r := responses[7]; r.Error != nil || len(r.Result) == 0 {
+		t.Errorf("Request with default params failed: %+v", r)
+	}
+	if len(responses) != 6 {
+		t.Errorf("Unexpected number of responses %d, notification must not be answered.", len(responses))
+	}
+}
+
+func TestMalformedParams(t *testing.T) {
+	curveOID, _ := ring.GetCurveOID(elliptic.P256)
+	hasherOID, _ := ring.GetHasherOID(sha3.New256)
+	folded, err := asn1.Marshal(ring.FoldedPublicKeys{
+		Name:      ring.Origin + " Folded public keys",
+		CurveOID:  curveOID,
+		HasherOID: hasherOID,
+		Digest:    []byte{0},
+		Keys:      [][]byte{{}, {}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	methods["Panic"] = method{
+		func() interface{} { return &derivePublicKeyParams{} },
+		func(params interface{}) (int, interface{}) { panic("malformed") },
+	}
+	defer delete(methods, "Panic")
+
+	responses := session(t,
+		request(t, 1, "CreateSignature", map[string]interface{}{"folded_public_keys": folded, "message": []byte("message")}),
+		request(t, 2, "Panic", nil),
+		request(t, 3, "GeneratePrivateKey", nil),
+	)
+	if r := responses[1]; r.Error == nil || r.Error.Code != ring.NilPointCoordinates {
+		t.Errorf("Unexpected response to empty key %+v.", r)
+	}
+	if r := responses[2]; r.Error == nil || r.Error.Code != InternalError || r.Error.Message != "Internal error." {
+		t.Errorf("Unexpected response to panic %+v.", r)
+	}
+	if r := responses[3]; r.Error != nil || len(r.Result) == 0 {
+		t.Errorf("Server did not survive the panic: %+v", r)
+	}
+}
+
+func TestMethods(t *testing.T) {
+	exported := []string{
+		"SignatureKeyImage", "FoldPublicKeys", "CreateSignature", "VerifySignature", "UnfoldPublicKeys",
+		"PublicKeysDigest", "PublicKeyXYCoordinates", "GeneratePrivateKey", "DerivePublicKey", "VerifySignatureDetails",
+	}
+	if len(Methods()) != len(exported) {
+		t.Errorf("Unexpected number of methods %d.", len(Methods()))
+	}
+	for _, name := range exported {
+		if _, ok := methods[name]; !ok {
+			t.Errorf("Missing method %s.", name)
+		}
+	}
+}
@@ -1,91 +1,383 @@
 package main
 
+/*
+#include <stdint.h>
+#include <stdlib.h>
+#include <string.h>
+
+// # Lirisi C API
+//
+// Every function returns status code, 0 on success. The message of the status is returned by LirisiErrorMessage.
+// Inputs are passed as pointer and length. The pointer may be NULL if the length is 0.
+// Inputs longer than 2 GB are rejected. Arrays of inputs and their sizes must not be NULL if the count is not 0.
+// Names (curve, hash, format, order) are NUL-terminated UTF-8 strings.
+//
+// Ownership rules:
+// - The library does not keep any pointer passed by the caller after the call returns.
+// - Output buffer (uint8_t **out, size_t *outLen) is allocated by the library and owned by the caller.
+//   It is terminated by an extra NUL byte not counted in outLen, so text outputs can be used as C strings.
+//   Release it by LirisiFree.
+// - Output list (LirisiList **out) is an opaque handle owned by the caller. Read it by LirisiListSize
+//   and LirisiListItem. Items are owned by the list. Release the list by LirisiFree.
+// - Outputs are set only on success. On failure they are left untouched and nothing has to be released.
+// - Output verification (LirisiVerification **out) is owned by the caller together with its strings.
+//   Release it by LirisiFree.
+// - Strings returned by LirisiErrorMessage are static and must not be released.
+
+// LirisiList is an opaque list of byte buffers.
+typedef struct LirisiList {
+	size_t count;
+	uint8_t **items;
+	size_t *sizes;
+} LirisiList;
+
+// LirisiVerification is the result of LirisiVerifySignatureDetails. Strings are NUL-terminated,
+// empty if the signature or folded public keys could not be parsed.
+typedef struct LirisiVerification {
+	int status;         // Status of the verification, 0 if the signature is valid.
+	char *keyImage;     // Key image of the signature in hex.
+	char *curveName;    // Curve name of the signature.
+	char *hashName;     // Hash name of the signature.
+	size_t ringSize;    // Number of public keys of the ring.
+	char *ringDigest;   // Digest of the ring in hex.
+} LirisiVerification;
+*/
+import "C"
+
 import (
-	"C"
-	"encoding/binary"
+	"math"
+	"sync"
 	"unsafe"
 
 	"github.com/zbohm/lirisi/client"
+	"github.com/zbohm/lirisi/ring"
 )
 
 // Compile this library for calling functions from other languages:
 // go build -o wrappers/lirisilib.so -buildmode=c-shared lib/lirisilib.go
+// The header wrappers/lirisilib.h is generated with it.
+
+var (
+	lists         = map[unsafe.Pointer]bool{}
+	verifications = map[unsafe.Pointer]bool{}
+	errorMessages = map[int]*C.char{}
+	mutex         sync.Mutex
+)
+
+// maxInputSize is the largest input copied by C.GoBytes, its length is C.int.
+const maxInputSize = math.MaxInt32
+
+// maxArrayCount is the largest count of items of the array of inputs.
+const maxArrayCount = 1 << 28
+
+// tooLarge reports whether some of the input sizes cannot be copied.
+func tooLarge(inputSizes ...C.size_t) bool {
+	for _, size := range inputSizes {
+		if size > maxInputSize {
+			return true
+		}
+	}
+	return false
+}
+
+// goBytes copies the input of the caller. The size is checked by tooLarge.
+func goBytes(data *C.uint8_t, size C.size_t) []byte {
+	if data == nil || size == 0 {
+		return []byte{}
+	}
+	return C.GoBytes(unsafe.Pointer(data), C.int(size))
+}
+
+// pointers returns C array of pointers as slice.
+func pointers(items **C.uint8_t, count int) []*C.uint8_t {
+	if items == nil || count == 0 {
+		return nil
+	}
+	return (*[maxArrayCount]*C.uint8_t)(unsafe.Pointer(items))[:count:count]
+}
+
+// sizes returns C array of sizes as slice.
+func sizes(items *C.size_t, count int) []C.size_t {
+	if items == nil || count == 0 {
+		return nil
+	}
+	return (*[maxArrayCount]C.size_t)(unsafe.Pointer(items))[:count:count]
+}
+
+// goBytesArray copies the array of inputs of the caller. The arrays and their count are checked by checkArray.
+func goBytesArray(items **C.uint8_t, itemSizes *C.size_t, count C.size_t) [][]byte {
+	data, lengths := pointers(items, int(count)), sizes(itemSizes, int(count))
+	contents := make([][]byte, len(data))
+	for i := range contents {
+		contents[i] = goBytes(data[i], lengths[i])
+	}
+	return contents
+}
+
+// checkArray checks the arrays of inputs and their sizes before they are read.
+func checkArray(items **C.uint8_t, itemSizes *C.size_t, count C.size_t) int {
+	if count > maxArrayCount {
+		return ring.InputTooLarge
+	}
+	if count > 0 && (items == nil || itemSizes == nil) {
+		return ring.NullInput
+	}
+	if tooLarge(sizes(itemSizes, int(count))...) {
+		return ring.InputTooLarge
+	}
+	return ring.Success
+}
+
+// cBuffer copies the content into the buffer allocated for the caller, terminated by NUL.
+func cBuffer(content []byte) (*C.uint8_t, C.size_t) {
+	buff := (*C.uint8_t)(C.malloc(C.size_t(len(content) + 1)))
+	if len(content) > 0 {
+		C.memcpy(unsafe.Pointer(buff), unsafe.Pointer(&content[0]), C.size_t(len(content)))
+	}
+	*(*C.uint8_t)(unsafe.Pointer(uintptr(unsafe.Pointer(buff)) + uintptr(len(content)))) = 0
+	return buff, C.size_t(len(content))
+}
+
+// setBuffer sets output buffer on success.
+func setBuffer(status int, content []byte, out **C.uint8_t, outLen *C.size_t) C.int {
+	if status == ring.Success {
+		*out, *outLen = cBuffer(content)
+	}
+	return C.int(status)
+}
+
+// newList allocates the list for the caller and registers it for LirisiFree.
+func newList(contents [][]byte) *C.LirisiList {
+	list := (*C.LirisiList)(C.calloc(1, C.size_t(unsafe.Sizeof(C.LirisiList{}))))
+	count := len(contents)
+	list.count = C.size_t(count)
+	if count > 0 {
+		list.items = (**C.uint8_t)(C.calloc(C.size_t(count), C.size_t(unsafe.Sizeof(uintptr(0)))))
+		list.sizes = (*C.size_t)(C.calloc(C.size_t(count), C.size_t(unsafe.Sizeof(C.size_t(0)))))
+		items, lengths := pointers(list.items, count), sizes(list.sizes, count)
+		for i, content := range contents {
+			items[i], lengths[i] = cBuffer(content)
+		}
+	}
+	mutex.Lock()
+	lists[unsafe.Pointer(list)] = true
+	mutex.Unlock()
+	return list
+}
+
+// newVerification allocates the verification for the caller and registers it for LirisiFree.
+func newVerification(details client.SignatureDetails) *C.LirisiVerification {
+	verification := (*C.LirisiVerification)(C.calloc(1, C.size_t(unsafe.Sizeof(C.LirisiVerification{}))))
+	verification.status = C.int(details.Status)
+	verification.keyImage = C.CString(details.KeyImage)
+	verification.curveName = C.CString(details.CurveName)
+	verification.hashName = C.CString(details.HashName)
+	verification.ringSize = C.size_t(details.RingSize)
+	verification.ringDigest = C.CString(details.RingDigest)
+	mutex.Lock()
+	verifications[unsafe.Pointer(verification)] = true
+	mutex.Unlock()
+	return verification
+}
+
+// LirisiFree releases the buffer, list or verification returned by the library. NULL is ignored.
+//export LirisiFree
+func LirisiFree(pointer unsafe.Pointer) {
+	if pointer == nil {
+		return
+	}
+	mutex.Lock()
+	isList, isVerification := lists[pointer], verifications[pointer]
+	delete(lists, pointer)
+	delete(verifications, pointer)
+	mutex.Unlock()
+	if isList {
+		list := (*C.LirisiList)(pointer)
+		for _, item := range pointers(list.items, int(list.count)) {
+			C.free(unsafe.Pointer(item))
+		}
+		C.free(unsafe.Pointer(list.items))
+		C.free(unsafe.Pointer(list.sizes))
+	}
+	if isVerification {
+		verification := (*C.LirisiVerification)(pointer)
+		C.free(unsafe.Pointer(verification.keyImage))
+		C.free(unsafe.Pointer(verification.curveName))
+		C.free(unsafe.Pointer(verification.hashName))
+		C.free(unsafe.Pointer(verification.ringDigest))
+	}
+	C.free(pointer)
+}
+
+// LirisiErrorMessage returns static message of the status code.
+//export LirisiErrorMessage
+func LirisiErrorMessage(status C.int) *C.char {
+	mutex.Lock()
+	defer mutex.Unlock()
+	message, ok := errorMessages[int(status)]
+	if !ok {
+		text, known := ring.ErrorMessages[int(status)]
+		if !known {
+			text = "Unknown status."
+		}
+		message = C.CString(text)
+		errorMessages[int(status)] = message
+	}
+	return message
+}
 
-// bytesToPointer encode bytes into unsafe pointer
-func bytesToPointer(status int, content []byte) unsafe.Pointer {
-	buff := make([]byte, 16)
-	binary.LittleEndian.PutUint64(buff, uint64(status))
-	binary.LittleEndian.PutUint64(buff[8:], uint64(len(content)))
-	buff = append(buff, content...)
-	return C.CBytes(buff)
+// LirisiListSize returns number of items of the list.
+//export LirisiListSize
+func LirisiListSize(list *C.LirisiList) C.size_t {
+	if list == nil {
+		return 0
+	}
+	return list.count
 }
 
-// arrayOfByteArraysToPointer encode array of byte arrays into unsafe pointer
-func arrayOfByteArraysToPointer(status int, contents [][]byte) unsafe.Pointer {
-	buff := make([]byte, 16)
-	binary.LittleEndian.PutUint64(buff, uint64(status))
-	binary.LittleEndian.PutUint64(buff[8:], uint64(len(contents)))
-	for _, item := range contents {
-		size := make([]byte, 8)
-		binary.LittleEndian.PutUint64(size, uint64(len(item)))
-		buff = append(buff, size...)
-		buff = append(buff, item...)
+// LirisiListItem returns item of the list and sets its length. It returns NULL for index out of range or NULL length.
+// The item is owned by the list.
+//export LirisiListItem
+func LirisiListItem(list *C.LirisiList, index C.size_t, length *C.size_t) *C.uint8_t {
+	if list == nil || length == nil || index >= list.count {
+		return nil
 	}
-	return C.CBytes(buff)
+	*length = sizes(list.sizes, int(list.count))[index]
+	return pointers(list.items, int(list.count))[index]
 }
 
-// SignatureKeyImage outputs signature key image.
-//export SignatureKeyImage
-func SignatureKeyImage(content []byte, separator bool) unsafe.Pointer {
-	return bytesToPointer(client.SignatureKeyImage(content, separator))
+// LirisiGeneratePrivateKey generates private key.
+//export LirisiGeneratePrivateKey
+func LirisiGeneratePrivateKey(curveName, format *C.char, out **C.uint8_t, outLen *C.size_t) C.int {
+	status, content := client.GeneratePrivateKey(C.GoString(curveName), C.GoString(format))
+	return setBuffer(status, content, out, outLen)
 }
 
-// FoldPublicKeys folds public keys into one content.
-//export FoldPublicKeys
-func FoldPublicKeys(pubKeysContent [][]byte, hashName, outFormat, order string) unsafe.Pointer {
-	return bytesToPointer(client.FoldPublicKeys(pubKeysContent, hashName, outFormat, order))
+// LirisiDerivePublicKey derives public key from private.
+//export LirisiDerivePublicKey
+func LirisiDerivePublicKey(privateKey *C.uint8_t, privateKeyLen C.size_t, format *C.char, out **C.uint8_t, outLen *C.size_t) C.int {
+	if tooLarge(privateKeyLen) {
+		return C.int(ring.InputTooLarge)
+	}
+	status, content := client.DerivePublicKey(goBytes(privateKey, privateKeyLen), C.GoString(format))
+	return setBuffer(status, content, out, outLen)
 }
 
-// CreateSignature creates signature.
-//export CreateSignature
-func CreateSignature(foldedPublicKeys, privateKeyContent, message, caseIdentifier []byte, outFormat string) unsafe.Pointer {
-	return bytesToPointer(client.CreateSignature(foldedPublicKeys, privateKeyContent, message, caseIdentifier, outFormat))
+// LirisiFoldPublicKeys folds public keys into one content.
+//export LirisiFoldPublicKeys
+func LirisiFoldPublicKeys(publicKeys **C.uint8_t, publicKeysLen *C.size_t, count C.size_t, hashName, format, order *C.char, out **C.uint8_t, outLen *C.size_t) C.int {
+	if status := checkArray(publicKeys, publicKeysLen, count); status != ring.Success {
+		return C.int(status)
+	}
+	status, content := client.FoldPublicKeys(goBytesArray(publicKeys, publicKeysLen, count), C.GoString(hashName), C.GoString(format), C.GoString(order))
+	return setBuffer(status, content, out, outLen)
 }
 
-// VerifySignature verifies signature.
-//export VerifySignature
-func VerifySignature(foldedPublicKeys, signature, message, caseIdentifier []byte) int {
-	return client.VerifySignature(foldedPublicKeys, signature, message, caseIdentifier)
+// LirisiUnfoldPublicKeys separates folded public keys into the list.
+//export LirisiUnfoldPublicKeys
+func LirisiUnfoldPublicKeys(foldedPublicKeys *C.uint8_t, foldedPublicKeysLen C.size_t, format *C.char, out **C.LirisiList) C.int {
+	if tooLarge(foldedPublicKeysLen) {
+		return C.int(ring.InputTooLarge)
+	}
+	status, contents := client.UnfoldPublicKeysIntoBytes(goBytes(foldedPublicKeys, foldedPublicKeysLen), C.GoString(format))
+	if status == ring.Success {
+		*out = newList(contents)
+	}
+	return C.int(status)
 }
 
-// UnfoldPublicKeys separates folded public keys into array of bytes.
-//export UnfoldPublicKeys
-func UnfoldPublicKeys(foldedPublicKeys []byte, outFormat string) unsafe.Pointer {
-	return arrayOfByteArraysToPointer(client.UnfoldPublicKeysIntoBytes(foldedPublicKeys, outFormat))
+// LirisiPublicKeysDigest returns digest of public keys.
+//export LirisiPublicKeysDigest
+func LirisiPublicKeysDigest(foldedPublicKeys *C.uint8_t, foldedPublicKeysLen C.size_t, separator C.int, out **C.uint8_t, outLen *C.size_t) C.int {
+	if tooLarge(foldedPublicKeysLen) {
+		return C.int(ring.InputTooLarge)
+	}
+	status, content := client.PublicKeysDigest(goBytes(foldedPublicKeys, foldedPublicKeysLen), separator != 0)
+	return setBuffer(status, content, out, outLen)
 }
 
-// PublicKeysDigest returns digest of public keys.
-//export PublicKeysDigest
-func PublicKeysDigest(foldedPublicKeys []byte, separator bool) unsafe.Pointer {
-	return bytesToPointer(client.PublicKeysDigest(foldedPublicKeys, separator))
+// LirisiPublicKeyXYCoordinates returns X,Y coordinates of public key.
+//export LirisiPublicKeyXYCoordinates
+func LirisiPublicKeyXYCoordinates(publicKey *C.uint8_t, publicKeyLen C.size_t, out **C.uint8_t, outLen *C.size_t) C.int {
+	if tooLarge(publicKeyLen) {
+		return C.int(ring.InputTooLarge)
+	}
+	status, content := client.PublicKeyXYCoordinates(goBytes(publicKey, publicKeyLen))
+	return setBuffer(status, content, out, outLen)
 }
 
-// PublicKeyXYCoordinates returns X,Y coordinates of public key.
-//export PublicKeyXYCoordinates
-func PublicKeyXYCoordinates(pubicKey []byte) unsafe.Pointer {
-	return bytesToPointer(client.PublicKeyXYCoordinates(pubicKey))
+// LirisiCreateSignature creates signature.
+//export LirisiCreateSignature
+func LirisiCreateSignature(
+	foldedPublicKeys *C.uint8_t, foldedPublicKeysLen C.size_t,
+	privateKey *C.uint8_t, privateKeyLen C.size_t,
+	message *C.uint8_t, messageLen C.size_t,
+	caseIdentifier *C.uint8_t, caseIdentifierLen C.size_t,
+	format *C.char, out **C.uint8_t, outLen *C.size_t,
+) C.int {
+	if tooLarge(foldedPublicKeysLen, privateKeyLen, messageLen, caseIdentifierLen) {
+		return C.int(ring.InputTooLarge)
+	}
+	status, content := client.CreateSignature(
+		goBytes(foldedPublicKeys, foldedPublicKeysLen),
+		goBytes(privateKey, privateKeyLen),
+		goBytes(message, messageLen),
+		goBytes(caseIdentifier, caseIdentifierLen),
+		C.GoString(format),
+	)
+	return setBuffer(status, content, out, outLen)
 }
 
-// GeneratePrivateKey generates private key.
-//export GeneratePrivateKey
-func GeneratePrivateKey(curveName, format string) unsafe.Pointer {
-	return bytesToPointer(client.GeneratePrivateKey(curveName, format))
+// LirisiVerifySignature verifies signature.
+//export LirisiVerifySignature
+func LirisiVerifySignature(
+	foldedPublicKeys *C.uint8_t, foldedPublicKeysLen C.size_t,
+	signature *C.uint8_t, signatureLen C.size_t,
+	message *C.uint8_t, messageLen C.size_t,
+	caseIdentifier *C.uint8_t, caseIdentifierLen C.size_t,
+) C.int {
+	if tooLarge(foldedPublicKeysLen, signatureLen, messageLen, caseIdentifierLen) {
+		return C.int(ring.InputTooLarge)
+	}
+	return C.int(client.VerifySignature(
+		goBytes(foldedPublicKeys, foldedPublicKeysLen),
+		goBytes(signature, signatureLen),
+		goBytes(message, messageLen),
+		goBytes(caseIdentifier, caseIdentifierLen),
+	))
 }
 
-// DerivePublicKey derives public keys from private.
-//export DerivePublicKey
-func DerivePublicKey(privateKey []byte, format string) unsafe.Pointer {
-	return bytesToPointer(client.DerivePublicKey(privateKey, format))
+// LirisiVerifySignatureDetails verifies signature and sets the verification with key image, curve and hash names,
+// size and digest of the ring. It returns 0 when the verification is set, the status of the verification is in it.
+//export LirisiVerifySignatureDetails
+func LirisiVerifySignatureDetails(
+	foldedPublicKeys *C.uint8_t, foldedPublicKeysLen C.size_t,
+	signature *C.uint8_t, signatureLen C.size_t,
+	message *C.uint8_t, messageLen C.size_t,
+	caseIdentifier *C.uint8_t, caseIdentifierLen C.size_t,
+	separator C.int, out **C.LirisiVerification,
+) C.int {
+	if tooLarge(foldedPublicKeysLen, signatureLen, messageLen, caseIdentifierLen) {
+		return C.int(ring.InputTooLarge)
+	}
+	*out = newVerification(client.VerifySignatureDetails(
+		goBytes(foldedPublicKeys, foldedPublicKeysLen),
+		goBytes(signature, signatureLen),
+		goBytes(message, messageLen),
+		goBytes(caseIdentifier, caseIdentifierLen),
+		separator != 0,
+	))
+	return C.int(ring.Success)
+}
+
+// LirisiSignatureKeyImage outputs signature key image.
+//export LirisiSignatureKeyImage
+func LirisiSignatureKeyImage(signature *C.uint8_t, signatureLen C.size_t, separator C.int, out **C.uint8_t, outLen *C.size_t) C.int {
+	if tooLarge(signatureLen) {
+		return C.int(ring.InputTooLarge)
+	}
+	status, content := client.SignatureKeyImage(goBytes(signature, signatureLen), separator != 0)
+	return setBuffer(status, content, out, outLen)
 }
 
 func main() {}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// TestCAPI builds the shared library with its header and runs the C test program against it.
+func TestCAPI(t *testing.T) {
+	compiler := os.Getenv("CC")
+	if compiler == "" {
+		compiler = "cc"
+	}
+	if _, err := exec.LookPath(compiler); err != nil {
+		t.Skip("C compiler not found.")
+	}
+	dir, err := ioutil.TempDir("", "lirisilib")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	library := filepath.Join(dir, "lirisilib.so")
+	build := exec.Command("go", "build", "-buildmode=c-shared", "-o", library, ".")
+	if output, err := build.CombinedOutput(); err != nil {
+		t.Fatalf("%v: %s", err, output)
+	}
+	program := filepath.Join(dir, "lirisilib_test")
+	compile := exec.Command(compiler, "-Wall", "-Werror", "-I", dir,
+		"-o", program, filepath.Join("testdata", "lirisilib_test.c"), library, "-Wl,-rpath,"+dir)
+	if output, err := compile.CombinedOutput(); err != nil {
+		t.Fatalf("%v: %s", err, output)
+	}
+	if output, err := exec.Command(program).CombinedOutput(); err != nil {
+		t.Fatalf("%v: %s", err, output)
+	}
+}
@@ -0,0 +1,88 @@
+// Package quota limits members of the ring to k anonymous signatures per time epoch.
+// Each signature is made under the case identifier H(case || epoch || counter) with the counter lower than k.
+// A member has only k distinct key images in the epoch, so the (k+1)-th signature reuses a counter
+// and it is linked with an earlier one. The member stays anonymous unless the quota is exceeded.
+package quota
+
+import (
+	"encoding/binary"
+	"time"
+
+	"github.com/zbohm/lirisi/client"
+	"github.com/zbohm/lirisi/registry"
+	"github.com/zbohm/lirisi/ring"
+	"golang.org/x/crypto/sha3"
+)
+
+// Epoch returns number of the epoch of the length containing the time. The length must be at least one second.
+func Epoch(t time.Time, length time.Duration) (int, int64) {
+	seconds := int64(length / time.Second)
+	if seconds < 1 {
+		return ring.InvalidEpochLength, 0
+	}
+	epoch := t.Unix() / seconds
+	if t.Unix()%seconds < 0 {
+		epoch--
+	}
+	return ring.Success, epoch
+}
+
+// EpochStart returns the beginning of the epoch of the length. The length must be at least one second.
+func EpochStart(epoch int64, length time.Duration) (int, time.Time) {
+	seconds := int64(length / time.Second)
+	if seconds < 1 {
+		return ring.InvalidEpochLength, time.Time{}
+	}
+	return ring.Success, time.Unix(epoch*seconds, 0)
+}
+
+// CaseIdentifier derives case identifier of the counter in the epoch as SHA3-256(case || epoch || counter),
+// with the epoch and the counter in 8 and 4 bytes big-endian.
+func CaseIdentifier(caseIdentifier []byte, epoch int64, counter int) []byte {
+	suffix := make([]byte, 12)
+	binary.BigEndian.PutUint64(suffix, uint64(epoch))
+	binary.BigEndian.PutUint32(suffix[8:], uint32(counter))
+	digest := sha3.Sum256(append(append([]byte{}, caseIdentifier...), suffix...))
+	return digest[:]
+}
+
+// Quota of k signatures per epoch in the case.
+type Quota struct {
+	Case []byte
+	K    int
+}
+
+func (q Quota) caseIdentifier(epoch int64, counter int) (int, []byte) {
+	if counter < 0 || counter >= q.K {
+		return ring.CounterOutOfRange, nil
+	}
+	return ring.Success, CaseIdentifier(q.Case, epoch, counter)
+}
+
+// Sign creates signature of the message under the counter in the epoch.
+func (q Quota) Sign(foldedPublicKeys, privateKey, message []byte, epoch int64, counter int, outFormat string) (int, []byte) {
+	status, caseIdentifier := q.caseIdentifier(epoch, counter)
+	if status != ring.Success {
+		return status, nil
+	}
+	return client.CreateSignature(foldedPublicKeys, privateKey, message, caseIdentifier, outFormat)
+}
+
+// Verify checks range of the counter and verifies signature of the message under the counter in the epoch.
+func (q Quota) Verify(foldedPublicKeys, signature, message []byte, epoch int64, counter int) int {
+	status, caseIdentifier := q.caseIdentifier(epoch, counter)
+	if status != ring.Success {
+		return status
+	}
+	return client.VerifySignature(foldedPublicKeys, signature, message, caseIdentifier)
+}
+
+// Submit checks range of the counter and submits signature into the registry under the derived case identifier.
+// Outcome registry.Conflicting means the member exceeded the quota in the epoch.
+func (q Quota) Submit(r *registry.Registry, foldedPublicKeys, signature, message []byte, epoch int64, counter int) (int, registry.Outcome) {
+	status, caseIdentifier := q.caseIdentifier(epoch, counter)
+	if status != ring.Success {
+		return status, registry.Invalid
+	}
+	return r.Submit(signature, foldedPublicKeys, message, caseIdentifier)
+}
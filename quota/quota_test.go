@@ -0,0 +1,116 @@
+package quota
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/zbohm/lirisi/internal/testring"
+	"github.com/zbohm/lirisi/registry"
+	"github.com/zbohm/lirisi/ring"
+)
+
+const day = 24 * time.Hour
+
+func epochOf(t *testing.T, moment time.Time, length time.Duration) int64 {
+	status, epoch := Epoch(moment, length)
+	if status != ring.Success {
+		t.Fatal(ring.ErrorMessages[status])
+	}
+	return epoch
+}
+
+func TestEpoch(t *testing.T) {
+	moment := time.Date(2026, 10, 19, 13, 30, 0, 0, time.UTC)
+	current := epochOf(t, moment, day)
+	status, start := EpochStart(current, day)
+	if status != ring.Success || !start.Equal(time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected start of the epoch %s.", start)
+	}
+	if epochOf(t, moment.Add(day), day) != current+1 {
+		t.Errorf("Next day is not the next epoch.")
+	}
+	if epochOf(t, time.Unix(-1, 0), time.Hour) != -1 {
+		t.Errorf("Time before 1970 is not in the epoch -1.")
+	}
+}
+
+func TestInvalidEpochLength(t *testing.T) {
+	for _, length := range []time.Duration{0, -time.Hour, 500 * time.Millisecond} {
+		if status, _ := Epoch(time.Now(), length); status != ring.InvalidEpochLength {
+			t.Errorf("Epoch of the length %s with status %d.", length, status)
+		}
+		if status, _ := EpochStart(1, length); status != ring.InvalidEpochLength {
+			t.Errorf("Start of the epoch of the length %s with status %d.", length, status)
+		}
+	}
+}
+
+func TestCaseIdentifier(t *testing.T) {
+	first := CaseIdentifier([]byte("forum"), 100, 0)
+	if len(first) != 32 {
+		t.Errorf("Unexpected length %d.", len(first))
+	}
+	for _, other := range [][]byte{
+		CaseIdentifier([]byte("forum"), 100, 1),
+		CaseIdentifier([]byte("forum"), 101, 0),
+		CaseIdentifier([]byte("forum2"), 100, 0),
+	} {
+		if bytes.Equal(first, other) {
+			t.Errorf("Case identifiers of different inputs are equal.")
+		}
+	}
+	if !bytes.Equal(first, CaseIdentifier([]byte("forum"), 100, 0)) {
+		t.Errorf("Case identifier is not deterministic.")
+	}
+}
+
+func TestQuota(t *testing.T) {
+	privateKeys, folded := testring.Create(t, 3)
+	q := Quota{Case: []byte("forum"), K: 3}
+	epoch := epochOf(t, time.Now(), day)
+	reg := registry.NewRegistry(registry.NewMemoryStorage())
+
+	sign := func(privateKey []byte, message string, epoch int64, counter int) []byte {
+		status, signature := q.Sign(folded, privateKey, []byte(message), epoch, counter, "PEM")
+		if status != ring.Success {
+			t.Fatal(ring.ErrorMessages[status])
+		}
+		return signature
+	}
+	submit := func(signature []byte, message string, epoch int64, counter int, expected registry.Outcome) {
+		status, outcome := q.Submit(reg, folded, signature, []byte(message), epoch, counter)
+		if status != ring.Success {
+			t.Fatal(ring.ErrorMessages[status])
+		}
+		if outcome != expected {
+			t.Errorf("Unexpected outcome %s, expected %s.", outcome, expected)
+		}
+	}
+
+	for counter := 0; counter < q.K; counter++ {
+		signature := sign(privateKeys[0], "post", epoch, counter)
+		if status := q.Verify(folded, signature, []byte("post"), epoch, counter); status != ring.Success {
+			t.Fatal(ring.ErrorMessages[status])
+		}
+		submit(signature, "post", epoch, counter, registry.New)
+	}
+	// Another member and the next epoch have their own quota.
+	submit(sign(privateKeys[1], "post", epoch, 0), "post", epoch, 0, registry.New)
+	submit(sign(privateKeys[0], "post", epoch+1, 0), "post", epoch+1, 0, registry.New)
+	// Reused counter exceeds the quota.
+	submit(sign(privateKeys[0], "fourth post", epoch, 1), "fourth post", epoch, 1, registry.Conflicting)
+
+	if status, _ := q.Sign(folded, privateKeys[0], []byte("post"), epoch, q.K, "PEM"); status != ring.CounterOutOfRange {
+		t.Errorf("Signature with the counter out of range created with status %d.", status)
+	}
+	if status := q.Verify(folded, sign(privateKeys[0], "post", epoch, 0), []byte("post"), epoch, -1); status != ring.CounterOutOfRange {
+		t.Errorf("Counter out of range verified with status %d.", status)
+	}
+	if status := q.Verify(folded, sign(privateKeys[0], "post", epoch, 0), []byte("post"), epoch, 1); status == ring.Success {
+		t.Errorf("Signature verified under another counter.")
+	}
+	if status, _ := q.Submit(reg, folded, sign(privateKeys[2], "post", epoch, 2), []byte("post"), epoch, 3); status != ring.CounterOutOfRange {
+		t.Errorf("Counter out of range submitted with status %d.", status)
+	}
+}
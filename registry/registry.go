@@ -0,0 +1,145 @@
+// Package registry keeps key images of accepted signatures and detects linked signatures.
+package registry
+
+import (
+	"encoding/hex"
+	"sync"
+
+	"github.com/zbohm/lirisi/client"
+	"github.com/zbohm/lirisi/ring"
+)
+
+// Outcome of the submitted signature.
+type Outcome int
+
+// Outcomes of the submitted signature.
+const (
+	// Invalid signature was not verified and it was not stored.
+	Invalid Outcome = iota
+	// New signature has a key image not seen before in the ring and case.
+	New
+	// DuplicateSameMessage signature is linked with a stored signature of the same message.
+	DuplicateSameMessage
+	// Conflicting signature is linked with a stored signature of another message.
+	Conflicting
+)
+
+var outcomeNames = map[Outcome]string{
+	Invalid:              "invalid",
+	New:                  "new",
+	DuplicateSameMessage: "duplicate-same-message",
+	Conflicting:          "conflicting",
+}
+
+func (o Outcome) String() string {
+	return outcomeNames[o]
+}
+
+// Entry holds accepted signature with the message. Digests, case and key image are hex encoded.
+type Entry struct {
+	RingDigest    string `json:"ring_digest"`
+	Case          string `json:"case"`
+	KeyImage      string `json:"key_image"`
+	MessageDigest string `json:"message_digest"`
+	Signature     []byte `json:"signature"`
+	Message       []byte `json:"message"`
+}
+
+// Storage keeps entries of the registry. Stored entries are never changed or removed.
+type Storage interface {
+	// Find returns entries with the ring digest, case and key image.
+	Find(ringDigest, caseIdentifier, keyImage string) ([]Entry, error)
+	// Append stores the entry.
+	Append(entry Entry) error
+	// All returns all entries in the order they were stored.
+	All() ([]Entry, error)
+}
+
+// Registry verifies submitted signatures and detects linked ones. It is safe for concurrent use.
+// Conflicting signatures are stored as the evidence, unless RejectConflicting is set.
+type Registry struct {
+	RejectConflicting bool
+	storage           Storage
+	mutex             sync.Mutex
+}
+
+// NewRegistry creates registry over the storage.
+func NewRegistry(storage Storage) *Registry {
+	return &Registry{storage: storage}
+}
+
+// NewEntry verifies signature and creates its entry.
+func NewEntry(signature, foldedPublicKeys, message, caseIdentifier []byte) (int, Entry) {
+	entry := Entry{}
+	if status := client.VerifySignature(foldedPublicKeys, signature, message, caseIdentifier); status != ring.Success {
+		return status, entry
+	}
+	status, sign := client.ParseSignature(signature)
+	if status != ring.Success {
+		return status, entry
+	}
+	status, publicKeys, _ := client.UnfoldPublicKeysContent(foldedPublicKeys)
+	if status != ring.Success {
+		return status, entry
+	}
+	status, fc := ring.GetFactoryContext(sign.CurveOID, sign.HasherOID)
+	if status != ring.Success {
+		return status, entry
+	}
+	status, der := client.EncodeSignarureToDER(&sign)
+	if status != ring.Success {
+		return status, entry
+	}
+	entry.RingDigest = hex.EncodeToString(fc.PublicKeysDigest(publicKeys))
+	entry.Case = hex.EncodeToString(caseIdentifier)
+	entry.KeyImage = hex.EncodeToString(sign.KeyImage.Bytes())
+	entry.MessageDigest = hex.EncodeToString(fc.MakeDigest(message))
+	entry.Signature = der
+	entry.Message = message
+	return ring.Success, entry
+}
+
+// Submit verifies signature, looks up signatures linked with it and stores it.
+// Signature of the same message as the linked one is not stored again.
+func (r *Registry) Submit(signature, foldedPublicKeys, message, caseIdentifier []byte) (int, Outcome) {
+	status, entry := NewEntry(signature, foldedPublicKeys, message, caseIdentifier)
+	if status != ring.Success {
+		return status, Invalid
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	linked, err := r.storage.Find(entry.RingDigest, entry.Case, entry.KeyImage)
+	if err != nil {
+		return ring.StorageFailure, Invalid
+	}
+	outcome := New
+	for _, item := range linked {
+		if item.MessageDigest == entry.MessageDigest {
+			return ring.Success, DuplicateSameMessage
+		}
+		outcome = Conflicting
+	}
+	if outcome == Conflicting && r.RejectConflicting {
+		return ring.Success, outcome
+	}
+	if err := r.storage.Append(entry); err != nil {
+		return ring.StorageFailure, Invalid
+	}
+	return ring.Success, outcome
+}
+
+// Lookup returns stored signatures with the key image in the ring and case.
+func (r *Registry) Lookup(ringDigest, caseIdentifier, keyImage string) ([]Entry, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.storage.Find(ringDigest, caseIdentifier, keyImage)
+}
+
+// Entries returns all stored signatures.
+func (r *Registry) Entries() ([]Entry, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.storage.All()
+}
@@ -0,0 +1,149 @@
+package registry
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/zbohm/lirisi/client"
+	"github.com/zbohm/lirisi/internal/testring"
+	"github.com/zbohm/lirisi/ring"
+)
+
+var caseIdentifier = []byte("election-2026")
+
+func sign(t *testing.T, folded, privateKey, message []byte) []byte {
+	status, signature := client.CreateSignature(folded, privateKey, message, caseIdentifier, "PEM")
+	if status != ring.Success {
+		t.Fatal(ring.ErrorMessages[status])
+	}
+	return signature
+}
+
+func submit(t *testing.T, registry *Registry, signature, folded, message []byte, expected Outcome) {
+	status, outcome := registry.Submit(signature, folded, message, caseIdentifier)
+	if status != ring.Success {
+		t.Fatal(ring.ErrorMessages[status])
+	}
+	if outcome != expected {
+		t.Errorf("Unexpected outcome %s, expected %s.", outcome, expected)
+	}
+}
+
+func checkOutcomes(t *testing.T, storage Storage) {
+	privateKeys, folded := testring.Create(t, 3)
+	registry := NewRegistry(storage)
+
+	submit(t, registry, sign(t, folded, privateKeys[0], []byte("yes")), folded, []byte("yes"), New)
+	submit(t, registry, sign(t, folded, privateKeys[1], []byte("no")), folded, []byte("no"), New)
+	submit(t, registry, sign(t, folded, privateKeys[0], []byte("yes")), folded, []byte("yes"), DuplicateSameMessage)
+	submit(t, registry, sign(t, folded, privateKeys[0], []byte("no")), folded, []byte("no"), Conflicting)
+
+	entries, err := registry.Entries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 3 {
+		t.Errorf("Unexpected number of entries %d.", len(entries))
+	}
+	linked, err := registry.Lookup(entries[0].RingDigest, entries[0].Case, entries[0].KeyImage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(linked) != 2 {
+		t.Errorf("Unexpected number of linked entries %d.", len(linked))
+	}
+}
+
+func TestSubmitMemoryStorage(t *testing.T) {
+	checkOutcomes(t, NewMemoryStorage())
+}
+
+func TestRejectConflicting(t *testing.T) {
+	privateKeys, folded := testring.Create(t, 2)
+	registry := NewRegistry(NewMemoryStorage())
+	registry.RejectConflicting = true
+
+	submit(t, registry, sign(t, folded, privateKeys[0], []byte("yes")), folded, []byte("yes"), New)
+	submit(t, registry, sign(t, folded, privateKeys[0], []byte("no")), folded, []byte("no"), Conflicting)
+	entries, err := registry.Entries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || string(entries[0].Message) != "yes" {
+		t.Errorf("Unexpected entries %v.", entries)
+	}
+}
+
+func TestSubmitInvalidSignature(t *testing.T) {
+	privateKeys, folded := testring.Create(t, 2)
+	registry := NewRegistry(NewMemoryStorage())
+	signature := sign(t, folded, privateKeys[0], []byte("yes"))
+	status, outcome := registry.Submit(signature, folded, []byte("no"), caseIdentifier)
+	if status != ring.IncorrectChecksum {
+		t.Errorf("Unexpected status %s", ring.ErrorMessages[status])
+	}
+	if outcome != Invalid {
+		t.Errorf("Unexpected outcome %s.", outcome)
+	}
+}
+
+func TestSubmitFileStorage(t *testing.T) {
+	dir, err := ioutil.TempDir("", "registry")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "registry.jsonl")
+
+	storage, err := OpenFileStorage(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkOutcomes(t, storage)
+	if err := storage.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	storage, err = OpenFileStorage(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer storage.Close()
+	entries, err := storage.All()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 3 {
+		t.Errorf("Unexpected number of reloaded entries %d.", len(entries))
+	}
+}
+
+func TestSubmitConcurrently(t *testing.T) {
+	privateKeys, folded := testring.Create(t, 2)
+	registry := NewRegistry(NewMemoryStorage())
+	signature := sign(t, folded, privateKeys[0], []byte("yes"))
+
+	outcomes := make(chan Outcome, 8)
+	var wg sync.WaitGroup
+	for i := 0; i < cap(outcomes); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, outcome := registry.Submit(signature, folded, []byte("yes"), caseIdentifier)
+			outcomes <- outcome
+		}()
+	}
+	wg.Wait()
+	close(outcomes)
+
+	counts := map[Outcome]int{}
+	for outcome := range outcomes {
+		counts[outcome]++
+	}
+	if counts[New] != 1 || counts[DuplicateSameMessage] != cap(outcomes)-1 {
+		t.Errorf("Unexpected outcomes %v.", counts)
+	}
+}
@@ -0,0 +1,129 @@
+package registry
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"sync"
+)
+
+func entryKey(ringDigest, caseIdentifier, keyImage string) string {
+	return ringDigest + "/" + caseIdentifier + "/" + keyImage
+}
+
+// MemoryStorage keeps entries in memory.
+type MemoryStorage struct {
+	entries []Entry
+	index   map[string][]int
+	mutex   sync.RWMutex
+}
+
+// NewMemoryStorage creates empty memory storage.
+func NewMemoryStorage() *MemoryStorage {
+	return &MemoryStorage{index: map[string][]int{}}
+}
+
+// Find returns entries with the ring digest, case and key image.
+func (s *MemoryStorage) Find(ringDigest, caseIdentifier, keyImage string) ([]Entry, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	positions := s.index[entryKey(ringDigest, caseIdentifier, keyImage)]
+	entries := make([]Entry, len(positions))
+	for i, position := range positions {
+		entries[i] = s.entries[position]
+	}
+	return entries, nil
+}
+
+// Append stores the entry.
+func (s *MemoryStorage) Append(entry Entry) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	key := entryKey(entry.RingDigest, entry.Case, entry.KeyImage)
+	s.index[key] = append(s.index[key], len(s.entries))
+	s.entries = append(s.entries, entry)
+	return nil
+}
+
+// All returns all entries in the order they were stored.
+func (s *MemoryStorage) All() ([]Entry, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return append([]Entry{}, s.entries...), nil
+}
+
+// FileStorage keeps entries in the append-only file, one JSON entry per line.
+// The entries are indexed in memory.
+type FileStorage struct {
+	memory *MemoryStorage
+	handle *os.File
+	mutex  sync.Mutex
+}
+
+// OpenFileStorage opens or creates the file and loads entries stored in it.
+func OpenFileStorage(path string) (*FileStorage, error) {
+	memory := NewMemoryStorage()
+	handle, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+	scanner := bufio.NewScanner(handle)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		entry := Entry{}
+		if err := json.Unmarshal(line, &entry); err != nil {
+			handle.Close()
+			return nil, err
+		}
+		if err := memory.Append(entry); err != nil {
+			handle.Close()
+			return nil, err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		handle.Close()
+		return nil, err
+	}
+	if _, err := handle.Seek(0, os.SEEK_END); err != nil {
+		handle.Close()
+		return nil, err
+	}
+	return &FileStorage{memory: memory, handle: handle}, nil
+}
+
+// Find returns entries with the ring digest, case and key image.
+func (s *FileStorage) Find(ringDigest, caseIdentifier, keyImage string) ([]Entry, error) {
+	return s.memory.Find(ringDigest, caseIdentifier, keyImage)
+}
+
+// Append writes the entry at the end of the file.
+func (s *FileStorage) Append(entry Entry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if _, err := s.handle.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	if err := s.handle.Sync(); err != nil {
+		return err
+	}
+	return s.memory.Append(entry)
+}
+
+// All returns all entries in the order they were stored.
+func (s *FileStorage) All() ([]Entry, error) {
+	return s.memory.All()
+}
+
+// Close closes the file.
+func (s *FileStorage) Close() error {
+	return s.handle.Close()
+}
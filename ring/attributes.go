@@ -0,0 +1,103 @@
+package ring
+
+import (
+	"bytes"
+	"encoding/asn1"
+	"sort"
+)
+
+// CanonicalAttributes sorts attributes by their DER encoding, which is the order of SET OF in DER.
+// Attributes with an empty key or a key set more than once are rejected.
+func CanonicalAttributes(attributes []Attribute) (int, []Attribute) {
+	encoded := make([][]byte, len(attributes))
+	keys := map[string]bool{}
+	for i, attribute := range attributes {
+		if attribute.Key == "" {
+			return InvalidAttribute, nil
+		}
+		if keys[attribute.Key] {
+			return DuplicateAttribute, nil
+		}
+		keys[attribute.Key] = true
+		content, err := asn1.Marshal(attribute)
+		if err != nil {
+			return InvalidAttribute, nil
+		}
+		encoded[i] = content
+	}
+	indexes := make([]int, len(attributes))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	sort.Slice(indexes, func(i, j int) bool { return bytes.Compare(encoded[indexes[i]], encoded[indexes[j]]) < 0 })
+	sorted := make([]Attribute, len(attributes))
+	for i, index := range indexes {
+		sorted[i] = attributes[index]
+	}
+	return Success, sorted
+}
+
+// EncodeAttributes encodes attributes into canonical DER of SET OF Attribute.
+func EncodeAttributes(attributes []Attribute) (int, []byte) {
+	status, sorted := CanonicalAttributes(attributes)
+	if status != Success {
+		return status, nil
+	}
+	var elements []byte
+	for _, attribute := range sorted {
+		content, err := asn1.Marshal(attribute)
+		if err != nil {
+			return Asn1MarshalFailed, nil
+		}
+		elements = append(elements, content...)
+	}
+	content, err := asn1.Marshal(asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagSet, IsCompound: true, Bytes: elements})
+	if err != nil {
+		return Asn1MarshalFailed, nil
+	}
+	return Success, content
+}
+
+// AttributesPrefix separates the digest of the message with attributes from the digest of the message without them.
+var AttributesPrefix = []byte("\x00" + Origin + " signed attributes\x00")
+
+// messageDigest returns digest of the message signed by the ring signature.
+// Without attributes and sources it is the digest of the message, as in the signatures made before attributes.
+// Otherwise it is the digest of the prefix, the message digest, the canonical DER of the attributes
+// and the DER of the digests of the source rings tagged [0]. Empty attributes or sources are left out.
+// A message without attributes and sources must not start with the prefix, otherwise a signature with stripped
+// attributes would be valid for the message made of the prefix, the digest and the attributes.
+func (fc FactoryContext) messageDigest(message []byte, attributes []Attribute, sources [][]byte) (int, []byte) {
+	if len(attributes) == 0 && len(sources) == 0 {
+		if bytes.HasPrefix(message, AttributesPrefix) {
+			return ReservedMessagePrefix, nil
+		}
+		return Success, fc.MakeDigest(message)
+	}
+	buff := append(append([]byte{}, AttributesPrefix...), fc.MakeDigest(message)...)
+	if len(attributes) > 0 {
+		status, encoded := EncodeAttributes(attributes)
+		if status != Success {
+			return status, nil
+		}
+		buff = append(buff, encoded...)
+	}
+	if len(sources) > 0 {
+		encoded, err := asn1.MarshalWithParams(sources, "explicit,tag:0")
+		if err != nil {
+			return Asn1MarshalFailed, nil
+		}
+		buff = append(buff, encoded...)
+	}
+	return Success, fc.MakeDigest(buff)
+}
+
+// Attribute returns value of the signed attribute.
+func (sign *Signature) Attribute(key string) (string, bool) {
+	for _, attribute := range sign.SignedAttributes {
+		if attribute.Key == key {
+			return attribute.Value, true
+		}
+	}
+	return "", false
+}
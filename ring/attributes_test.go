@@ -0,0 +1,142 @@
+package ring
+
+import (
+	"bytes"
+	"crypto/elliptic"
+	"encoding/asn1"
+	"testing"
+
+	"golang.org/x/crypto/sha3"
+)
+
+var attributes = []Attribute{
+	{Key: "signing-time", Value: "2026-10-19T12:00:00Z"},
+	{Key: "content-type", Value: "text/plain"},
+	{Key: "app", Value: "forum"},
+}
+
+func TestSignedAttributes(t *testing.T) {
+	privateKeys, publicKeys := createPrivatePublicKeys(elliptic.P256, 3)
+	status, sign := CreateWithAttributes(elliptic.P256, sha3.New256, privateKeys[1], publicKeys, message, []byte("case"), attributes)
+	if status != Success {
+		t.Fatalf("Create failed: %s", ErrorMessages[status])
+	}
+	content, err := asn1.Marshal(*sign)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed := Signature{}
+	if _, err := asn1.Unmarshal(content, &parsed); err != nil {
+		t.Fatal(err)
+	}
+	if status := Verify(&parsed, publicKeys, message, []byte("case")); status != Success {
+		t.Errorf("Signature with attributes is not valid: %s", ErrorMessages[status])
+	}
+	if value, ok := parsed.Attribute("content-type"); !ok || value != "text/plain" {
+		t.Errorf("Unexpected attribute %s.", value)
+	}
+	if _, ok := parsed.Attribute("expires"); ok {
+		t.Errorf("Missing attribute found.")
+	}
+
+	altered := parsed
+	altered.SignedAttributes = append([]Attribute{}, parsed.SignedAttributes...)
+	altered.SignedAttributes[0].Value = "changed"
+	if status := Verify(&altered, publicKeys, message, []byte("case")); status != IncorrectChecksum {
+		t.Errorf("Signature with altered attribute verified with status %d.", status)
+	}
+	stripped := parsed
+	stripped.SignedAttributes = nil
+	if status := Verify(&stripped, publicKeys, message, []byte("case")); status != IncorrectChecksum {
+		t.Errorf("Signature with stripped attributes verified with status %d.", status)
+	}
+	duplicate := parsed
+	duplicate.SignedAttributes = append(append([]Attribute{}, parsed.SignedAttributes...), parsed.SignedAttributes[0])
+	if status := Verify(&duplicate, publicKeys, message, []byte("case")); status != DuplicateAttribute {
+		t.Errorf("Signature with duplicate attribute verified with status %d.", status)
+	}
+}
+
+func TestStrippedAttributesMessage(t *testing.T) {
+	privateKeys, publicKeys := createPrivatePublicKeys(elliptic.P256, 2)
+	status, sign := CreateWithAttributes(elliptic.P256, sha3.New256, privateKeys[0], publicKeys, message, []byte("case"), attributes)
+	if status != Success {
+		t.Fatalf("Create failed: %s", ErrorMessages[status])
+	}
+	fc := FactoryContext{Curve: elliptic.P256(), Hasher: sha3.New256}
+	status, encoded := EncodeAttributes(sign.SignedAttributes)
+	if status != Success {
+		t.Fatal(ErrorMessages[status])
+	}
+	forged := append(append(append([]byte{}, AttributesPrefix...), fc.MakeDigest(message)...), encoded...)
+	stripped := *sign
+	stripped.SignedAttributes = nil
+	if status := Verify(&stripped, publicKeys, forged, []byte("case")); status != ReservedMessagePrefix {
+		t.Errorf("Signature with stripped attributes verified the forged message with status %d.", status)
+	}
+	if status, _ := Create(elliptic.P256, sha3.New256, privateKeys[0], publicKeys, forged, []byte("case")); status != ReservedMessagePrefix {
+		t.Errorf("Message with the reserved prefix signed with status %d.", status)
+	}
+}
+
+func TestSignatureWithoutAttributes(t *testing.T) {
+	privateKeys, publicKeys := createPrivatePublicKeys(elliptic.P256, 2)
+	status, sign := Create(elliptic.P256, sha3.New256, privateKeys[0], publicKeys, message, []byte("case"))
+	if status != Success {
+		t.Fatalf("Create failed: %s", ErrorMessages[status])
+	}
+	if sign.SignedAttributes != nil || sign.Sources != nil {
+		t.Errorf("Unexpected attributes or sources.")
+	}
+	fc := FactoryContext{Curve: elliptic.P256(), Hasher: sha3.New256}
+	status, digest := fc.messageDigest(message, nil, nil)
+	if status != Success || !bytes.Equal(digest, fc.MakeDigest(message)) {
+		t.Errorf("Digest of the message without attributes changed.")
+	}
+
+	// Signature in the format without the ring digest still verifies.
+	legacy := struct {
+		Name       string
+		Version    int
+		CurveOID   asn1.ObjectIdentifier
+		HasherOID  asn1.ObjectIdentifier
+		KeyImage   PointData
+		Checksum   []byte
+		Signatures [][]byte
+	}{sign.Name, sign.Version, sign.CurveOID, sign.HasherOID, sign.KeyImage, sign.Checksum, sign.Signatures}
+	content, err := asn1.Marshal(legacy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed := Signature{}
+	if _, err := asn1.Unmarshal(content, &parsed); err != nil {
+		t.Fatal(err)
+	}
+	if parsed.RingDigest != nil {
+		t.Errorf("Unexpected ring digest.")
+	}
+	if status := Verify(&parsed, publicKeys, message, []byte("case")); status != Success {
+		t.Errorf("Signature without the ring digest is not valid: %s", ErrorMessages[status])
+	}
+}
+
+func TestEncodeAttributes(t *testing.T) {
+	status, first := EncodeAttributes(attributes)
+	if status != Success {
+		t.Fatal(ErrorMessages[status])
+	}
+	reversed := []Attribute{attributes[2], attributes[1], attributes[0]}
+	status, second := EncodeAttributes(reversed)
+	if status != Success || !bytes.Equal(first, second) {
+		t.Errorf("Encoding depends on the order of attributes.")
+	}
+	if first[0] != 0x31 {
+		t.Errorf("Attributes are not encoded as SET OF.")
+	}
+	if status, _ := EncodeAttributes([]Attribute{{Key: "", Value: "x"}}); status != InvalidAttribute {
+		t.Errorf("Unexpected status %d of empty key.", status)
+	}
+	if status, _ := EncodeAttributes([]Attribute{{Key: "a", Value: "x"}, {Key: "a", Value: "y"}}); status != DuplicateAttribute {
+		t.Errorf("Unexpected status %d of duplicate key.", status)
+	}
+}
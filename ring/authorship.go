@@ -0,0 +1,128 @@
+// # Voluntary authorship claim.
+
+// The signer reveals the public key *y<sub>π</sub>* and proves
+// *log<sub>g</sub>(y<sub>π</sub>) = log<sub>h</sub>(ỹ)*, where *h = H<sub>2</sub>(L)* and *ỹ* is the key image
+// of the signature. The proof is bound to a fresh challenge of the verifier, so it cannot be replayed.
+
+package ring
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+)
+
+// findPublicKey returns position of the point among public keys or -1.
+func findPublicKey(publicKeys []*ecdsa.PublicKey, x, y []byte) int {
+	px, py := BuffToInt(x), BuffToInt(y)
+	for i, pub := range publicKeys {
+		if pub.X.Cmp(px) == 0 && pub.Y.Cmp(py) == 0 {
+			return i
+		}
+	}
+	return -1
+}
+
+// signatureContext returns factory context of the signature and the point h = H2(L).
+func signatureContext(sign *Signature, publicKeys []*ecdsa.PublicKey, caseIdentifier []byte) (int, FactoryContext, Point) {
+	status, fc := GetFactoryContext(sign.CurveOID, sign.HasherOID)
+	if status != Success {
+		return status, fc, Point{}
+	}
+	for _, pub := range publicKeys {
+		if pub.Curve != fc.Curve {
+			return UnexpectedCurveType, fc, Point{}
+		}
+	}
+	if !fc.Curve.IsOnCurve(BuffToInt(sign.KeyImage.X), BuffToInt(sign.KeyImage.Y)) {
+		return InvalidKeyImage, fc, Point{}
+	}
+	h := fc.HashPublicKeysIntoPoint(ConvertPublicKeysToPoints(publicKeys), caseIdentifier)
+	if h.x == nil {
+		return PointWasNotFound, fc, h
+	}
+	return Success, fc, h
+}
+
+// ProveAuthorship creates proof that the private key made the signature.
+func ProveAuthorship(
+	privateKey *ecdsa.PrivateKey,
+	sign *Signature,
+	publicKeys []*ecdsa.PublicKey,
+	caseIdentifier []byte,
+	challenge []byte,
+) (int, *AuthorshipProof) {
+
+	if len(challenge) == 0 {
+		return EmptyChallenge, nil
+	}
+	if findPublicKey(publicKeys, privateKey.X.Bytes(), privateKey.Y.Bytes()) == -1 {
+		return PrivateKeyNotFoundAmongPublicKeys, nil
+	}
+	status, fc, h := signatureContext(sign, publicKeys, caseIdentifier)
+	if status != Success {
+		return status, nil
+	}
+	keyImage := fc.PointScalarMult(h, privateKey.D.Bytes())
+	if !bytes.Equal(keyImage.Bytes(), sign.KeyImage.Bytes()) {
+		return PrivateKeyNotSigner, nil
+	}
+	y := Point{privateKey.X, privateKey.Y}
+	c, s := fc.ProveEqualDiscreteLogs(fc.Generator(), y, h, keyImage, privateKey.D, challenge)
+
+	proof := AuthorshipProof{
+		Name:      Origin + " Authorship proof",
+		Version:   SignatureVersion,
+		CurveOID:  sign.CurveOID,
+		HasherOID: sign.HasherOID,
+		PublicKey: y.PointData(),
+		KeyImage:  sign.KeyImage,
+		Challenge: challenge,
+		Checksum:  c,
+		Response:  s,
+	}
+	return Success, &proof
+}
+
+// VerifyAuthorship verifies the signature of the message and proof that the owner of the public key
+// in the proof made it.
+func VerifyAuthorship(
+	proof *AuthorshipProof,
+	sign *Signature,
+	publicKeys []*ecdsa.PublicKey,
+	message []byte,
+	caseIdentifier []byte,
+	challenge []byte,
+) int {
+
+	if len(challenge) == 0 {
+		return EmptyChallenge
+	}
+	if !bytes.Equal(proof.Challenge, challenge) {
+		return ChallengeMismatch
+	}
+	if status := Verify(sign, publicKeys, message, caseIdentifier); status != Success {
+		return status
+	}
+	if !proof.CurveOID.Equal(sign.CurveOID) {
+		return UnexpectedCurveType
+	}
+	if !proof.HasherOID.Equal(sign.HasherOID) {
+		return UnexpectedHashType
+	}
+	if !bytes.Equal(proof.KeyImage.Bytes(), sign.KeyImage.Bytes()) {
+		return InvalidKeyImage
+	}
+	position := findPublicKey(publicKeys, proof.PublicKey.X, proof.PublicKey.Y)
+	if position == -1 {
+		return PublicKeyNotFoundAmongPublicKeys
+	}
+	status, fc, h := signatureContext(sign, publicKeys, caseIdentifier)
+	if status != Success {
+		return status
+	}
+	y := Point{publicKeys[position].X, publicKeys[position].Y}
+	if !fc.VerifyEqualDiscreteLogs(fc.Generator(), y, h, sign.KeyImage.Point(), proof.Checksum, proof.Response, challenge) {
+		return InvalidProof
+	}
+	return Success
+}
@@ -0,0 +1,120 @@
+package ring
+
+import (
+	"crypto/elliptic"
+	"hash"
+	"testing"
+
+	"golang.org/x/crypto/sha3"
+)
+
+var challenge = []byte(`fresh challenge of the verifier`)
+
+func TestProveAuthorship(t *testing.T) {
+	t.Parallel()
+	testAllCurvesAndHashers(t, func(t *testing.T, curve func() elliptic.Curve, hasher func() hash.Hash, size int, priv int) {
+		privateKeys, publicKeys := createPrivatePublicKeys(curve, size)
+		caseIdentifier := []byte(`case`)
+		status, sign := Create(curve, hasher, privateKeys[priv], publicKeys, message, caseIdentifier)
+		if status != Success {
+			t.Fatal(status)
+		}
+		status, proof := ProveAuthorship(privateKeys[priv], sign, publicKeys, caseIdentifier, challenge)
+		if status != Success {
+			t.Fatal(status)
+		}
+		if VerifyAuthorship(proof, sign, publicKeys, message, caseIdentifier, challenge) != Success {
+			t.Error("Authorship proof is not valid.")
+		}
+	})
+}
+
+func TestProveAuthorshipNotSigner(t *testing.T) {
+	t.Parallel()
+	curve := elliptic.P256
+	privateKeys, publicKeys := createPrivatePublicKeys(curve, 3)
+	status, sign := Create(curve, sha3.New256, privateKeys[0], publicKeys, message, []byte(``))
+	if status != Success {
+		t.Fatal(status)
+	}
+	status, _ = ProveAuthorship(privateKeys[1], sign, publicKeys, []byte(``), challenge)
+	if status != PrivateKeyNotSigner {
+		t.Error("Expected PrivateKeyNotSigner.")
+	}
+}
+
+func TestVerifyAuthorshipReplay(t *testing.T) {
+	t.Parallel()
+	curve := elliptic.P256
+	privateKeys, publicKeys := createPrivatePublicKeys(curve, 3)
+	status, sign := Create(curve, sha3.New256, privateKeys[0], publicKeys, message, []byte(``))
+	if status != Success {
+		t.Fatal(status)
+	}
+	status, proof := ProveAuthorship(privateKeys[0], sign, publicKeys, []byte(``), challenge)
+	if status != Success {
+		t.Fatal(status)
+	}
+	if VerifyAuthorship(proof, sign, publicKeys, message, []byte(``), []byte(`another challenge`)) != ChallengeMismatch {
+		t.Error("Proof accepted for another challenge.")
+	}
+	proof.Challenge = []byte(`another challenge`)
+	if VerifyAuthorship(proof, sign, publicKeys, message, []byte(``), []byte(`another challenge`)) != InvalidProof {
+		t.Error("Proof with replaced challenge accepted.")
+	}
+}
+
+func TestVerifyAuthorshipOtherPublicKey(t *testing.T) {
+	t.Parallel()
+	curve := elliptic.P256
+	privateKeys, publicKeys := createPrivatePublicKeys(curve, 3)
+	status, sign := Create(curve, sha3.New256, privateKeys[0], publicKeys, message, []byte(``))
+	if status != Success {
+		t.Fatal(status)
+	}
+	status, proof := ProveAuthorship(privateKeys[0], sign, publicKeys, []byte(``), challenge)
+	if status != Success {
+		t.Fatal(status)
+	}
+	proof.PublicKey = PointData{X: publicKeys[1].X.Bytes(), Y: publicKeys[1].Y.Bytes()}
+	if VerifyAuthorship(proof, sign, publicKeys, message, []byte(``), challenge) != InvalidProof {
+		t.Error("Proof accepted for another public key.")
+	}
+}
+
+func TestVerifyAuthorshipOtherMessage(t *testing.T) {
+	t.Parallel()
+	curve := elliptic.P256
+	privateKeys, publicKeys := createPrivatePublicKeys(curve, 3)
+	status, sign := Create(curve, sha3.New256, privateKeys[0], publicKeys, message, []byte(``))
+	if status != Success {
+		t.Fatal(status)
+	}
+	status, proof := ProveAuthorship(privateKeys[0], sign, publicKeys, []byte(``), challenge)
+	if status != Success {
+		t.Fatal(status)
+	}
+	if VerifyAuthorship(proof, sign, publicKeys, []byte(`another message`), []byte(``), challenge) != IncorrectChecksum {
+		t.Error("Proof accepted for the signature of another message.")
+	}
+	forged := *sign
+	forged.Checksum = append([]byte{}, sign.Checksum...)
+	forged.Checksum[0] ^= 1
+	if VerifyAuthorship(proof, &forged, publicKeys, message, []byte(``), challenge) == Success {
+		t.Error("Proof accepted for an invalid signature with the key image of the signer.")
+	}
+}
+
+func TestProveAuthorshipEmptyChallenge(t *testing.T) {
+	t.Parallel()
+	curve := elliptic.P256
+	privateKeys, publicKeys := createPrivatePublicKeys(curve, 3)
+	status, sign := Create(curve, sha3.New256, privateKeys[0], publicKeys, message, []byte(``))
+	if status != Success {
+		t.Fatal(status)
+	}
+	status, _ = ProveAuthorship(privateKeys[0], sign, publicKeys, []byte(``), []byte(``))
+	if status != EmptyChallenge {
+		t.Error("Expected EmptyChallenge.")
+	}
+}
@@ -0,0 +1,262 @@
+// # Borromean ring signature over several rings.
+
+// The signer proves membership in several rings at once. Every ring has its own
+// chain of LSAG challenges, but all chains are closed by one common checksum
+// *e<sub>0</sub>*, as in the Borromean ring signatures of G. Maxwell and A. Poelstra.
+// Each ring keeps its own key image, so the signature stays linkable per ring.
+//
+// ```
+// MultiSignature DEFINITIONS ::= BEGIN
+//     Name       ::= OCTET STRING,
+//     Version    ::= INTEGER,
+//     CurveOID   ::= OBJECT IDENTIFIER,
+//     HashOID    ::= OBJECT IDENTIFIER,
+//     KeyImages  ::= SEQUENCE OF PointData,
+//     Checksum   ::= INTEGER,
+//     Signatures ::= SEQUENCE OF SEQUENCE OF INTEGER
+// END
+// ```
+
+package ring
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"encoding/binary"
+	"hash"
+	"math/big"
+)
+
+// getRingsDigest returns digest binding all rings, key images and message together.
+func (fc FactoryContext) getRingsDigest(rings [][]Point, keyImages []Point, messageDigest []byte) []byte {
+	buff := []byte{}
+	for _, L := range rings {
+		buff = append(buff, fc.MakeDigest(PointsToBytes(L))...)
+	}
+	buff = append(buff, PointsToBytes(keyImages)...)
+	buff = append(buff, messageDigest...)
+	return fc.MakeDigest(buff)
+}
+
+// getChainDigest returns challenge for the position of the ring.
+func (fc FactoryContext) getChainDigest(ringsDigest []byte, ringIndex, position int, z1, z2 Point) []byte {
+	index := make([]byte, 8)
+	binary.BigEndian.PutUint32(index, uint32(ringIndex))
+	binary.BigEndian.PutUint32(index[4:], uint32(position))
+	buff := append([]byte{}, ringsDigest...)
+	buff = append(buff, index...)
+	buff = append(buff, z1.Bytes()...)
+	buff = append(buff, z2.Bytes()...)
+	return fc.MakeDigest(buff)
+}
+
+// getClosingDigest returns common checksum e0 from the last points of all rings.
+func (fc FactoryContext) getClosingDigest(ringsDigest []byte, lastPoints []Point) []byte {
+	buff := append([]byte{}, ringsDigest...)
+	buff = append(buff, PointsToBytes(lastPoints)...)
+	return fc.MakeDigest(buff)
+}
+
+// CreateMulti makes Borromean ring signature. Private key privateKeys[i] must be among publicKeys[i].
+func CreateMulti(
+	curve func() elliptic.Curve,
+	hasher func() hash.Hash,
+	privateKeys []*ecdsa.PrivateKey,
+	rings [][]*ecdsa.PublicKey,
+	message []byte,
+	caseIdentifier []byte,
+) (int, *MultiSignature) {
+
+	if !CurveHashSupportedCombination(curve, hasher) {
+		return UnsupportedCurveHashCombination, nil
+	}
+	if len(rings) == 0 || len(rings) != len(privateKeys) {
+		return IncorrectNumberOfRings, nil
+	}
+
+	curveOID, status := GetCurveOID(curve)
+	if status != Success {
+		return status, nil
+	}
+	hasherOID, status := GetHasherOID(hasher)
+	if status != Success {
+		return status, nil
+	}
+
+	fc := FactoryContext{Curve: curve(), Hasher: hasher}
+	params := fc.Curve.Params()
+	q := params.N
+	G := Point{params.Gx, params.Gy}
+
+	positions := make([]int, len(rings))
+	for i, publicKeys := range rings {
+		if len(publicKeys) < 2 {
+			return InsufficientNumberOfPublicKeys, nil
+		}
+		positions[i] = -1
+		for p, pub := range publicKeys {
+			if pub.Curve != fc.Curve {
+				return UnexpectedCurveType, nil
+			}
+			if pub.X.Cmp(privateKeys[i].X) == 0 && pub.Y.Cmp(privateKeys[i].Y) == 0 {
+				positions[i] = p
+			}
+		}
+		if positions[i] == -1 {
+			return PrivateKeyNotFoundAmongPublicKeys, nil
+		}
+	}
+
+	// ### Step 1
+	// For every ring *L<sub>i</sub>* compute *h<sub>i</sub> = H<sub>2</sub>(L<sub>i</sub>)* and
+	// *ỹ<sub>i</sub> = h<sub>i</sub><sup>x<sub>i</sub></sup>*.
+
+	m := fc.MakeDigest(message)
+	L := make([][]Point, len(rings))
+	h := make([]Point, len(rings))
+	y := make([]Point, len(rings))
+	for i, publicKeys := range rings {
+		L[i] = ConvertPublicKeysToPoints(publicKeys)
+		h[i] = fc.HashPublicKeysIntoPoint(L[i], caseIdentifier)
+		if h[i].x == nil {
+			return PointWasNotFound, nil
+		}
+		y[i] = fc.PointScalarMult(h[i], privateKeys[i].D.Bytes())
+	}
+	digest := fc.getRingsDigest(L, y, m)
+
+	// ### Step 2
+	// For every ring pick *u<sub>i</sub>* and continue the chain from position π<sub>i</sub>+1
+	// to the end of the ring. The last points of all rings are hashed into *e<sub>0</sub>*.
+
+	u := make([][]byte, len(rings))
+	c := make([][][]byte, len(rings))
+	s := make([][][]byte, len(rings))
+	lastPoints := make([]Point, 2*len(rings))
+
+	for i := range rings {
+		n := len(L[i])
+		π := positions[i]
+		c[i] = make([][]byte, n)
+		s[i] = make([][]byte, n)
+		u[i] = getRandomBytes(q)
+		z1 := fc.PointScalarMult(G, u[i])
+		z2 := fc.PointScalarMult(h[i], u[i])
+		for j := π + 1; j < n; j++ {
+			c[i][j] = fc.getChainDigest(digest, i, j, z1, z2)
+			s[i][j] = getRandomBytes(q)
+			z1 = fc.PointAdd(fc.PointScalarMult(G, s[i][j]), fc.PointScalarMult(L[i][j], c[i][j]))
+			z2 = fc.PointAdd(fc.PointScalarMult(h[i], s[i][j]), fc.PointScalarMult(y[i], c[i][j]))
+		}
+		lastPoints[2*i], lastPoints[2*i+1] = z1, z2
+	}
+
+	e0 := fc.getClosingDigest(digest, lastPoints)
+
+	// ### Step 3
+	// For every ring start the chain by *e<sub>0</sub>* and close it at position π<sub>i</sub>
+	// by *s<sub>π</sub>* = *u − x<sub>π</sub>c<sub>π</sub>* mod *q*.
+
+	for i := range rings {
+		π := positions[i]
+		c[i][0] = e0
+		for j := 0; j < π; j++ {
+			s[i][j] = getRandomBytes(q)
+			z1 := fc.PointAdd(fc.PointScalarMult(G, s[i][j]), fc.PointScalarMult(L[i][j], c[i][j]))
+			z2 := fc.PointAdd(fc.PointScalarMult(h[i], s[i][j]), fc.PointScalarMult(y[i], c[i][j]))
+			c[i][j+1] = fc.getChainDigest(digest, i, j+1, z1, z2)
+		}
+		xπ := privateKeys[i].D
+		s[i][π] = new(big.Int).Mod(new(big.Int).Sub(BuffToInt(u[i]), new(big.Int).Mul(xπ, BuffToInt(c[i][π]))), q).Bytes()
+	}
+
+	keyImages := make([]PointData, len(rings))
+	for i, point := range y {
+		keyImages[i] = PointData{X: point.x.Bytes(), Y: point.y.Bytes()}
+	}
+
+	sign := MultiSignature{
+		Name:       Origin + " Multi-ring Signature",
+		Version:    SignatureVersion,
+		CurveOID:   curveOID,
+		HasherOID:  hasherOID,
+		KeyImages:  keyImages,
+		Checksum:   e0,
+		Signatures: s,
+	}
+	return Success, &sign
+}
+
+// VerifyMulti verifies Borromean ring signature.
+func VerifyMulti(sign *MultiSignature, rings [][]*ecdsa.PublicKey, message []byte, caseIdentifier []byte) int {
+
+	if len(rings) == 0 || len(sign.Signatures) != len(rings) || len(sign.KeyImages) != len(rings) {
+		return IncorrectNumberOfRings
+	}
+
+	curve, ok := GetCurve(sign.CurveOID)
+	if !ok {
+		return OIDCurveNotFound
+	}
+	hasher, ok := GetHasher(sign.HasherOID)
+	if !ok {
+		return OIDHasherNotFound
+	}
+	if !CurveHashSupportedCombination(curve, hasher) {
+		return UnsupportedCurveHashCombination
+	}
+
+	fc := FactoryContext{Curve: curve(), Hasher: hasher}
+	params := fc.Curve.Params()
+	G := Point{params.Gx, params.Gy}
+
+	m := fc.MakeDigest(message)
+	L := make([][]Point, len(rings))
+	h := make([]Point, len(rings))
+	y := make([]Point, len(rings))
+
+	for i, publicKeys := range rings {
+		if len(sign.Signatures[i]) != len(publicKeys) {
+			return IncorrectNumberOfSignatures
+		}
+		for _, pub := range publicKeys {
+			if pub.Curve != fc.Curve {
+				return UnexpectedCurveType
+			}
+		}
+		kx, ky := BuffToInt(sign.KeyImages[i].X), BuffToInt(sign.KeyImages[i].Y)
+		if !fc.Curve.IsOnCurve(kx, ky) {
+			return InvalidKeyImage
+		}
+		y[i] = Point{kx, ky}
+		L[i] = ConvertPublicKeysToPoints(publicKeys)
+		h[i] = fc.HashPublicKeysIntoPoint(L[i], caseIdentifier)
+		if h[i].x == nil {
+			return PointWasNotFound
+		}
+	}
+	digest := fc.getRingsDigest(L, y, m)
+
+	// Walk every ring from *e<sub>0</sub>* to its end and check that the last points
+	// of all rings hash again into *e<sub>0</sub>*.
+
+	lastPoints := make([]Point, 2*len(rings))
+	for i := range rings {
+		var z1, z2 Point
+		c := sign.Checksum
+		for j, s := range sign.Signatures[i] {
+			if j > 0 {
+				c = fc.getChainDigest(digest, i, j, z1, z2)
+			}
+			z1 = fc.PointAdd(fc.PointScalarMult(G, s), fc.PointScalarMult(L[i][j], c))
+			z2 = fc.PointAdd(fc.PointScalarMult(h[i], s), fc.PointScalarMult(y[i], c))
+		}
+		lastPoints[2*i], lastPoints[2*i+1] = z1, z2
+	}
+
+	if bytes.Equal(sign.Checksum, fc.getClosingDigest(digest, lastPoints)) {
+		return Success
+	}
+	return IncorrectChecksum
+}
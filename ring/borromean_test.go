@@ -0,0 +1,108 @@
+package ring
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"hash"
+	"testing"
+
+	"golang.org/x/crypto/sha3"
+)
+
+func createRings(curve func() elliptic.Curve, sizes []int) ([]*ecdsa.PrivateKey, [][]*ecdsa.PublicKey) {
+	signers := make([]*ecdsa.PrivateKey, len(sizes))
+	rings := make([][]*ecdsa.PublicKey, len(sizes))
+	for i, size := range sizes {
+		privateKeys, publicKeys := createPrivatePublicKeys(curve, size)
+		signers[i] = privateKeys[i%size]
+		rings[i] = publicKeys
+	}
+	return signers, rings
+}
+
+func TestMultiSignVerify(t *testing.T) {
+	t.Parallel()
+	testAllCurvesAndHashers(t, func(t *testing.T, curve func() elliptic.Curve, hasher func() hash.Hash, size int, priv int) {
+		signers, rings := createRings(curve, []int{size, size + 1, 2})
+		caseIdentifier := []byte(`case`)
+		status, sign := CreateMulti(curve, hasher, signers, rings, message, caseIdentifier)
+		if status != Success {
+			t.Fatal(status)
+		}
+		if VerifyMulti(sign, rings, message, caseIdentifier) != Success {
+			t.Error("Multi signature is not valid.")
+		}
+	})
+}
+
+func TestMultiSignatureKeyImages(t *testing.T) {
+	t.Parallel()
+	curve := elliptic.P256
+	signers, rings := createRings(curve, []int{3, 4})
+	caseIdentifier := []byte(`case`)
+	status, multi := CreateMulti(curve, sha3.New256, signers, rings, message, caseIdentifier)
+	if status != Success {
+		t.Fatal(status)
+	}
+	for i := range rings {
+		status, sign := Create(curve, sha3.New256, signers[i], rings[i], message, caseIdentifier)
+		if status != Success {
+			t.Fatal(status)
+		}
+		if !bytes.Equal(multi.KeyImages[i].Bytes(), sign.KeyImage.Bytes()) {
+			t.Errorf("Key image of ring %d doesn't match the key image of ring signature.", i)
+		}
+	}
+}
+
+func TestMultiVerifyWrongRingOrder(t *testing.T) {
+	t.Parallel()
+	curve := elliptic.P256
+	signers, rings := createRings(curve, []int{3, 3})
+	status, sign := CreateMulti(curve, sha3.New256, signers, rings, message, []byte(``))
+	if status != Success {
+		t.Fatal(status)
+	}
+	swapped := [][]*ecdsa.PublicKey{rings[1], rings[0]}
+	if VerifyMulti(sign, swapped, message, []byte(``)) != IncorrectChecksum {
+		t.Error("Signature verified with swapped rings.")
+	}
+}
+
+func TestMultiVerifyDifferentMessage(t *testing.T) {
+	t.Parallel()
+	curve := elliptic.P256
+	signers, rings := createRings(curve, []int{3, 2})
+	status, sign := CreateMulti(curve, sha3.New256, signers, rings, message, []byte(``))
+	if status != Success {
+		t.Fatal(status)
+	}
+	if VerifyMulti(sign, rings, []byte(`Another message`), []byte(``)) != IncorrectChecksum {
+		t.Error("Signature verified with different message.")
+	}
+}
+
+func TestMultiVerifyMissingRing(t *testing.T) {
+	t.Parallel()
+	curve := elliptic.P256
+	signers, rings := createRings(curve, []int{3, 2})
+	status, sign := CreateMulti(curve, sha3.New256, signers, rings, message, []byte(``))
+	if status != Success {
+		t.Fatal(status)
+	}
+	if VerifyMulti(sign, rings[:1], message, []byte(``)) != IncorrectNumberOfRings {
+		t.Error("Expected IncorrectNumberOfRings.")
+	}
+}
+
+func TestMultiSignPrivateKeyNotInRing(t *testing.T) {
+	t.Parallel()
+	curve := elliptic.P256
+	signers, rings := createRings(curve, []int{3, 2})
+	signers[0], signers[1] = signers[1], signers[0]
+	status, _ := CreateMulti(curve, sha3.New256, signers, rings, message, []byte(``))
+	if status != PrivateKeyNotFoundAmongPublicKeys {
+		t.Error("Expected PrivateKeyNotFoundAmongPublicKeys.")
+	}
+}
@@ -0,0 +1,77 @@
+// # Proof of equality of discrete logarithms.
+
+// Chaum–Pedersen protocol made non-interactive by Fiat–Shamir heuristic.
+// The prover knows *x* such that *y<sub>1</sub> = g<sub>1</sub><sup>x</sup>* and
+// *y<sub>2</sub> = g<sub>2</sub><sup>x</sup>*.
+//
+// 1. Pick *r ∈<sub>R</sub> Z<sub>q</sub>*, compute *a = g<sub>1</sub><sup>r</sup>*, *b = g<sub>2</sub><sup>r</sup>*.
+// 2. Compute *c = H(g<sub>1</sub>, y<sub>1</sub>, g<sub>2</sub>, y<sub>2</sub>, a, b, context)*.
+// 3. Compute *s = r − xc* mod *q*.
+//
+// The verifier restores *a = g<sub>1</sub><sup>s</sup> y<sub>1</sub><sup>c</sup>*,
+// *b = g<sub>2</sub><sup>s</sup> y<sub>2</sub><sup>c</sup>* and checks the hash *c*.
+
+package ring
+
+import (
+	"bytes"
+	"math/big"
+)
+
+func (fc FactoryContext) getEqualityDigest(g1, y1, g2, y2, a, b Point, context []byte) []byte {
+	buff := PointsToBytes([]Point{g1, y1, g2, y2, a, b})
+	buff = append(buff, context...)
+	return fc.MakeDigest(buff)
+}
+
+// ProveEqualDiscreteLogs returns checksum and response of the proof that log_g1(y1) = log_g2(y2) = x.
+func (fc FactoryContext) ProveEqualDiscreteLogs(g1, y1, g2, y2 Point, x *big.Int, context []byte) ([]byte, []byte) {
+	q := fc.Curve.Params().N
+	r := getRandomBytes(q)
+	c := fc.getEqualityDigest(g1, y1, g2, y2, fc.PointScalarMult(g1, r), fc.PointScalarMult(g2, r), context)
+	s := new(big.Int).Mod(new(big.Int).Sub(BuffToInt(r), new(big.Int).Mul(x, BuffToInt(c))), q)
+	return c, s.Bytes()
+}
+
+// VerifyEqualDiscreteLogs verifies the proof that log_g1(y1) = log_g2(y2).
+func (fc FactoryContext) VerifyEqualDiscreteLogs(g1, y1, g2, y2 Point, c, s []byte, context []byte) bool {
+	a := fc.PointAdd(fc.PointScalarMult(g1, s), fc.PointScalarMult(y1, c))
+	b := fc.PointAdd(fc.PointScalarMult(g2, s), fc.PointScalarMult(y2, c))
+	return bytes.Equal(c, fc.getEqualityDigest(g1, y1, g2, y2, a, b, context))
+}
+
+// Generator returns generator point of the curve.
+func (fc FactoryContext) Generator() Point {
+	params := fc.Curve.Params()
+	return Point{params.Gx, params.Gy}
+}
+
+// NewPoint creates point from coordinates.
+func NewPoint(x, y *big.Int) Point {
+	return Point{x, y}
+}
+
+// Coordinates returns X,Y coordinates of the point.
+func (p Point) Coordinates() (*big.Int, *big.Int) {
+	return p.x, p.y
+}
+
+// PointData converts point to data for serialization.
+func (p Point) PointData() PointData {
+	return PointData{X: p.x.Bytes(), Y: p.y.Bytes()}
+}
+
+// Point converts serialized data to point.
+func (p PointData) Point() Point {
+	return Point{BuffToInt(p.X), BuffToInt(p.Y)}
+}
+
+// PointNeg returns inverse point -p.
+func (fc FactoryContext) PointNeg(p Point) Point {
+	return Point{p.x, new(big.Int).Mod(new(big.Int).Neg(p.y), fc.Curve.Params().P)}
+}
+
+// IsInfinity returns true for the point at infinity.
+func (p Point) IsInfinity() bool {
+	return p.x == nil || (p.x.Sign() == 0 && p.y.Sign() == 0)
+}
@@ -95,3 +95,19 @@ func GetCurveOIDForCurve(curve elliptic.Curve) (asn1.ObjectIdentifier, int) {
 	}
 	return asn1.ObjectIdentifier{}, OIDCurveNotFound
 }
+
+// GetFactoryContext returns factory context for OIDs of curve and hash function.
+func GetFactoryContext(curveOID, hasherOID asn1.ObjectIdentifier) (int, FactoryContext) {
+	curve, ok := GetCurve(curveOID)
+	if !ok {
+		return OIDCurveNotFound, FactoryContext{}
+	}
+	hasher, ok := GetHasher(hasherOID)
+	if !ok {
+		return OIDHasherNotFound, FactoryContext{}
+	}
+	if !CurveHashSupportedCombination(curve, hasher) {
+		return UnsupportedCurveHashCombination, FactoryContext{}
+	}
+	return Success, FactoryContext{Curve: curve(), Hasher: hasher}
+}
@@ -0,0 +1,130 @@
+// # Non-authorship (repudiation) proof.
+
+// A member of the ring with the key pair *(x, y = g<sup>x</sup>)* proves that the member's key image
+// *t = h<sup>x</sup>* differs from the key image *ỹ* of the signature without revealing *t*.
+// It is the proof of inequality of discrete logarithms by J. Camenisch and V. Shoup.
+//
+// 1. Pick *r ∈<sub>R</sub> Z<sub>q</sub>* and compute the commitment *C = (t / ỹ)<sup>r</sup> = h<sup>α</sup> ỹ<sup>β</sup>*,
+// where *α = xr* and *β = −r*. Then also *g<sup>α</sup> y<sup>β</sup> = 1*.
+// 2. Prove knowledge of *α, β* satisfying both equations.
+//
+// The verifier checks the proof and *C ≠ 1*. If *t = ỹ*, the commitment is always *1*.
+
+package ring
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"math/big"
+)
+
+func (fc FactoryContext) getRepudiationDigest(points []Point) []byte {
+	return fc.MakeDigest(PointsToBytes(points))
+}
+
+// ProveNotSigner creates proof that the private key did not make the signature.
+func ProveNotSigner(
+	privateKey *ecdsa.PrivateKey,
+	sign *Signature,
+	publicKeys []*ecdsa.PublicKey,
+	caseIdentifier []byte,
+) (int, *NonAuthorshipProof) {
+
+	if findPublicKey(publicKeys, privateKey.X.Bytes(), privateKey.Y.Bytes()) == -1 {
+		return PrivateKeyNotFoundAmongPublicKeys, nil
+	}
+	status, fc, h := signatureContext(sign, publicKeys, caseIdentifier)
+	if status != Success {
+		return status, nil
+	}
+	q := fc.Curve.Params().N
+	G := fc.Generator()
+	y := Point{privateKey.X, privateKey.Y}
+	keyImage := sign.KeyImage.Point()
+
+	t := fc.PointScalarMult(h, privateKey.D.Bytes())
+	if bytes.Equal(t.Bytes(), keyImage.Bytes()) {
+		return PrivateKeyIsSigner, nil
+	}
+
+	r := BuffToInt(getRandomBytes(q))
+	α := new(big.Int).Mod(new(big.Int).Mul(privateKey.D, r), q)
+	β := new(big.Int).Mod(new(big.Int).Neg(r), q)
+	C := fc.PointAdd(fc.PointScalarMult(h, α.Bytes()), fc.PointScalarMult(keyImage, β.Bytes()))
+
+	k1 := getRandomBytes(q)
+	k2 := getRandomBytes(q)
+	T1 := fc.PointAdd(fc.PointScalarMult(h, k1), fc.PointScalarMult(keyImage, k2))
+	T2 := fc.PointAdd(fc.PointScalarMult(G, k1), fc.PointScalarMult(y, k2))
+
+	c := fc.getRepudiationDigest([]Point{G, y, h, keyImage, C, T1, T2})
+	z1 := new(big.Int).Mod(new(big.Int).Add(BuffToInt(k1), new(big.Int).Mul(BuffToInt(c), α)), q)
+	z2 := new(big.Int).Mod(new(big.Int).Add(BuffToInt(k2), new(big.Int).Mul(BuffToInt(c), β)), q)
+
+	proof := NonAuthorshipProof{
+		Name:       Origin + " Non-authorship proof",
+		Version:    SignatureVersion,
+		CurveOID:   sign.CurveOID,
+		HasherOID:  sign.HasherOID,
+		PublicKey:  y.PointData(),
+		KeyImage:   sign.KeyImage,
+		Commitment: C.PointData(),
+		Checksum:   c,
+		Responses:  [][]byte{z1.Bytes(), z2.Bytes()},
+	}
+	return Success, &proof
+}
+
+// VerifyNotSigner verifies the signature of the message and proof that the owner of the public key
+// in the proof did not make it.
+func VerifyNotSigner(
+	proof *NonAuthorshipProof,
+	sign *Signature,
+	publicKeys []*ecdsa.PublicKey,
+	message []byte,
+	caseIdentifier []byte,
+) int {
+
+	if !proof.CurveOID.Equal(sign.CurveOID) {
+		return UnexpectedCurveType
+	}
+	if !proof.HasherOID.Equal(sign.HasherOID) {
+		return UnexpectedHashType
+	}
+	if !bytes.Equal(proof.KeyImage.Bytes(), sign.KeyImage.Bytes()) {
+		return InvalidKeyImage
+	}
+	if len(proof.Responses) != 2 {
+		return InvalidProof
+	}
+	if status := Verify(sign, publicKeys, message, caseIdentifier); status != Success {
+		return status
+	}
+	position := findPublicKey(publicKeys, proof.PublicKey.X, proof.PublicKey.Y)
+	if position == -1 {
+		return PublicKeyNotFoundAmongPublicKeys
+	}
+	status, fc, h := signatureContext(sign, publicKeys, caseIdentifier)
+	if status != Success {
+		return status
+	}
+	C := proof.Commitment.Point()
+	if C.IsInfinity() || !fc.Curve.IsOnCurve(C.x, C.y) {
+		return InvalidProof
+	}
+	G := fc.Generator()
+	y := Point{publicKeys[position].X, publicKeys[position].Y}
+	keyImage := sign.KeyImage.Point()
+	z1, z2, c := proof.Responses[0], proof.Responses[1], proof.Checksum
+
+	T1 := fc.PointAdd(
+		fc.PointAdd(fc.PointScalarMult(h, z1), fc.PointScalarMult(keyImage, z2)),
+		fc.PointNeg(fc.PointScalarMult(C, c)),
+	)
+	T2 := fc.PointAdd(fc.PointScalarMult(G, z1), fc.PointScalarMult(y, z2))
+
+	if !bytes.Equal(c, fc.getRepudiationDigest([]Point{G, y, h, keyImage, C, T1, T2})) {
+		return InvalidProof
+	}
+	return Success
+}
@@ -0,0 +1,114 @@
+package ring
+
+import (
+	"crypto/elliptic"
+	"hash"
+	"testing"
+
+	"golang.org/x/crypto/sha3"
+)
+
+func TestProveNotSigner(t *testing.T) {
+	t.Parallel()
+	testAllCurvesAndHashers(t, func(t *testing.T, curve func() elliptic.Curve, hasher func() hash.Hash, size int, priv int) {
+		privateKeys, publicKeys := createPrivatePublicKeys(curve, size)
+		caseIdentifier := []byte(`case`)
+		status, sign := Create(curve, hasher, privateKeys[priv], publicKeys, message, caseIdentifier)
+		if status != Success {
+			t.Fatal(status)
+		}
+		for i, privateKey := range privateKeys {
+			status, proof := ProveNotSigner(privateKey, sign, publicKeys, caseIdentifier)
+			if i == priv {
+				if status != PrivateKeyIsSigner {
+					t.Error("Signer made non-authorship proof.")
+				}
+				continue
+			}
+			if status != Success {
+				t.Fatal(status)
+			}
+			if VerifyNotSigner(proof, sign, publicKeys, message, caseIdentifier) != Success {
+				t.Error("Non-authorship proof is not valid.")
+			}
+		}
+	})
+}
+
+func TestVerifyNotSignerOtherPublicKey(t *testing.T) {
+	t.Parallel()
+	curve := elliptic.P256
+	privateKeys, publicKeys := createPrivatePublicKeys(curve, 3)
+	status, sign := Create(curve, sha3.New256, privateKeys[0], publicKeys, message, []byte(``))
+	if status != Success {
+		t.Fatal(status)
+	}
+	status, proof := ProveNotSigner(privateKeys[1], sign, publicKeys, []byte(``))
+	if status != Success {
+		t.Fatal(status)
+	}
+	proof.PublicKey = PointData{X: publicKeys[0].X.Bytes(), Y: publicKeys[0].Y.Bytes()}
+	if VerifyNotSigner(proof, sign, publicKeys, message, []byte(``)) != InvalidProof {
+		t.Error("Proof accepted for the public key of the signer.")
+	}
+}
+
+func TestVerifyNotSignerOtherMessage(t *testing.T) {
+	t.Parallel()
+	curve := elliptic.P256
+	privateKeys, publicKeys := createPrivatePublicKeys(curve, 3)
+	status, sign := Create(curve, sha3.New256, privateKeys[0], publicKeys, message, []byte(``))
+	if status != Success {
+		t.Fatal(status)
+	}
+	status, proof := ProveNotSigner(privateKeys[1], sign, publicKeys, []byte(``))
+	if status != Success {
+		t.Fatal(status)
+	}
+	if VerifyNotSigner(proof, sign, publicKeys, []byte(`another message`), []byte(``)) != IncorrectChecksum {
+		t.Error("Proof accepted for the signature of another message.")
+	}
+}
+
+func TestVerifyNotSignerOtherSignature(t *testing.T) {
+	t.Parallel()
+	curve := elliptic.P256
+	privateKeys, publicKeys := createPrivatePublicKeys(curve, 3)
+	status, sign1 := Create(curve, sha3.New256, privateKeys[0], publicKeys, message, []byte(``))
+	if status != Success {
+		t.Fatal(status)
+	}
+	status, sign2 := Create(curve, sha3.New256, privateKeys[2], publicKeys, message, []byte(``))
+	if status != Success {
+		t.Fatal(status)
+	}
+	status, proof := ProveNotSigner(privateKeys[1], sign1, publicKeys, []byte(``))
+	if status != Success {
+		t.Fatal(status)
+	}
+	if VerifyNotSigner(proof, sign2, publicKeys, message, []byte(``)) != InvalidKeyImage {
+		t.Error("Proof accepted for another signature.")
+	}
+	proof.KeyImage = sign2.KeyImage
+	if VerifyNotSigner(proof, sign2, publicKeys, message, []byte(``)) != InvalidProof {
+		t.Error("Proof with replaced key image accepted.")
+	}
+}
+
+func TestVerifyNotSignerInfinityCommitment(t *testing.T) {
+	t.Parallel()
+	curve := elliptic.P256
+	privateKeys, publicKeys := createPrivatePublicKeys(curve, 3)
+	status, sign := Create(curve, sha3.New256, privateKeys[0], publicKeys, message, []byte(``))
+	if status != Success {
+		t.Fatal(status)
+	}
+	status, proof := ProveNotSigner(privateKeys[1], sign, publicKeys, []byte(``))
+	if status != Success {
+		t.Fatal(status)
+	}
+	proof.Commitment = PointData{X: []byte{0}, Y: []byte{0}}
+	if VerifyNotSigner(proof, sign, publicKeys, message, []byte(``)) != InvalidProof {
+		t.Error("Proof with commitment at infinity accepted.")
+	}
+}
@@ -0,0 +1,152 @@
+// # Proof that two key images belong to the same signer.
+
+// Signatures made under different case identifiers (or rings) have different key images
+// *ỹ<sub>1</sub> = h<sub>1</sub><sup>x</sup>* and *ỹ<sub>2</sub> = h<sub>2</sub><sup>x</sup>*, so they are not linkable.
+// The signer can link them voluntarily by the proof
+// *log<sub>h<sub>1</sub></sub>(ỹ<sub>1</sub>) = log<sub>h<sub>2</sub></sub>(ỹ<sub>2</sub>)*
+// without revealing which member of the ring made them. Both signatures are verified against their messages,
+// so the proof links the messages too.
+
+package ring
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"encoding/asn1"
+)
+
+// linkContext returns the statements of both signatures serialized for the proof digest.
+func linkContext(first, second LinkStatement) []byte {
+	content, err := asn1.Marshal([]LinkStatement{first, second})
+	if err != nil {
+		panic(err)
+	}
+	return content
+}
+
+// ProveSameSigner creates proof that the private key made both signatures of the messages.
+func ProveSameSigner(
+	privateKey *ecdsa.PrivateKey,
+	firstSign *Signature,
+	firstPublicKeys []*ecdsa.PublicKey,
+	firstMessage []byte,
+	firstCase []byte,
+	secondSign *Signature,
+	secondPublicKeys []*ecdsa.PublicKey,
+	secondMessage []byte,
+	secondCase []byte,
+) (int, *SameSignerProof) {
+
+	if !firstSign.CurveOID.Equal(secondSign.CurveOID) {
+		return UnexpectedCurveType, nil
+	}
+	if !firstSign.HasherOID.Equal(secondSign.HasherOID) {
+		return UnexpectedHashType, nil
+	}
+	if status := Verify(firstSign, firstPublicKeys, firstMessage, firstCase); status != Success {
+		return status, nil
+	}
+	if status := Verify(secondSign, secondPublicKeys, secondMessage, secondCase); status != Success {
+		return status, nil
+	}
+	status, fc, h1 := signatureContext(firstSign, firstPublicKeys, firstCase)
+	if status != Success {
+		return status, nil
+	}
+	status, _, h2 := signatureContext(secondSign, secondPublicKeys, secondCase)
+	if status != Success {
+		return status, nil
+	}
+	y1 := fc.PointScalarMult(h1, privateKey.D.Bytes())
+	y2 := fc.PointScalarMult(h2, privateKey.D.Bytes())
+	if !bytes.Equal(y1.Bytes(), firstSign.KeyImage.Bytes()) || !bytes.Equal(y2.Bytes(), secondSign.KeyImage.Bytes()) {
+		return PrivateKeyNotSigner, nil
+	}
+
+	first := LinkStatement{
+		RingDigest:    fc.PublicKeysDigest(firstPublicKeys),
+		Case:          firstCase,
+		MessageDigest: fc.MakeDigest(firstMessage),
+		KeyImage:      firstSign.KeyImage,
+	}
+	second := LinkStatement{
+		RingDigest:    fc.PublicKeysDigest(secondPublicKeys),
+		Case:          secondCase,
+		MessageDigest: fc.MakeDigest(secondMessage),
+		KeyImage:      secondSign.KeyImage,
+	}
+	c, s := fc.ProveEqualDiscreteLogs(h1, y1, h2, y2, privateKey.D, linkContext(first, second))
+
+	proof := SameSignerProof{
+		Name:      Origin + " Same signer proof",
+		Version:   SignatureVersion,
+		CurveOID:  firstSign.CurveOID,
+		HasherOID: firstSign.HasherOID,
+		First:     first,
+		Second:    second,
+		Checksum:  c,
+		Response:  s,
+	}
+	return Success, &proof
+}
+
+// checkLinkStatement returns Success if the statement describes the signature and the signature of the message is valid.
+func checkLinkStatement(
+	fc FactoryContext,
+	statement LinkStatement,
+	sign *Signature,
+	publicKeys []*ecdsa.PublicKey,
+	message []byte,
+	caseIdentifier []byte,
+) int {
+	if !bytes.Equal(statement.RingDigest, fc.PublicKeysDigest(publicKeys)) ||
+		!bytes.Equal(statement.Case, caseIdentifier) ||
+		!bytes.Equal(statement.MessageDigest, fc.MakeDigest(message)) ||
+		!bytes.Equal(statement.KeyImage.Bytes(), sign.KeyImage.Bytes()) {
+		return LinkStatementMismatch
+	}
+	return Verify(sign, publicKeys, message, caseIdentifier)
+}
+
+// VerifySameSigner verifies both signatures of the messages and proof that they were made by the same private key.
+func VerifySameSigner(
+	proof *SameSignerProof,
+	firstSign *Signature,
+	firstPublicKeys []*ecdsa.PublicKey,
+	firstMessage []byte,
+	firstCase []byte,
+	secondSign *Signature,
+	secondPublicKeys []*ecdsa.PublicKey,
+	secondMessage []byte,
+	secondCase []byte,
+) int {
+
+	for _, sign := range []*Signature{firstSign, secondSign} {
+		if !proof.CurveOID.Equal(sign.CurveOID) {
+			return UnexpectedCurveType
+		}
+		if !proof.HasherOID.Equal(sign.HasherOID) {
+			return UnexpectedHashType
+		}
+	}
+	status, fc, h1 := signatureContext(firstSign, firstPublicKeys, firstCase)
+	if status != Success {
+		return status
+	}
+	status, _, h2 := signatureContext(secondSign, secondPublicKeys, secondCase)
+	if status != Success {
+		return status
+	}
+	if status := checkLinkStatement(fc, proof.First, firstSign, firstPublicKeys, firstMessage, firstCase); status != Success {
+		return status
+	}
+	if status := checkLinkStatement(fc, proof.Second, secondSign, secondPublicKeys, secondMessage, secondCase); status != Success {
+		return status
+	}
+	y1 := firstSign.KeyImage.Point()
+	y2 := secondSign.KeyImage.Point()
+	if !fc.VerifyEqualDiscreteLogs(h1, y1, h2, y2, proof.Checksum, proof.Response, linkContext(proof.First, proof.Second)) {
+		return InvalidProof
+	}
+	return Success
+}
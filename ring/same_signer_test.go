@@ -0,0 +1,128 @@
+package ring
+
+import (
+	"crypto/elliptic"
+	"hash"
+	"testing"
+
+	"golang.org/x/crypto/sha3"
+)
+
+func TestProveSameSigner(t *testing.T) {
+	t.Parallel()
+	testAllCurvesAndHashers(t, func(t *testing.T, curve func() elliptic.Curve, hasher func() hash.Hash, size int, priv int) {
+		privateKeys, publicKeys := createPrivatePublicKeys(curve, size)
+		status, sign1 := Create(curve, hasher, privateKeys[priv], publicKeys, message, []byte(`first`))
+		if status != Success {
+			t.Fatal(status)
+		}
+		status, sign2 := Create(curve, hasher, privateKeys[priv], publicKeys, message, []byte(`second`))
+		if status != Success {
+			t.Fatal(status)
+		}
+		status, proof := ProveSameSigner(privateKeys[priv], sign1, publicKeys, message, []byte(`first`), sign2, publicKeys, message, []byte(`second`))
+		if status != Success {
+			t.Fatal(status)
+		}
+		if VerifySameSigner(proof, sign1, publicKeys, message, []byte(`first`), sign2, publicKeys, message, []byte(`second`)) != Success {
+			t.Error("Same signer proof is not valid.")
+		}
+	})
+}
+
+func TestProveSameSignerDifferentRings(t *testing.T) {
+	t.Parallel()
+	curve := elliptic.P256
+	privateKeys, publicKeys1 := createPrivatePublicKeys(curve, 3)
+	_, others := createPrivatePublicKeys(curve, 2)
+	publicKeys2 := append(others, publicKeys1[1])
+	status, sign1 := Create(curve, sha3.New256, privateKeys[1], publicKeys1, message, []byte(`first`))
+	if status != Success {
+		t.Fatal(status)
+	}
+	status, sign2 := Create(curve, sha3.New256, privateKeys[1], publicKeys2, message, []byte(`second`))
+	if status != Success {
+		t.Fatal(status)
+	}
+	status, proof := ProveSameSigner(privateKeys[1], sign1, publicKeys1, message, []byte(`first`), sign2, publicKeys2, message, []byte(`second`))
+	if status != Success {
+		t.Fatal(status)
+	}
+	if VerifySameSigner(proof, sign1, publicKeys1, message, []byte(`first`), sign2, publicKeys2, message, []byte(`second`)) != Success {
+		t.Error("Same signer proof is not valid.")
+	}
+	if VerifySameSigner(proof, sign1, publicKeys1, message, []byte(`first`), sign2, publicKeys1, message, []byte(`second`)) != LinkStatementMismatch {
+		t.Error("Proof accepted for another ring.")
+	}
+}
+
+func TestProveSameSignerDifferentSigners(t *testing.T) {
+	t.Parallel()
+	curve := elliptic.P256
+	privateKeys, publicKeys := createPrivatePublicKeys(curve, 3)
+	status, sign1 := Create(curve, sha3.New256, privateKeys[0], publicKeys, message, []byte(`first`))
+	if status != Success {
+		t.Fatal(status)
+	}
+	status, sign2 := Create(curve, sha3.New256, privateKeys[1], publicKeys, message, []byte(`second`))
+	if status != Success {
+		t.Fatal(status)
+	}
+	status, _ = ProveSameSigner(privateKeys[0], sign1, publicKeys, message, []byte(`first`), sign2, publicKeys, message, []byte(`second`))
+	if status != PrivateKeyNotSigner {
+		t.Error("Expected PrivateKeyNotSigner.")
+	}
+}
+
+func TestVerifySameSignerOtherMessages(t *testing.T) {
+	t.Parallel()
+	curve := elliptic.P256
+	privateKeys, publicKeys := createPrivatePublicKeys(curve, 3)
+	status, sign1 := Create(curve, sha3.New256, privateKeys[0], publicKeys, []byte(`complaint 1`), []byte(`first`))
+	if status != Success {
+		t.Fatal(status)
+	}
+	status, sign2 := Create(curve, sha3.New256, privateKeys[0], publicKeys, []byte(`complaint 2`), []byte(`second`))
+	if status != Success {
+		t.Fatal(status)
+	}
+	if status, _ := ProveSameSigner(privateKeys[0], sign1, publicKeys, []byte(`other`), []byte(`first`), sign2, publicKeys, []byte(`complaint 2`), []byte(`second`)); status != IncorrectChecksum {
+		t.Errorf("Proof made for the signature of another message with status %d.", status)
+	}
+	status, proof := ProveSameSigner(privateKeys[0], sign1, publicKeys, []byte(`complaint 1`), []byte(`first`), sign2, publicKeys, []byte(`complaint 2`), []byte(`second`))
+	if status != Success {
+		t.Fatal(status)
+	}
+	if VerifySameSigner(proof, sign1, publicKeys, []byte(`complaint 1`), []byte(`first`), sign2, publicKeys, []byte(`complaint 2`), []byte(`second`)) != Success {
+		t.Error("Same signer proof is not valid.")
+	}
+	if VerifySameSigner(proof, sign1, publicKeys, []byte(`complaint 1`), []byte(`first`), sign2, publicKeys, []byte(`other`), []byte(`second`)) != LinkStatementMismatch {
+		t.Error("Proof accepted for another message.")
+	}
+	proof.Second.MessageDigest = FactoryContext{Hasher: sha3.New256}.MakeDigest([]byte(`other`))
+	if VerifySameSigner(proof, sign1, publicKeys, []byte(`complaint 1`), []byte(`first`), sign2, publicKeys, []byte(`other`), []byte(`second`)) != IncorrectChecksum {
+		t.Error("Proof accepted for the signature of another message.")
+	}
+}
+
+func TestVerifySameSignerSwappedCases(t *testing.T) {
+	t.Parallel()
+	curve := elliptic.P256
+	privateKeys, publicKeys := createPrivatePublicKeys(curve, 3)
+	status, sign1 := Create(curve, sha3.New256, privateKeys[0], publicKeys, message, []byte(`first`))
+	if status != Success {
+		t.Fatal(status)
+	}
+	status, sign2 := Create(curve, sha3.New256, privateKeys[0], publicKeys, message, []byte(`second`))
+	if status != Success {
+		t.Fatal(status)
+	}
+	status, proof := ProveSameSigner(privateKeys[0], sign1, publicKeys, message, []byte(`first`), sign2, publicKeys, message, []byte(`second`))
+	if status != Success {
+		t.Fatal(status)
+	}
+	proof.First.Case, proof.Second.Case = proof.Second.Case, proof.First.Case
+	if VerifySameSigner(proof, sign1, publicKeys, message, []byte(`second`), sign2, publicKeys, message, []byte(`first`)) == Success {
+		t.Error("Proof accepted with swapped cases.")
+	}
+}
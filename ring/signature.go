@@ -27,7 +27,15 @@ import (
 //     HashOID    ::= OBJECT IDENTIFIER,
 //     KeyImage   ::= PointData,
 //     Checksum   ::= INTEGER,
-//     Signatures ::= SEQUENCE OF INTEGER
+//     Signatures ::= SEQUENCE OF INTEGER,
+//     Sources    ::= [0] EXPLICIT SEQUENCE OF OCTET STRING OPTIONAL,
+//     SignedAttributes ::= [1] EXPLICIT SET OF Attribute OPTIONAL,
+//     RingDigest ::= [2] EXPLICIT OCTET STRING OPTIONAL
+// END
+//
+// Attribute DEFINITIONS ::= BEGIN
+//     Key   ::= UTF8String,
+//     Value ::= UTF8String
 // END
 // ```
 // openssl asn1parse -i -dump -in signature.pem
@@ -40,24 +48,96 @@ type PointData struct {
 	Y []byte
 }
 
+// Attribute is a signed attribute of the signature, such as signing time or content type.
+type Attribute struct {
+	Key   string `asn1:"utf8"`
+	Value string `asn1:"utf8"`
+}
+
 // Signature holds data of ring signature.
+// Sources holds digests of the rings merged into the ring of the signature. They are covered by the signature.
+// SignedAttributes are covered by the signature together with the message.
+// RingDigest is the digest of the folded public keys of the ring. It is not covered by the signature,
+// the ring itself is. It only tells early that the signature is verified against another ring.
 type Signature struct {
+	Name             string
+	Version          int
+	CurveOID         asn1.ObjectIdentifier
+	HasherOID        asn1.ObjectIdentifier
+	KeyImage         PointData
+	Checksum         []byte
+	Signatures       [][]byte
+	Sources          [][]byte    `asn1:"optional,omitempty,explicit,tag:0"`
+	SignedAttributes []Attribute `asn1:"optional,omitempty,explicit,tag:1,set"`
+	RingDigest       []byte      `asn1:"optional,omitempty,explicit,tag:2"`
+}
+
+// MultiSignature holds data of Borromean ring signature over several rings.
+type MultiSignature struct {
 	Name       string
 	Version    int
 	CurveOID   asn1.ObjectIdentifier
 	HasherOID  asn1.ObjectIdentifier
+	KeyImages  []PointData
+	Checksum   []byte
+	Signatures [][][]byte
+}
+
+// AuthorshipProof holds proof that the owner of the public key made the signature.
+type AuthorshipProof struct {
+	Name      string
+	Version   int
+	CurveOID  asn1.ObjectIdentifier
+	HasherOID asn1.ObjectIdentifier
+	PublicKey PointData
+	KeyImage  PointData
+	Challenge []byte
+	Checksum  []byte
+	Response  []byte
+}
+
+// NonAuthorshipProof holds proof that the owner of the public key did not make the signature.
+type NonAuthorshipProof struct {
+	Name       string
+	Version    int
+	CurveOID   asn1.ObjectIdentifier
+	HasherOID  asn1.ObjectIdentifier
+	PublicKey  PointData
 	KeyImage   PointData
+	Commitment PointData
 	Checksum   []byte
-	Signatures [][]byte
+	Responses  [][]byte
+}
+
+// LinkStatement holds ring digest, case identifier, message digest and key image of one of the linked signatures.
+type LinkStatement struct {
+	RingDigest    []byte
+	Case          []byte
+	MessageDigest []byte
+	KeyImage      PointData
+}
+
+// SameSignerProof holds proof that two key images were made by the same private key.
+type SameSignerProof struct {
+	Name      string
+	Version   int
+	CurveOID  asn1.ObjectIdentifier
+	HasherOID asn1.ObjectIdentifier
+	First     LinkStatement
+	Second    LinkStatement
+	Checksum  []byte
+	Response  []byte
 }
 
 // FoldedPublicKeys holds data of points of public keys.
+// Sources holds digests of the rings merged into these keys.
 type FoldedPublicKeys struct {
 	Name      string
 	CurveOID  asn1.ObjectIdentifier
 	HasherOID asn1.ObjectIdentifier
 	Digest    []byte
 	Keys      [][]byte
+	Sources   [][]byte `asn1:"optional,omitempty,explicit,tag:0"`
 }
 
 // CurveCodes maps curve names to curves available to make signature.
@@ -118,6 +198,52 @@ const (
 	ParsePKIXPublicKeyFailed          = 23
 	CreateKeyFailed                   = 24
 	MarshalKeyFailed                  = 25
+	IncorrectNumberOfRings            = 26
+	SourceRingsMismatch               = 27
+	PrivateKeyNotSigner               = 28
+	EmptyChallenge                    = 29
+	ChallengeMismatch                 = 30
+	PublicKeyNotFoundAmongPublicKeys  = 31
+	InvalidProof                      = 32
+	PrivateKeyIsSigner                = 33
+	LinkStatementMismatch             = 34
+	AlreadySignedInCase               = 35
+	SigningStateFailure               = 36
+	StorageFailure                    = 37
+	UnknownPolicy                     = 38
+	IncorrectNumberOfOptions          = 39
+	ChoiceOutOfRange                  = 40
+	ElectionKeyMismatch               = 41
+	DecryptionFailure                 = 42
+	TallyMismatch                     = 43
+	UnknownBallotKind                 = 44
+	BallotSchemaMismatch              = 45
+	NonCanonicalBallot                = 46
+	DuplicateChoice                   = 47
+	IncorrectNumberOfChoices          = 48
+	InvalidBallotSchema               = 49
+	ServerIdentityMismatch            = 50
+	UnknownChallenge                  = 51
+	ChallengeExpired                  = 52
+	InvalidChallenge                  = 53
+	CounterOutOfRange                 = 54
+	RingMismatch                      = 55
+	ContentMismatch                   = 56
+	MissingAttachedContent            = 57
+	DuplicateAttribute                = 58
+	InvalidAttribute                  = 59
+	AttributeMismatch                 = 60
+	MissingRingDigest                 = 61
+	ManifestMismatch                  = 62
+	ReservedMessagePrefix             = 63
+	AlreadySignedMessageInCase        = 64
+	BallotBindingMismatch             = 65
+	DuplicateCiphertext               = 66
+	InputTooLarge                     = 67
+	InvalidEpochLength                = 68
+	ReadingFolderFailed               = 69
+	NullInput                         = 70
+	InvalidRateLimit                  = 71
 )
 
 // ErrorMessages convert status codes to human readable error messages.
@@ -147,6 +273,52 @@ var ErrorMessages = map[int]string{
 	ParsePKIXPublicKeyFailed:          "Parse PKIX public key falied.",
 	CreateKeyFailed:                   "Create key failed.",
 	MarshalKeyFailed:                  "Marshal key failed.",
+	IncorrectNumberOfRings:            "Incorrect number of rings.",
+	SourceRingsMismatch:               "Source rings of the signature do not match the public keys.",
+	PrivateKeyNotSigner:               "The private key did not make the signature.",
+	EmptyChallenge:                    "Challenge is empty.",
+	ChallengeMismatch:                 "Challenge does not match the proof.",
+	PublicKeyNotFoundAmongPublicKeys:  "Public key not found among public keys.",
+	InvalidProof:                      "Invalid proof.",
+	PrivateKeyIsSigner:                "The private key made the signature.",
+	LinkStatementMismatch:             "Statement of the proof does not match the signature.",
+	AlreadySignedInCase:               "A signature for this ring and case was already made.",
+	SigningStateFailure:               "Reading or writing of the signing state failed.",
+	StorageFailure:                    "Storage of the registry failed.",
+	UnknownPolicy:                     "Unknown policy of linked ballots.",
+	IncorrectNumberOfOptions:          "Incorrect number of options of the ballot.",
+	ChoiceOutOfRange:                  "Choice is out of range of the options.",
+	ElectionKeyMismatch:               "Ballot was not encrypted by the election key.",
+	DecryptionFailure:                 "Decrypted count was not found.",
+	TallyMismatch:                     "Decryption was made for another tally.",
+	UnknownBallotKind:                 "Unknown kind of the ballot.",
+	BallotSchemaMismatch:              "Ballot was made for another schema.",
+	NonCanonicalBallot:                "Ballot is not in the canonical encoding.",
+	DuplicateChoice:                   "The option was chosen more than once.",
+	IncorrectNumberOfChoices:          "Incorrect number of choices of the ballot.",
+	InvalidBallotSchema:               "Ballot schema is not valid.",
+	ServerIdentityMismatch:            "Challenge was issued for another server.",
+	UnknownChallenge:                  "Challenge is unknown or it was already used.",
+	ChallengeExpired:                  "Challenge has expired.",
+	InvalidChallenge:                  "Challenge is not valid.",
+	CounterOutOfRange:                 "Counter is out of the range of the quota.",
+	RingMismatch:                      "Signature was made for another ring.",
+	ContentMismatch:                   "Message does not match the content of the attached signature.",
+	MissingAttachedContent:            "Attached signature has neither content nor its digest.",
+	DuplicateAttribute:                "Signed attribute is set more than once.",
+	InvalidAttribute:                  "Signed attribute is not valid.",
+	AttributeMismatch:                 "Signed attribute is missing or has unexpected value.",
+	MissingRingDigest:                 "Signature does not carry the digest of its ring.",
+	ManifestMismatch:                  "Manifest digest does not match the content of the bundle.",
+	ReservedMessagePrefix:             "Message without signed attributes starts with the prefix reserved for signed attributes.",
+	AlreadySignedMessageInCase:        "The same message was already signed for this ring and case.",
+	BallotBindingMismatch:             "Ballot was encrypted for another case or voter.",
+	DuplicateCiphertext:               "Ballot repeats a ciphertext of another voter.",
+	InputTooLarge:                     "Input is too large.",
+	InvalidEpochLength:                "Epoch length must be at least one second.",
+	ReadingFolderFailed:               "Reading of the folder failed.",
+	NullInput:                         "Input is NULL.",
+	InvalidRateLimit:                  "Rate limit needs a positive period.",
 }
 
 // GetCurveName returns curve name of the curve instace.
@@ -13,6 +13,7 @@ import (
 	"crypto/ecdsa"
 	"crypto/elliptic"
 	"crypto/rand"
+	"encoding/hex"
 	"hash"
 	"math/big"
 	"reflect"
@@ -216,6 +217,22 @@ func MakeSignature(
 	message []byte,
 	caseIdentifier []byte,
 ) (int, *Signature) {
+	return makeSignature(curve, hasher, privateKey, publicKeys, privateKeyPosition, message, caseIdentifier, nil, nil)
+}
+
+// makeSignature creates ring signature of the message together with the signed attributes
+// and the digests of the source rings.
+func makeSignature(
+	curve func() elliptic.Curve,
+	hasher func() hash.Hash,
+	privateKey *ecdsa.PrivateKey,
+	publicKeys []*ecdsa.PublicKey,
+	privateKeyPosition int,
+	message []byte,
+	caseIdentifier []byte,
+	attributes []Attribute,
+	sources [][]byte,
+) (int, *Signature) {
 
 	if !CurveHashSupportedCombination(curve, hasher) {
 		return UnsupportedCurveHashCombination, nil
@@ -252,6 +269,15 @@ func MakeSignature(
 		}
 	}
 
+	status, attributes := CanonicalAttributes(attributes)
+	if status != Success {
+		return status, nil
+	}
+	status, m := fc.messageDigest(message, attributes, sources)
+	if status != Success {
+		return status, nil
+	}
+
 	// # 4 A LSAG Signature Scheme
 	//
 	// Let *G* = ⧼g⧽ be a group of prime order *q* such that the underlying discrete
@@ -268,7 +294,6 @@ func MakeSignature(
 	q := params.N                    // curve order
 	G := Point{params.Gx, params.Gy} // curve generator
 
-	m := fc.MakeDigest(message)
 	xπ := privateKey.D.Bytes() // secret multiplier
 	π := privateKeyPosition
 	L := ConvertPublicKeysToPoints(publicKeys)
@@ -329,6 +354,11 @@ func MakeSignature(
 		KeyImage:   PointData{X: y.x.Bytes(), Y: y.y.Bytes()},
 		Checksum:   c[0],
 		Signatures: s,
+		Sources:    sources,
+		RingDigest: fc.PublicKeysDigest(publicKeys),
+	}
+	if len(attributes) > 0 {
+		sign.SignedAttributes = attributes
 	}
 
 	return Success, &sign
@@ -343,6 +373,34 @@ func Create(
 	message []byte,
 	caseIdentifier []byte,
 ) (int, *Signature) {
+	return CreateWithAttributes(curve, hasher, privateKey, publicKeys, message, caseIdentifier, nil)
+}
+
+// CreateWithAttributes makes ring signature covering the message and the signed attributes.
+func CreateWithAttributes(
+	curve func() elliptic.Curve,
+	hasher func() hash.Hash,
+	privateKey *ecdsa.PrivateKey,
+	publicKeys []*ecdsa.PublicKey,
+	message []byte,
+	caseIdentifier []byte,
+	attributes []Attribute,
+) (int, *Signature) {
+	return CreateWithSources(curve, hasher, privateKey, publicKeys, message, caseIdentifier, attributes, nil)
+}
+
+// CreateWithSources makes ring signature covering the message, the signed attributes
+// and the digests of the rings merged into the public keys.
+func CreateWithSources(
+	curve func() elliptic.Curve,
+	hasher func() hash.Hash,
+	privateKey *ecdsa.PrivateKey,
+	publicKeys []*ecdsa.PublicKey,
+	message []byte,
+	caseIdentifier []byte,
+	attributes []Attribute,
+	sources [][]byte,
+) (int, *Signature) {
 
 	var privateKeyPosition = -1
 
@@ -355,7 +413,37 @@ func Create(
 	if privateKeyPosition == -1 {
 		return PrivateKeyNotFoundAmongPublicKeys, nil
 	}
-	return MakeSignature(curve, hasher, privateKey, publicKeys, privateKeyPosition, message, caseIdentifier)
+	return makeSignature(curve, hasher, privateKey, publicKeys, privateKeyPosition, message, caseIdentifier, attributes, sources)
+}
+
+// KeyImage returns key image the private key makes for the public keys and case identifier,
+// and position of the private key among public keys.
+func KeyImage(
+	curve func() elliptic.Curve,
+	hasher func() hash.Hash,
+	privateKey *ecdsa.PrivateKey,
+	publicKeys []*ecdsa.PublicKey,
+	caseIdentifier []byte,
+) (int, PointData, int) {
+
+	if !CurveHashSupportedCombination(curve, hasher) {
+		return UnsupportedCurveHashCombination, PointData{}, -1
+	}
+	fc := FactoryContext{Curve: curve(), Hasher: hasher}
+	for _, pub := range publicKeys {
+		if pub.Curve != fc.Curve {
+			return UnexpectedCurveType, PointData{}, -1
+		}
+	}
+	position := findPublicKey(publicKeys, privateKey.X.Bytes(), privateKey.Y.Bytes())
+	if position == -1 {
+		return PrivateKeyNotFoundAmongPublicKeys, PointData{}, position
+	}
+	h := fc.HashPublicKeysIntoPoint(ConvertPublicKeysToPoints(publicKeys), caseIdentifier)
+	if h.x == nil {
+		return PointWasNotFound, PointData{}, position
+	}
+	return Success, fc.PointScalarMult(h, privateKey.D.Bytes()).PointData(), position
 }
 
 // Verify verifies signature.
@@ -368,9 +456,6 @@ func Verify(sign *Signature, publicKeys []*ecdsa.PublicKey, message []byte, case
 	var z1, z2 Point
 
 	n := len(publicKeys)
-	if len(sign.Signatures) != n {
-		return IncorrectNumberOfSignatures
-	}
 
 	curve, success1 := GetCurve(sign.CurveOID)
 	if !success1 {
@@ -392,6 +477,13 @@ func Verify(sign *Signature, publicKeys []*ecdsa.PublicKey, message []byte, case
 		}
 	}
 
+	if len(sign.RingDigest) > 0 && !bytes.Equal(sign.RingDigest, fc.PublicKeysDigest(publicKeys)) {
+		return RingMismatch
+	}
+	if len(sign.Signatures) != n {
+		return IncorrectNumberOfSignatures
+	}
+
 	kx, ky := BuffToInt(sign.KeyImage.X), BuffToInt(sign.KeyImage.Y)
 	if !fc.Curve.IsOnCurve(kx, ky) {
 		return InvalidKeyImage
@@ -405,7 +497,10 @@ func Verify(sign *Signature, publicKeys []*ecdsa.PublicKey, message []byte, case
 	params := fc.Curve.Params()
 	G := Point{params.Gx, params.Gy}
 
-	m := fc.MakeDigest(message)
+	status, m := fc.messageDigest(message, sign.SignedAttributes, sign.Sources)
+	if status != Success {
+		return status
+	}
 	L := ConvertPublicKeysToPoints(publicKeys)
 	Lb := PointsToBytes(L)
 
@@ -441,3 +536,16 @@ func Verify(sign *Signature, publicKeys []*ecdsa.PublicKey, message []byte, case
 	}
 	return IncorrectChecksum
 }
+
+// PublicKeysDigest returns digest of public keys. It is the same value as the digest of folded public keys.
+func (fc FactoryContext) PublicKeysDigest(publicKeys []*ecdsa.PublicKey) []byte {
+	digests := make([]byte, 0)
+	for _, pub := range publicKeys {
+		buff := []byte{0x04} // Uncompressed form.
+		buff = append(buff, pub.X.Bytes()...)
+		buff = append(buff, pub.Y.Bytes()...)
+		digests = append(digests, hex.EncodeToString(fc.MakeDigest(buff))...)
+		digests = append(digests, '\n')
+	}
+	return fc.MakeDigest(digests)
+}
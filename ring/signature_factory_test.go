@@ -522,6 +522,31 @@ func TestVerifyDifferentPublicKeys(t *testing.T) {
 	}
 }
 
+func TestVerifyRingMismatch(t *testing.T) {
+	t.Parallel()
+	curve := elliptic.P256
+	privateKeys, publicKeys := createPrivatePublicKeys(curve, 3)
+	status, sign := Create(curve, sha3.New256, privateKeys[0], publicKeys, message, nil)
+	if status != Success {
+		t.Fatal(status)
+	}
+	fc := FactoryContext{Curve: curve(), Hasher: sha3.New256}
+	if !bytes.Equal(sign.RingDigest, fc.PublicKeysDigest(publicKeys)) {
+		t.Error("Signature does not carry the digest of its ring.")
+	}
+	_, otherPublicKeys := createPrivatePublicKeys(curve, 3)
+	if status := Verify(sign, otherPublicKeys, message, nil); status != RingMismatch {
+		t.Errorf("Signature verified against another ring with status %d.", status)
+	}
+	sign.RingDigest = nil
+	if status := Verify(sign, otherPublicKeys, message, nil); status != IncorrectChecksum {
+		t.Errorf("Signature without ring digest verified against another ring with status %d.", status)
+	}
+	if status := Verify(sign, publicKeys, message, nil); status != Success {
+		t.Errorf("Signature without ring digest is not valid: %s", ErrorMessages[status])
+	}
+}
+
 func TestVerifyDifferentMessage(t *testing.T) {
 	t.Parallel()
 	size := 10
@@ -612,3 +637,36 @@ func TestVerifyCurveS256WithUnsupportedHashers(t *testing.T) {
 		doTest(t, curve, hasher)
 	}
 }
+
+func TestKeyImageBeforeSigning(t *testing.T) {
+	t.Parallel()
+	testAllCurvesAndHashers(t, func(t *testing.T, curve func() elliptic.Curve, hasher func() hash.Hash, size int, priv int) {
+		privateKeys, publicKeys := createPrivatePublicKeys(curve, size)
+		caseIdentifier := []byte(`case`)
+		status, keyImage, position := KeyImage(curve, hasher, privateKeys[priv], publicKeys, caseIdentifier)
+		if status != Success {
+			t.Fatal(status)
+		}
+		if position != priv {
+			t.Errorf("Unexpected position %d.", position)
+		}
+		status, sign := Create(curve, hasher, privateKeys[priv], publicKeys, message, caseIdentifier)
+		if status != Success {
+			t.Fatal(status)
+		}
+		if !bytes.Equal(keyImage.Bytes(), sign.KeyImage.Bytes()) {
+			t.Error("Key image doesn't match the key image of signature.")
+		}
+	})
+}
+
+func TestKeyImagePrivateKeyNotFound(t *testing.T) {
+	t.Parallel()
+	curve := elliptic.P256
+	privateKeys, _ := createPrivatePublicKeys(curve, 1)
+	_, publicKeys := createPrivatePublicKeys(curve, 3)
+	status, _, _ := KeyImage(curve, sha3.New256, privateKeys[0], publicKeys, []byte(``))
+	if status != PrivateKeyNotFoundAmongPublicKeys {
+		t.Error("Expected PrivateKeyNotFoundAmongPublicKeys.")
+	}
+}
@@ -0,0 +1,272 @@
+// Package ringauth authenticates HTTP requests by ring signatures. The client signs the method, request URI,
+// digest of the body, timestamp and nonce of the request in the ring and sends the signature in headers.
+// The server verifies it against the folded public keys, rejects replays and passes the key image
+// of the signer to the handler, so the handler knows the request comes from a member of the ring, but not from which.
+// Requests with the same case and key image come from the same member and can be rate limited.
+package ringauth
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/zbohm/lirisi/client"
+	"github.com/zbohm/lirisi/ring"
+	"golang.org/x/crypto/sha3"
+)
+
+// Headers of the signed request.
+const (
+	SignatureHeader = "Lirisi-Signature"
+	TimestampHeader = "Lirisi-Timestamp"
+	NonceHeader     = "Lirisi-Nonce"
+	CaseHeader      = "Lirisi-Case"
+)
+
+// Defaults of the verifier.
+const (
+	DefaultMaxSkew     = 5 * time.Minute
+	DefaultMaxBodySize = 10 << 20
+)
+
+// Message returns content signed for the request.
+func Message(method, requestURI string, body []byte, timestamp int64, nonce string) []byte {
+	digest := sha3.Sum256(body)
+	return []byte("lirisi-ringauth-v1\n" + method + "\n" + requestURI + "\n" + hex.EncodeToString(digest[:]) + "\n" +
+		strconv.FormatInt(timestamp, 10) + "\n" + nonce)
+}
+
+// Transport signs requests in the ring before sending them by the base transport.
+type Transport struct {
+	// Base transport. Default is http.DefaultTransport.
+	Base             http.RoundTripper
+	FoldedPublicKeys []byte
+	PrivateKey       []byte
+	Case             string
+	// Now returns the current time. Default is time.Now.
+	Now func() time.Time
+}
+
+// RoundTrip signs the request and sends it.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body := []byte{}
+	if req.Body != nil {
+		content, err := ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		body = content
+	}
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	now := time.Now
+	if t.Now != nil {
+		now = t.Now
+	}
+	timestamp := now().Unix()
+	message := Message(req.Method, req.URL.RequestURI(), body, timestamp, hex.EncodeToString(nonce))
+	status, signature := client.CreateSignature(t.FoldedPublicKeys, t.PrivateKey, message, []byte(t.Case), "DER")
+	if status != ring.Success {
+		return nil, &Error{Status: status}
+	}
+
+	signed := req.Clone(req.Context())
+	signed.Body = ioutil.NopCloser(bytes.NewReader(body))
+	signed.GetBody = func() (io.ReadCloser, error) {
+		return ioutil.NopCloser(bytes.NewReader(body)), nil
+	}
+	signed.ContentLength = int64(len(body))
+	signed.Header.Set(SignatureHeader, base64.StdEncoding.EncodeToString(signature))
+	signed.Header.Set(TimestampHeader, strconv.FormatInt(timestamp, 10))
+	signed.Header.Set(NonceHeader, hex.EncodeToString(nonce))
+	signed.Header.Set(CaseHeader, t.Case)
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return base.RoundTrip(signed)
+}
+
+// Error of the signing with the status code.
+type Error struct {
+	Status int
+}
+
+func (e *Error) Error() string {
+	return "ringauth: " + ring.ErrorMessages[e.Status]
+}
+
+// RateLimit limits number of requests of one signer in the period. Zero number of requests is unlimited.
+type RateLimit struct {
+	Requests int
+	Period   time.Duration
+}
+
+// Identity of the signer of the request.
+type Identity struct {
+	KeyImage string
+	Case     string
+}
+
+type contextKey struct{}
+
+// FromContext returns identity of the signer of the verified request.
+func FromContext(ctx context.Context) (Identity, bool) {
+	identity, ok := ctx.Value(contextKey{}).(Identity)
+	return identity, ok
+}
+
+type window struct {
+	start  time.Time
+	period time.Duration
+	count  int
+}
+
+// Verifier verifies signed requests. It is safe for concurrent use.
+type Verifier struct {
+	// MaxSkew is the maximal difference of the timestamp of the request from the current time.
+	MaxSkew time.Duration
+	// MaxBodySize limits size of the request body.
+	MaxBodySize int64
+	// Now returns the current time. Default is time.Now.
+	Now func() time.Time
+
+	foldedPublicKeys []byte
+	cases            map[string]RateLimit
+	nonces           map[string]time.Time
+	windows          map[string]*window
+	lastPurge        time.Time
+	mutex            sync.Mutex
+}
+
+// NewVerifier creates verifier of requests signed in the ring of the folded public keys.
+// Only requests with the given cases are accepted, each case with its rate limit.
+// A limited number of requests needs a positive period.
+func NewVerifier(foldedPublicKeys []byte, cases map[string]RateLimit) (int, *Verifier) {
+	if status, _, _ := client.UnfoldPublicKeysContent(foldedPublicKeys); status != ring.Success {
+		return status, nil
+	}
+	for _, limit := range cases {
+		if limit.Requests > 0 && limit.Period <= 0 {
+			return ring.InvalidRateLimit, nil
+		}
+	}
+	return ring.Success, &Verifier{
+		MaxSkew:          DefaultMaxSkew,
+		MaxBodySize:      DefaultMaxBodySize,
+		foldedPublicKeys: foldedPublicKeys,
+		cases:            cases,
+		nonces:           map[string]time.Time{},
+		windows:          map[string]*window{},
+	}
+}
+
+func (v *Verifier) now() time.Time {
+	if v.Now != nil {
+		return v.Now()
+	}
+	return time.Now()
+}
+
+// purge removes expired nonces and rate limit windows. It has to be called with the mutex locked.
+func (v *Verifier) purge(now time.Time) {
+	if now.Sub(v.lastPurge) < time.Minute {
+		return
+	}
+	v.lastPurge = now
+	for nonce, timestamp := range v.nonces {
+		if now.Sub(timestamp) > 2*v.MaxSkew {
+			delete(v.nonces, nonce)
+		}
+	}
+	for key, w := range v.windows {
+		if now.Sub(w.start) >= w.period {
+			delete(v.windows, key)
+		}
+	}
+}
+
+// check verifies the signed request and returns identity of its signer and HTTP status code of the failure.
+func (v *Verifier) check(r *http.Request, body []byte) (Identity, int, string) {
+	caseIdentifier := r.Header.Get(CaseHeader)
+	limit, ok := v.cases[caseIdentifier]
+	if !ok {
+		return Identity{}, http.StatusUnauthorized, "Unknown case."
+	}
+	timestamp, err := strconv.ParseInt(r.Header.Get(TimestampHeader), 10, 64)
+	if err != nil {
+		return Identity{}, http.StatusUnauthorized, "Invalid timestamp."
+	}
+	nonce := r.Header.Get(NonceHeader)
+	if nonce == "" {
+		return Identity{}, http.StatusUnauthorized, "Missing nonce."
+	}
+	now := v.now()
+	if skew := now.Sub(time.Unix(timestamp, 0)); skew > v.MaxSkew || skew < -v.MaxSkew {
+		return Identity{}, http.StatusUnauthorized, "Timestamp out of the allowed window."
+	}
+	signature, err := base64.StdEncoding.DecodeString(r.Header.Get(SignatureHeader))
+	if err != nil || len(signature) == 0 {
+		return Identity{}, http.StatusUnauthorized, "Invalid signature encoding."
+	}
+	message := Message(r.Method, r.URL.RequestURI(), body, timestamp, nonce)
+	if status := client.VerifySignature(v.foldedPublicKeys, signature, message, []byte(caseIdentifier)); status != ring.Success {
+		return Identity{}, http.StatusUnauthorized, ring.ErrorMessages[status]
+	}
+	status, keyImage := client.SignatureKeyImage(signature, false)
+	if status != ring.Success {
+		return Identity{}, http.StatusUnauthorized, ring.ErrorMessages[status]
+	}
+	identity := Identity{KeyImage: string(keyImage), Case: caseIdentifier}
+
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	v.purge(now)
+	if _, seen := v.nonces[nonce]; seen {
+		return Identity{}, http.StatusUnauthorized, "Replayed request."
+	}
+	v.nonces[nonce] = time.Unix(timestamp, 0)
+	if limit.Requests > 0 {
+		key := caseIdentifier + "\x00" + identity.KeyImage
+		w, ok := v.windows[key]
+		if !ok || now.Sub(w.start) >= limit.Period {
+			w = &window{start: now, period: limit.Period}
+			v.windows[key] = w
+		}
+		if w.count >= limit.Requests {
+			return Identity{}, http.StatusTooManyRequests, "Rate limit exceeded."
+		}
+		w.count++
+	}
+	return identity, http.StatusOK, ""
+}
+
+// Middleware passes verified requests to the next handler with identity of the signer in the request context.
+// Other requests are answered by 401 Unauthorized or 429 Too Many Requests.
+func (v *Verifier) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, v.MaxBodySize))
+		if err != nil {
+			http.Error(w, "Request body too large.", http.StatusRequestEntityTooLarge)
+			return
+		}
+		identity, code, message := v.check(r, body)
+		if code != http.StatusOK {
+			http.Error(w, message, code)
+			return
+		}
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), contextKey{}, identity)))
+	})
+}
@@ -0,0 +1,158 @@
+package ringauth
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zbohm/lirisi/internal/testring"
+	"github.com/zbohm/lirisi/ring"
+)
+
+// startServer runs server answering the key image and the body of verified requests.
+func startServer(t *testing.T, folded []byte, cases map[string]RateLimit) (*Verifier, *httptest.Server) {
+	status, verifier := NewVerifier(folded, cases)
+	if status != ring.Success {
+		t.Fatal(ring.ErrorMessages[status])
+	}
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		identity, ok := FromContext(r.Context())
+		if !ok {
+			t.Error("Missing identity.")
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		w.Write([]byte(identity.Case + " " + identity.KeyImage + " " + string(body)))
+	})
+	return verifier, httptest.NewServer(verifier.Middleware(handler))
+}
+
+func send(t *testing.T, c *http.Client, url, body string, expected int) string {
+	response, err := c.Post(url, "text/plain", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+	content, _ := ioutil.ReadAll(response.Body)
+	if response.StatusCode != expected {
+		t.Fatalf("Unexpected status %d, expected %d: %s", response.StatusCode, expected, content)
+	}
+	return string(content)
+}
+
+func signingClient(folded, privateKey []byte, caseIdentifier string) *http.Client {
+	return &http.Client{Transport: &Transport{FoldedPublicKeys: folded, PrivateKey: privateKey, Case: caseIdentifier}}
+}
+
+func TestSignedRequests(t *testing.T) {
+	privateKeys, folded := testring.Create(t, 3)
+	_, server := startServer(t, folded, map[string]RateLimit{"api": {}, "other": {}})
+	defer server.Close()
+
+	first := send(t, signingClient(folded, privateKeys[0], "api"), server.URL+"/items?page=1", "Hello", http.StatusOK)
+	if !strings.HasPrefix(first, "api ") || !strings.HasSuffix(first, " Hello") {
+		t.Errorf("Unexpected response %s.", first)
+	}
+	again := send(t, signingClient(folded, privateKeys[0], "api"), server.URL+"/items", "", http.StatusOK)
+	if strings.Fields(again)[1] != strings.Fields(first)[1] {
+		t.Errorf("Key images of the same member differ.")
+	}
+	other := send(t, signingClient(folded, privateKeys[1], "api"), server.URL+"/items", "", http.StatusOK)
+	if strings.Fields(other)[1] == strings.Fields(first)[1] {
+		t.Errorf("Key images of different members are equal.")
+	}
+	otherCase := send(t, signingClient(folded, privateKeys[0], "other"), server.URL+"/items", "", http.StatusOK)
+	if strings.Fields(otherCase)[1] == strings.Fields(first)[1] {
+		t.Errorf("Key images of different cases are equal.")
+	}
+}
+
+// recorder keeps the last signed request.
+type recorder struct {
+	request *http.Request
+}
+
+func (r *recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	r.request = req
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestReplay(t *testing.T) {
+	privateKeys, folded := testring.Create(t, 2)
+	_, server := startServer(t, folded, map[string]RateLimit{"api": {}})
+	defer server.Close()
+
+	rec := &recorder{}
+	c := &http.Client{Transport: &Transport{Base: rec, FoldedPublicKeys: folded, PrivateKey: privateKeys[0], Case: "api"}}
+	send(t, c, server.URL, "Hello", http.StatusOK)
+
+	replayed, _ := http.NewRequest("POST", server.URL, strings.NewReader("Hello"))
+	replayed.Header = rec.request.Header
+	response, err := http.DefaultClient.Do(replayed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	response.Body.Close()
+	if response.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Replayed request accepted with status %d.", response.StatusCode)
+	}
+
+	// The signature does not cover another body.
+	tampered, _ := http.NewRequest("POST", server.URL, strings.NewReader("Hacked"))
+	tampered.Header = rec.request.Header.Clone()
+	tampered.Header.Set(NonceHeader, "00")
+	response, err = http.DefaultClient.Do(tampered)
+	if err != nil {
+		t.Fatal(err)
+	}
+	response.Body.Close()
+	if response.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Tampered request accepted with status %d.", response.StatusCode)
+	}
+}
+
+func TestRejected(t *testing.T) {
+	privateKeys, folded := testring.Create(t, 2)
+	outsiders, otherFolded := testring.Create(t, 2)
+	verifier, server := startServer(t, folded, map[string]RateLimit{"api": {}})
+	defer server.Close()
+
+	send(t, http.DefaultClient, server.URL, "", http.StatusUnauthorized)
+	send(t, signingClient(folded, privateKeys[0], "unknown"), server.URL, "", http.StatusUnauthorized)
+	send(t, signingClient(otherFolded, outsiders[0], "api"), server.URL, "", http.StatusUnauthorized)
+
+	stale := &Transport{FoldedPublicKeys: folded, PrivateKey: privateKeys[0], Case: "api",
+		Now: func() time.Time { return time.Now().Add(-2 * DefaultMaxSkew) }}
+	send(t, &http.Client{Transport: stale}, server.URL, "", http.StatusUnauthorized)
+
+	verifier.MaxBodySize = 4
+	send(t, signingClient(folded, privateKeys[0], "api"), server.URL, "Hello", http.StatusRequestEntityTooLarge)
+
+	if status, _ := NewVerifier([]byte("garbage"), nil); status == ring.Success {
+		t.Errorf("Verifier of invalid public keys created.")
+	}
+	if status, _ := NewVerifier(folded, map[string]RateLimit{"api": {Requests: 1}}); status != ring.InvalidRateLimit {
+		t.Errorf("Verifier of rate limit without period created.")
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	privateKeys, folded := testring.Create(t, 2)
+	now := time.Now()
+	verifier, server := startServer(t, folded, map[string]RateLimit{"api": {Requests: 2, Period: time.Minute}, "free": {}})
+	defer server.Close()
+	verifier.Now = func() time.Time { return now }
+
+	member := signingClient(folded, privateKeys[0], "api")
+	send(t, member, server.URL, "", http.StatusOK)
+	send(t, member, server.URL, "", http.StatusOK)
+	send(t, member, server.URL, "", http.StatusTooManyRequests)
+	// Limits are per member and per case.
+	send(t, signingClient(folded, privateKeys[1], "api"), server.URL, "", http.StatusOK)
+	send(t, signingClient(folded, privateKeys[0], "free"), server.URL, "", http.StatusOK)
+
+	now = now.Add(time.Minute)
+	send(t, member, server.URL, "", http.StatusOK)
+}
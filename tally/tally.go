@@ -0,0 +1,137 @@
+// Package tally counts messages of ballots signed by members of the ring.
+// Ballots with the same key image were signed by the same member and the duplicate policy decides which of them count.
+package tally
+
+import (
+	"fmt"
+
+	"github.com/zbohm/lirisi/client"
+	"github.com/zbohm/lirisi/ring"
+)
+
+// Policy resolves linked ballots.
+type Policy int
+
+// Policies of linked ballots.
+const (
+	// FirstWins counts the first ballot of the member.
+	FirstWins Policy = iota
+	// LastWins counts the last ballot of the member, so the member can vote again.
+	LastWins
+	// DiscardAllLinked counts no ballot of the member who voted more than once.
+	DiscardAllLinked
+)
+
+// PolicyNames maps names of policies used in the command line.
+var PolicyNames = map[string]Policy{
+	"first-wins":         FirstWins,
+	"last-wins":          LastWins,
+	"discard-all-linked": DiscardAllLinked,
+}
+
+func (p Policy) String() string {
+	for name, policy := range PolicyNames {
+		if policy == p {
+			return name
+		}
+	}
+	return ""
+}
+
+// Ballot holds signed message. ID identifies the ballot in the audit, e.g. by its filename.
+type Ballot struct {
+	ID        string
+	Signature []byte
+	Message   []byte
+}
+
+// Rejection describes ballot that was not counted.
+type Rejection struct {
+	ID       string `json:"id"`
+	KeyImage string `json:"key_image,omitempty"`
+	Reason   string `json:"reason"`
+}
+
+// MessageCount holds number of counted ballots with the message.
+type MessageCount struct {
+	Message string `json:"message"`
+	Count   int    `json:"count"`
+}
+
+// Result of the tally.
+type Result struct {
+	Policy   string         `json:"policy"`
+	Counts   []MessageCount `json:"counts"`
+	Accepted []string       `json:"accepted"`
+	Rejected []Rejection    `json:"rejected"`
+	Invalid  []Rejection    `json:"invalid"`
+}
+
+// Count verifies ballots and counts their messages. Ballots are taken in the given order,
+// which decides about the first and the last ballot of the member.
+// Counts are ordered by the first counted ballot of the message.
+func Count(foldedPublicKeys, caseIdentifier []byte, ballots []Ballot, policy Policy) (int, Result) {
+	result := Result{
+		Policy:   policy.String(),
+		Counts:   []MessageCount{},
+		Accepted: []string{},
+		Rejected: []Rejection{},
+		Invalid:  []Rejection{},
+	}
+	if result.Policy == "" {
+		return ring.UnknownPolicy, result
+	}
+	if status, _, _ := client.UnfoldPublicKeysContent(foldedPublicKeys); status != ring.Success {
+		return status, result
+	}
+
+	// Ballots of each member, in the given order.
+	valid := []int{}
+	keyImages := make([]string, len(ballots))
+	linked := map[string][]int{}
+	for i, ballot := range ballots {
+		status := client.VerifySignature(foldedPublicKeys, ballot.Signature, ballot.Message, caseIdentifier)
+		if status != ring.Success {
+			result.Invalid = append(result.Invalid, Rejection{ID: ballot.ID, Reason: ring.ErrorMessages[status]})
+			continue
+		}
+		_, keyImage := client.SignatureKeyImage(ballot.Signature, false)
+		keyImages[i] = string(keyImage)
+		linked[keyImages[i]] = append(linked[keyImages[i]], i)
+		valid = append(valid, i)
+	}
+
+	positions := map[string]int{}
+	for _, i := range valid {
+		member := linked[keyImages[i]]
+		counted := -1
+		switch policy {
+		case FirstWins:
+			counted = member[0]
+		case LastWins:
+			counted = member[len(member)-1]
+		case DiscardAllLinked:
+			if len(member) == 1 {
+				counted = member[0]
+			}
+		}
+		if i != counted {
+			result.Rejected = append(result.Rejected, Rejection{
+				ID:       ballots[i].ID,
+				KeyImage: keyImages[i],
+				Reason:   fmt.Sprintf("Linked with %d other ballots, policy %s.", len(member)-1, result.Policy),
+			})
+			continue
+		}
+		message := string(ballots[i].Message)
+		position, ok := positions[message]
+		if !ok {
+			position = len(result.Counts)
+			positions[message] = position
+			result.Counts = append(result.Counts, MessageCount{Message: message})
+		}
+		result.Counts[position].Count++
+		result.Accepted = append(result.Accepted, ballots[i].ID)
+	}
+	return ring.Success, result
+}
@@ -0,0 +1,78 @@
+package tally
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/zbohm/lirisi/client"
+	"github.com/zbohm/lirisi/internal/testring"
+	"github.com/zbohm/lirisi/ring"
+)
+
+var caseIdentifier = []byte("election-2026")
+
+func createBallot(t *testing.T, id string, folded, privateKey []byte, message string) Ballot {
+	status, signature := client.CreateSignature(folded, privateKey, []byte(message), caseIdentifier, "PEM")
+	if status != ring.Success {
+		t.Fatal(ring.ErrorMessages[status])
+	}
+	return Ballot{ID: id, Signature: signature, Message: []byte(message)}
+}
+
+func TestCountPolicies(t *testing.T) {
+	privateKeys, folded := testring.Create(t, 3)
+	forged := createBallot(t, "forged", folded, privateKeys[2], "yes")
+	forged.Message = []byte("no")
+	ballots := []Ballot{
+		createBallot(t, "first", folded, privateKeys[0], "yes"),
+		createBallot(t, "second", folded, privateKeys[1], "no"),
+		createBallot(t, "revote", folded, privateKeys[0], "no"),
+		forged,
+	}
+
+	expected := map[Policy]struct {
+		counts   []MessageCount
+		accepted []string
+	}{
+		FirstWins:        {[]MessageCount{{"yes", 1}, {"no", 1}}, []string{"first", "second"}},
+		LastWins:         {[]MessageCount{{"no", 2}}, []string{"second", "revote"}},
+		DiscardAllLinked: {[]MessageCount{{"no", 1}}, []string{"second"}},
+	}
+	for policy, want := range expected {
+		status, result := Count(folded, caseIdentifier, ballots, policy)
+		if status != ring.Success {
+			t.Fatal(ring.ErrorMessages[status])
+		}
+		if !reflect.DeepEqual(result.Counts, want.counts) {
+			t.Errorf("Policy %s: unexpected counts %v.", policy, result.Counts)
+		}
+		if !reflect.DeepEqual(result.Accepted, want.accepted) {
+			t.Errorf("Policy %s: unexpected accepted ballots %v.", policy, result.Accepted)
+		}
+		if len(result.Rejected)+len(result.Accepted) != 3 {
+			t.Errorf("Policy %s: unexpected rejected ballots %v.", policy, result.Rejected)
+		}
+		if len(result.Invalid) != 1 || result.Invalid[0].ID != "forged" {
+			t.Errorf("Policy %s: unexpected invalid ballots %v.", policy, result.Invalid)
+		}
+	}
+}
+
+func TestCountOtherCase(t *testing.T) {
+	privateKeys, folded := testring.Create(t, 2)
+	ballots := []Ballot{createBallot(t, "first", folded, privateKeys[0], "yes")}
+	status, result := Count(folded, []byte("election-2027"), ballots, FirstWins)
+	if status != ring.Success {
+		t.Fatal(ring.ErrorMessages[status])
+	}
+	if len(result.Accepted) != 0 || len(result.Invalid) != 1 {
+		t.Errorf("Ballot of the other case was counted.")
+	}
+}
+
+func TestCountUnknownPolicy(t *testing.T) {
+	_, folded := testring.Create(t, 2)
+	if status, _ := Count(folded, caseIdentifier, []Ballot{}, Policy(9)); status != ring.UnknownPolicy {
+		t.Errorf("Unexpected status %s", ring.ErrorMessages[status])
+	}
+}
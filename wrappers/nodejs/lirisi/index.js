@@ -3,7 +3,6 @@ const ffi = require("ffi")
 const Struct = require("ref-struct")
 const ArrayType = require("ref-array")
 const path = require("path")
-const utf8 = require('utf8')
 
 const Success = 0
 
@@ -33,131 +32,81 @@ const ErrorCode = {
 	23: "ParsePKIXPublicKeyFailed",
 }
 
-const BytesArray = ArrayType(ref.types.byte)
-
-const GoBoolean = ref.types.ushort
-
-
-// Define object GoBytes to map to:
-// C type struct { void *data; GoInt len; GoInt cap; }
-const GoBytes = Struct({
-    data: BytesArray,
-    len:  ref.types.ulonglong,
-    cap: ref.types.ulonglong,
+// Pointer types of the C API. See the header generated with the library for the ownership rules.
+const BytesPtr = ref.refType(ref.types.uint8)
+const BytesPtrPtr = ref.refType(BytesPtr)
+const SizePtr = ref.refType(ref.types.size_t)
+const ListHandle = ref.refType(ref.types.void)
+const ListHandlePtr = ref.refType(ListHandle)
+const BytesPtrArray = ArrayType(BytesPtr)
+const SizeArray = ArrayType(ref.types.size_t)
+
+// Result of LirisiVerifySignatureDetails.
+const Verification = Struct({
+    status: ref.types.int,
+    keyImage: ref.types.CString,
+    curveName: ref.types.CString,
+    hashName: ref.types.CString,
+    ringSize: ref.types.size_t,
+    ringDigest: ref.types.CString,
 })
+const VerificationPtr = ref.refType(Verification)
 
-const GoBytesArrayType = ArrayType(GoBytes)
 
-const GoBytesArray = Struct({
-    data: GoBytesArrayType,
-    len:  ref.types.ulonglong,
-    cap: ref.types.ulonglong
-})
-
-const GoString = Struct({
-    p: BytesArray,
-    n: ref.types.int
-})
-
-const GoStringArrayType = ArrayType(GoString)
-
-const GoStringsArray = Struct({
-    data: GoStringArrayType,
-    len:  ref.types.ulonglong,
-    cap: ref.types.ulonglong
-})
-
-// typedef struct { GoInt len; GoUint8 data[]; } BuffBytes;
-const BuffBytes = Struct({
-    data: BytesArray,
-    len:  ref.types.ulonglong,
-})
-
-
-const intSize = 8
-
-
-function toBytes(pointer) {
-    const status = ref.readUInt64LE(ref.readPointer(pointer['ref.buffer'], 0, intSize))
-    if (status != Success) {
-        throw new Error(ErrorCode[status])
-    }
-    const size = ref.readUInt64LE(ref.readPointer(pointer['ref.buffer'], 0, intSize * 2).slice(intSize))
-    const buffer = ref.readPointer(pointer['ref.buffer'], 0, intSize * 2 + size)
-    return Array.from(buffer.slice(intSize * 2))
-}
-
-function toBytesArray(pointer) {
-    const status = ref.readUInt64LE(ref.readPointer(pointer['ref.buffer'], 0, intSize))
+function checkStatus(status) {
     if (status != Success) {
-        throw new Error(ErrorCode[status])
-    }
-    const length = ref.readUInt64LE(ref.readPointer(pointer['ref.buffer'], 0, intSize * 2).slice(intSize))
-
-    let from, to, size, buffer, data = []
-
-    from = intSize * 2
-    to = intSize * 2
-
-    for (let i=0; i < length; i++) {
-        size = ref.readUInt64LE(ref.readPointer(pointer['ref.buffer'], 0, to).slice(from))
-        if (i == 0) {
-            to += intSize
-        }
-        to += size
-        from += intSize
-        buffer = ref.readPointer(pointer['ref.buffer'], 0, to)
-        data.push(Array.from(buffer.slice(from)))
-        to += intSize
-        from += size
+        const error = new Error(ErrorCode[status] || lib.LirisiErrorMessage(status))
+        error.status = status
+        throw error
     }
-    return data
 }
 
-
-function toString(pointer) {
-    return Buffer.from(toBytes(pointer)).toString()
+// Input as buffer and its length.
+function inBytes(octets) {
+    const buffer = Buffer.from(octets)
+    return [buffer, buffer.length]
 }
 
-
-function goBytes(octests) {
-    const slice = new GoBytes()
-    slice.data = Array.from(octests)
-    slice.len = octests.length
-    slice.cap = octests.length
-    return slice
+// Inputs as arrays of pointers and lengths.
+function inBytesArray(array) {
+    const buffers = Array.from(array, (item) => Buffer.from(item))
+    return [new BytesPtrArray(buffers), new SizeArray(buffers.map((item) => item.length)), buffers.length]
 }
 
-function goString(string) {
-    const slice = new GoString()
-    const bytes = Array.from(utf8.encode(string))
-    slice.p = bytes
-    slice.n = bytes.length
-    return slice
+// Call function with output buffer, copy the buffer and release it.
+function callBytes(fnc, ...args) {
+    const out = ref.alloc(BytesPtr)
+    const outLen = ref.alloc(ref.types.size_t)
+    checkStatus(fnc(...args, out, outLen))
+    const pointer = out.deref()
+    try {
+        return Array.from(ref.reinterpret(pointer, outLen.deref(), 0))
+    } finally {
+        lib.LirisiFree(pointer)
+    }
 }
 
-function goBytesArray(array) {
-    const data = []
-    for (var i=0; i < array.length; i++) {
-        data.push(goBytes(array[i]))
+// Call function with output list, copy items of the list and release it.
+function callList(fnc, ...args) {
+    const out = ref.alloc(ListHandle)
+    checkStatus(fnc(...args, out))
+    const handle = out.deref()
+    try {
+        const data = []
+        const count = lib.LirisiListSize(handle)
+        for (let i = 0; i < count; i++) {
+            const length = ref.alloc(ref.types.size_t)
+            const item = lib.LirisiListItem(handle, i, length)
+            data.push(Array.from(ref.reinterpret(item, length.deref(), 0)))
+        }
+        return data
+    } finally {
+        lib.LirisiFree(handle)
     }
-    const slice = new GoBytesArray()
-    slice.data = new GoBytesArrayType(data)
-    slice.len = data.length
-    slice.cap = data.length
-    return slice
 }
 
-function goStringsArray(texts) {
-    const data = []
-    for (var i=0; i < texts.length; i++) {
-        data.push(goString(texts[i]))
-    }
-    const slice = new GoStringsArray()
-    slice.data = new GoStringArrayType(data)
-    slice.len = data.length
-    slice.cap = data.length
-    return slice
+function toString(array) {
+    return Buffer.from(array).toString()
 }
 
 
@@ -166,12 +115,13 @@ module.exports.FoldPublicKeys = (
         hashName = 'sha3-256',
         outFormat = 'PEM',
         order = 'hashes'
-    ) => toBytes(lib.FoldPublicKeys(
-        goBytesArray(pubKeyContens),
-        goString(hashName),
-        goString(outFormat),
-        goString(order),
-    ))
+    ) => callBytes(
+        lib.LirisiFoldPublicKeys,
+        ...inBytesArray(pubKeyContens),
+        hashName,
+        outFormat,
+        order,
+    )
 
 module.exports.CreateSignature = (
         foldedPublicKeys,
@@ -179,45 +129,86 @@ module.exports.CreateSignature = (
         message,
         caseIdentifier = '',
         outFormat = 'PEM'
-    ) => toBytes(lib.CreateSignature(
-        goBytes(foldedPublicKeys),
-        goBytes(privateKeyContent),
-        goBytes(message),
-        goBytes(caseIdentifier),
-        goString(outFormat),
-    ))
+    ) => callBytes(
+        lib.LirisiCreateSignature,
+        ...inBytes(foldedPublicKeys),
+        ...inBytes(privateKeyContent),
+        ...inBytes(message),
+        ...inBytes(caseIdentifier),
+        outFormat,
+    )
 
 module.exports.VerifySignature = (
         foldedPublicKeys,
         signature,
         message,
         caseIdentifier = '',
-    ) => lib.VerifySignature(
-        goBytes(foldedPublicKeys),
-        goBytes(signature),
-        goBytes(message),
-        goBytes(caseIdentifier),
+    ) => lib.LirisiVerifySignature(
+        ...inBytes(foldedPublicKeys),
+        ...inBytes(signature),
+        ...inBytes(message),
+        ...inBytes(caseIdentifier),
     )
 
-module.exports.SignatureKeyImage = (signature, separator = false) => toString(lib.SignatureKeyImage(goBytes(signature), separator))
-module.exports.PublicKeysDigest = (foldedPublicKeys, separator = false) => toString(lib.PublicKeysDigest(goBytes(foldedPublicKeys), separator))
-module.exports.PublicKeyXYCoordinates = (publicKey) => toBytes(lib.PublicKeyXYCoordinates(goBytes(publicKey)))
-module.exports.UnfoldPublicKeys = (foldedPublicKeys, format = 'PEM') => toBytesArray(lib.UnfoldPublicKeys(goBytes(foldedPublicKeys), goString(format)))
-module.exports.GeneratePrivateKey = (curveName = 'prime256v1', format = 'PEM') => toBytes(lib.GeneratePrivateKey(goString(curveName), goString(format)))
-module.exports.DerivePublicKey = (privateKey, format = 'PEM') => toBytes(lib.DerivePublicKey(goBytes(privateKey), goString(format)))
+// Verify signature and return its key image, curve and hash names, size and digest of the ring.
+// The signature is parsed only once. Properties are filled also when the verification fails.
+module.exports.VerifySignatureDetails = (
+        foldedPublicKeys,
+        signature,
+        message,
+        caseIdentifier = '',
+        separator = false,
+    ) => {
+    const out = ref.alloc(VerificationPtr)
+    checkStatus(lib.LirisiVerifySignatureDetails(
+        ...inBytes(foldedPublicKeys),
+        ...inBytes(signature),
+        ...inBytes(message),
+        ...inBytes(caseIdentifier),
+        separator ? 1 : 0,
+        out,
+    ))
+    const pointer = out.deref()
+    try {
+        const verification = pointer.deref()
+        return {
+            status: verification.status,
+            valid: verification.status === Success,
+            keyImage: verification.keyImage,
+            curveName: verification.curveName,
+            hashName: verification.hashName,
+            ringSize: Number(verification.ringSize),
+            ringDigest: verification.ringDigest,
+        }
+    } finally {
+        lib.LirisiFree(pointer)
+    }
+}
+
+module.exports.SignatureKeyImage = (signature, separator = false) => toString(callBytes(lib.LirisiSignatureKeyImage, ...inBytes(signature), separator ? 1 : 0))
+module.exports.PublicKeysDigest = (foldedPublicKeys, separator = false) => toString(callBytes(lib.LirisiPublicKeysDigest, ...inBytes(foldedPublicKeys), separator ? 1 : 0))
+module.exports.PublicKeyXYCoordinates = (publicKey) => callBytes(lib.LirisiPublicKeyXYCoordinates, ...inBytes(publicKey))
+module.exports.UnfoldPublicKeys = (foldedPublicKeys, format = 'PEM') => callList(lib.LirisiUnfoldPublicKeys, ...inBytes(foldedPublicKeys), format)
+module.exports.GeneratePrivateKey = (curveName = 'prime256v1', format = 'PEM') => callBytes(lib.LirisiGeneratePrivateKey, curveName, format)
+module.exports.DerivePublicKey = (privateKey, format = 'PEM') => callBytes(lib.LirisiDerivePublicKey, ...inBytes(privateKey), format)
 
 module.exports.ArrayToString = (array) => Buffer.from(array).toString()
 module.exports.ResultMessage = (code) => code === Success ? "Verified OK." : "Verification Failure: " + ErrorCode[code]
 
 
 const lib = ffi.Library(path.join(__dirname, "lirisilib.so"), {
-    FoldPublicKeys: [BuffBytes, [GoBytesArray, GoString, GoString, GoString]],
-    CreateSignature: [BuffBytes, [GoBytes, GoBytes, GoBytes, GoBytes, GoString]],
-    VerifySignature: [ref.types.int, [GoBytes, GoBytes, GoBytes, GoBytes]],
-    SignatureKeyImage: [BuffBytes, [GoBytes, GoBoolean]],
-    PublicKeysDigest: [BuffBytes, [GoBytes, GoBoolean]],
-    PublicKeyXYCoordinates: [BuffBytes, [GoBytes]],
-    UnfoldPublicKeys: [BuffBytes, [GoBytes, GoString]],
-    GeneratePrivateKey: [BuffBytes, [GoString, GoString]],
-    DerivePublicKey: [BuffBytes, [GoBytes, GoString]],
+    LirisiFree: ['void', ['pointer']],
+    LirisiErrorMessage: ['string', ['int']],
+    LirisiListSize: ['size_t', [ListHandle]],
+    LirisiListItem: [BytesPtr, [ListHandle, 'size_t', SizePtr]],
+    LirisiFoldPublicKeys: ['int', [BytesPtrArray, SizeArray, 'size_t', 'string', 'string', 'string', BytesPtrPtr, SizePtr]],
+    LirisiCreateSignature: ['int', [BytesPtr, 'size_t', BytesPtr, 'size_t', BytesPtr, 'size_t', BytesPtr, 'size_t', 'string', BytesPtrPtr, SizePtr]],
+    LirisiVerifySignature: ['int', [BytesPtr, 'size_t', BytesPtr, 'size_t', BytesPtr, 'size_t', BytesPtr, 'size_t']],
+    LirisiVerifySignatureDetails: ['int', [BytesPtr, 'size_t', BytesPtr, 'size_t', BytesPtr, 'size_t', BytesPtr, 'size_t', 'int', ref.refType(VerificationPtr)]],
+    LirisiSignatureKeyImage: ['int', [BytesPtr, 'size_t', 'int', BytesPtrPtr, SizePtr]],
+    LirisiPublicKeysDigest: ['int', [BytesPtr, 'size_t', 'int', BytesPtrPtr, SizePtr]],
+    LirisiPublicKeyXYCoordinates: ['int', [BytesPtr, 'size_t', BytesPtrPtr, SizePtr]],
+    LirisiUnfoldPublicKeys: ['int', [BytesPtr, 'size_t', 'string', ListHandlePtr]],
+    LirisiGeneratePrivateKey: ['int', ['string', 'string', BytesPtrPtr, SizePtr]],
+    LirisiDerivePublicKey: ['int', [BytesPtr, 'size_t', 'string', BytesPtrPtr, SizePtr]],
 })
@@ -2,4 +2,5 @@ from .exceptions import LirisiException
 from .library import (CreateSignature, DerivePublicKey, FoldPublicKeys,
                       GeneratePrivateKey, PublicKeysDigest,
                       PublicKeyXYCoordinates, SignatureKeyImage,
-                      UnfoldPublicKeys, VerifySignature)
+                      UnfoldPublicKeys, VerifySignature,
+                      VerifySignatureDetails)
@@ -7,13 +7,11 @@
 
 import ctypes
 import os
-from typing import List, Sequence
+from typing import List, NamedTuple, Sequence
 
-from .exceptions import EXCEPTION_BY_STATUS
-from .structs import (BuffBytes, GoBoolean, GoBytes, GoBytesArray, GoString,
-                      GoStringsArray)
-from .utils import (goBytes, goBytesArray, goString, goStringsArray, toBytes,
-                    toListBytes, toStr)
+from .structs import InBytes, InName, ListHandle, OutBytes, Size, Verification
+from .utils import (callBytes, callList, checkStatus, inBytes, inBytesArray,
+                    inName)
 
 # API - Main Library functions.
 
@@ -30,9 +28,7 @@ def GeneratePrivateKey(curveName: str = "prime256v1", outFormat: str = "PEM") ->
     print(priateKeyPem.decode())
     ```
     """
-    lib.GeneratePrivateKey.argtypes = [GoString, GoString]
-    lib.GeneratePrivateKey.restype = BuffBytes
-    return toBytes(lib.GeneratePrivateKey(goString(curveName), goString(outFormat)))
+    return callBytes(lib, lib.LirisiGeneratePrivateKey, inName(curveName), inName(outFormat))
 
 
 def DerivePublicKey(privateKey: bytes, outFormat: str = "PEM") -> bytes:
@@ -48,9 +44,7 @@ def DerivePublicKey(privateKey: bytes, outFormat: str = "PEM") -> bytes:
     print(publicKeyPem.decode())
     ```
     """
-    lib.DerivePublicKey.argtypes = [GoBytes, GoString]
-    lib.DerivePublicKey.restype = BuffBytes
-    return toBytes(lib.DerivePublicKey(goBytes(privateKey), goString(outFormat)))
+    return callBytes(lib, lib.LirisiDerivePublicKey, *inBytes(privateKey), inName(outFormat))
 
 
 def SignatureKeyImage(signature_body: bytes, separator: bool = False) -> str:
@@ -69,9 +63,7 @@ def SignatureKeyImage(signature_body: bytes, separator: bool = False) -> str:
     print(key_image)
     ```
     """
-    lib.SignatureKeyImage.argtypes = [GoBytes, GoBoolean]
-    lib.SignatureKeyImage.restype = BuffBytes
-    return toStr(lib.SignatureKeyImage(goBytes(signature_body), GoBoolean(separator)))
+    return callBytes(lib, lib.LirisiSignatureKeyImage, *inBytes(signature_body), int(separator)).decode('UTF-8')
 
 
 def FoldPublicKeys(
@@ -97,14 +89,14 @@ def FoldPublicKeys(
     print(foldedPublicKeys.decode())
     ```
     """
-    lib.FoldPublicKeys.argtypes = [GoBytesArray, GoString, GoString, GoString]
-    lib.FoldPublicKeys.restype = BuffBytes
-    return toBytes(lib.FoldPublicKeys(
-        goBytesArray(pubKeysContent),
-        goString(hashName),
-        goString(outFormat),
-        goString(order),
-    ))
+    return callBytes(
+        lib,
+        lib.LirisiFoldPublicKeys,
+        *inBytesArray(pubKeysContent),
+        inName(hashName),
+        inName(outFormat),
+        inName(order),
+    )
 
 
 def UnfoldPublicKeys(foldedPublicKeys: Sequence[bytes], outFormat: str = 'PEM') -> List[bytes]:
@@ -122,9 +114,7 @@ def UnfoldPublicKeys(foldedPublicKeys: Sequence[bytes], outFormat: str = 'PEM')
         print(key.decode())
     ```
     """
-    lib.UnfoldPublicKeys.argtypes = [GoBytes, GoString]
-    lib.UnfoldPublicKeys.restype = BuffBytes
-    return toListBytes(lib.UnfoldPublicKeys(goBytes(foldedPublicKeys), goString(outFormat)))
+    return callList(lib, lib.LirisiUnfoldPublicKeys, *inBytes(foldedPublicKeys), inName(outFormat))
 
 
 def PublicKeysDigest(foldedPublicKeys: Sequence[bytes], separator: bool = False) -> bytes:
@@ -139,9 +129,7 @@ def PublicKeysDigest(foldedPublicKeys: Sequence[bytes], separator: bool = False)
     print(digest.decode())
     ```
     """
-    lib.PublicKeysDigest.argtypes = [GoBytes, GoBoolean]
-    lib.PublicKeysDigest.restype = BuffBytes
-    return toBytes(lib.PublicKeysDigest(goBytes(foldedPublicKeys), GoBoolean(separator)))
+    return callBytes(lib, lib.LirisiPublicKeysDigest, *inBytes(foldedPublicKeys), int(separator))
 
 
 def PublicKeyXYCoordinates(foldedPublicKeys: Sequence[bytes], separator: bool = False) -> bytes:
@@ -156,9 +144,7 @@ def PublicKeyXYCoordinates(foldedPublicKeys: Sequence[bytes], separator: bool =
     print(coordinates)
     ```
     """
-    lib.PublicKeyXYCoordinates.argtypes = [GoBytes]
-    lib.PublicKeyXYCoordinates.restype = BuffBytes
-    return toBytes(lib.PublicKeyXYCoordinates(goBytes(foldedPublicKeys)))
+    return callBytes(lib, lib.LirisiPublicKeyXYCoordinates, *inBytes(foldedPublicKeys))
 
 
 def CreateSignature(
@@ -183,15 +169,15 @@ def CreateSignature(
     print("signature:", signature.decode())
     ```
     """
-    lib.CreateSignature.argtypes = [GoBytes, GoBytes, GoBytes, GoBytes, GoString]
-    lib.CreateSignature.restype = BuffBytes
-    return toBytes(lib.CreateSignature(
-        goBytes(foldedPublicKeys),
-        goBytes(privateKeyContent),
-        goBytes(message),
-        goBytes(caseIdentifier),
-        goString(outFormat),
-    ))
+    return callBytes(
+        lib,
+        lib.LirisiCreateSignature,
+        *inBytes(foldedPublicKeys),
+        *inBytes(privateKeyContent),
+        *inBytes(message),
+        *inBytes(caseIdentifier),
+        inName(outFormat),
+    )
 
 
 def VerifySignature(
@@ -215,22 +201,103 @@ def VerifySignature(
     print("result:", result)
     ```
     """
-    lib.VerifySignature.argtypes = [GoBytes, GoBytes, GoBytes, GoBytes]
-    lib.VerifySignature.restype = GoBoolean
-    status = lib.VerifySignature(
-        goBytes(foldedPublicKeys),
-        goBytes(signature),
-        goBytes(message),
-        goBytes(caseIdentifier),
+    status = lib.LirisiVerifySignature(
+        *inBytes(foldedPublicKeys),
+        *inBytes(signature),
+        *inBytes(message),
+        *inBytes(caseIdentifier),
     )
-    if status not in (0, 9):
-        raise EXCEPTION_BY_STATUS[status]()
+    if status != 9:
+        checkStatus(lib, status)
     return status == 0
 
 
+# Result of VerifySignatureDetails.
+SignatureDetails = NamedTuple('SignatureDetails', [
+    ('status', int),
+    ('valid', bool),
+    ('key_image', str),
+    ('curve_name', str),
+    ('hash_name', str),
+    ('ring_size', int),
+    ('ring_digest', str),
+])
+
+
+def VerifySignatureDetails(
+            foldedPublicKeys: bytes,
+            signature: bytes,
+            message: bytes,
+            caseIdentifier: bytes = '',
+            separator: bool = False,
+        ) -> SignatureDetails:
+    """## VerifySignatureDetails
+
+    Verify signature and return its key image, curve and hash names, size and digest of the ring.
+    The signature is parsed only once. Properties are filled also when the verification fails.
+
+    ```python
+    from lirisi import VerifySignatureDetails
+
+    public_keys = open("prime256v1-keys.pem", "rb").read()
+    signature = open("prime256v1-signature.pem", "rb").read()
+
+    details = VerifySignatureDetails(public_keys, signature, b'Hello, world!')
+    print("valid:", details.valid, "key image:", details.key_image)
+    ```
+    """
+    verification = ctypes.POINTER(Verification)()
+    checkStatus(lib, lib.LirisiVerifySignatureDetails(
+        *inBytes(foldedPublicKeys),
+        *inBytes(signature),
+        *inBytes(message),
+        *inBytes(caseIdentifier),
+        int(separator),
+        ctypes.byref(verification),
+    ))
+    try:
+        result = verification.contents
+        return SignatureDetails(
+            status=result.status,
+            valid=result.status == 0,
+            key_image=result.keyImage.decode('UTF-8'),
+            curve_name=result.curveName.decode('UTF-8'),
+            hash_name=result.hashName.decode('UTF-8'),
+            ring_size=result.ringSize,
+            ring_digest=result.ringDigest.decode('UTF-8'),
+        )
+    finally:
+        lib.LirisiFree(verification)
+
 # Init library.
 
 path = os.path.dirname(__file__)
 if path == "":
     path = "."
 lib = ctypes.cdll.LoadLibrary(os.path.join(path, "lirisilib.so"))
+
+OutBytesRef = ctypes.POINTER(OutBytes)
+SizeRef = ctypes.POINTER(Size)
+
+lib.LirisiFree.argtypes = [ctypes.c_void_p]
+lib.LirisiFree.restype = None
+lib.LirisiErrorMessage.argtypes = [ctypes.c_int]
+lib.LirisiErrorMessage.restype = ctypes.c_char_p
+lib.LirisiListSize.argtypes = [ListHandle]
+lib.LirisiListSize.restype = Size
+lib.LirisiListItem.argtypes = [ListHandle, Size, SizeRef]
+lib.LirisiListItem.restype = OutBytes
+lib.LirisiGeneratePrivateKey.argtypes = [InName, InName, OutBytesRef, SizeRef]
+lib.LirisiDerivePublicKey.argtypes = [InBytes, Size, InName, OutBytesRef, SizeRef]
+lib.LirisiFoldPublicKeys.argtypes = [
+    ctypes.POINTER(InBytes), SizeRef, Size, InName, InName, InName, OutBytesRef, SizeRef]
+lib.LirisiUnfoldPublicKeys.argtypes = [InBytes, Size, InName, ctypes.POINTER(ListHandle)]
+lib.LirisiPublicKeysDigest.argtypes = [InBytes, Size, ctypes.c_int, OutBytesRef, SizeRef]
+lib.LirisiPublicKeyXYCoordinates.argtypes = [InBytes, Size, OutBytesRef, SizeRef]
+lib.LirisiCreateSignature.argtypes = [
+    InBytes, Size, InBytes, Size, InBytes, Size, InBytes, Size, InName, OutBytesRef, SizeRef]
+lib.LirisiVerifySignature.argtypes = [InBytes, Size, InBytes, Size, InBytes, Size, InBytes, Size]
+lib.LirisiVerifySignatureDetails.argtypes = [
+    InBytes, Size, InBytes, Size, InBytes, Size, InBytes, Size, ctypes.c_int,
+    ctypes.POINTER(ctypes.POINTER(Verification))]
+lib.LirisiSignatureKeyImage.argtypes = [InBytes, Size, ctypes.c_int, OutBytesRef, SizeRef]
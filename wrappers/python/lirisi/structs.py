@@ -1,46 +1,29 @@
 import ctypes
 
 
-GoBoolean = ctypes.c_ubyte
+# const uint8_t * - input passed as bytes, with its length.
+InBytes = ctypes.c_char_p
 
+# const char * - NUL-terminated UTF-8 name.
+InName = ctypes.c_char_p
 
-class GoBytes(ctypes.Structure):
-    """GoBytes represents go type []byte."""
-    _fields_ = [
-        ("data", ctypes.POINTER(ctypes.c_ubyte)),
-        ("len", ctypes.c_longlong),
-        ("cap", ctypes.c_longlong),
-    ]
-
-
-class GoBytesArray(ctypes.Structure):
-    """GoBytesArray represents go type [][]byte."""
-    _fields_ = [
-        ("data", ctypes.POINTER(GoBytes)),
-        ("len", ctypes.c_longlong),
-        ("cap", ctypes.c_longlong),
-    ]
+# size_t - length of input or output.
+Size = ctypes.c_size_t
 
+# uint8_t * - output buffer allocated by the library, released by LirisiFree.
+OutBytes = ctypes.POINTER(ctypes.c_ubyte)
 
-class GoString(ctypes.Structure):
-    """GoString represents go type string."""
-    _fields_ = [
-        ("p", ctypes.c_char_p),
-        ("n", ctypes.c_int)
-    ]
-
-
-class GoStringsArray(ctypes.Structure):
-    """GoStringsArray represents go type []string."""
-    _fields_ = [
-        ("data", ctypes.POINTER(GoString)),
-        ("len", ctypes.c_longlong),
-        ("cap", ctypes.c_longlong),
-    ]
+# LirisiList * - opaque list handle, released by LirisiFree.
+ListHandle = ctypes.c_void_p
 
 
-class BuffBytes(ctypes.Structure):
-    """BuffBytes represents go type unsafe.Pointer of C.CBytes."""
+class Verification(ctypes.Structure):
+    """Verification represents C type LirisiVerification."""
     _fields_ = [
-        ("data", ctypes.POINTER(ctypes.c_ubyte)),
+        ("status", ctypes.c_int),
+        ("keyImage", ctypes.c_char_p),
+        ("curveName", ctypes.c_char_p),
+        ("hashName", ctypes.c_char_p),
+        ("ringSize", ctypes.c_size_t),
+        ("ringDigest", ctypes.c_char_p),
     ]
@@ -1,79 +1,55 @@
 import ctypes
-from typing import List, Sequence
+from typing import Callable, List, Sequence
 
-from .exceptions import EXCEPTION_BY_STATUS
-from .structs import BuffBytes, GoBytes, GoBytesArray, GoString, GoStringsArray
+from .exceptions import EXCEPTION_BY_STATUS, LirisiException
+from .structs import InBytes, ListHandle, OutBytes, Size
 
 
-def goBytes(octets: bytes) -> GoBytes:
-    """Make GoBytes instance with array of bytes."""
-    length = len(octets)
-    return GoBytes((ctypes.c_ubyte * length)(*octets), length, length)
+def inBytes(octets: bytes) -> (bytes, int):
+    """Make input pointer and length of bytes."""
+    if isinstance(octets, str):
+        octets = octets.encode("UTF-8")
+    return octets, len(octets)
 
 
-def goBytesArray(array: Sequence[bytes]) -> GoBytesArray:
-    """Make GoBytesArray instance, that is the GoSlices array."""
-    data = []
-    for values in array:
-        data.append(goBytes(values))
-    length = len(data)
-    return GoBytesArray((GoBytes * length)(*data), length, length)
+def inBytesArray(array: Sequence[bytes]) -> (ctypes.Array, ctypes.Array, int):
+    """Make arrays of input pointers and lengths."""
+    length = len(array)
+    return (InBytes * length)(*array), (Size * length)(*[len(item) for item in array]), length
 
 
-def goString(text: str) -> GoString:
-    """Cast python string to GoString."""
-    octets = text.encode("UTF-8")
-    return GoString(ctypes.c_char_p(octets), len(octets))
+def inName(text: str) -> bytes:
+    """Encode name into NUL-terminated UTF-8."""
+    return text.encode("UTF-8")
 
 
-def goStringsArray(array: Sequence[str]) -> GoStringsArray:
-    """Make GoStringsArray instance with array of UTF-8 bytes."""
-    data = []
-    for values in array:
-        data.append(goString(values))
-    length = len(data)
-    return GoStringsArray((GoString * length)(*data), length, length)
+def checkStatus(lib: ctypes.CDLL, status: int) -> None:
+    """Raise exception of the status."""
+    if status != 0:
+        message = lib.LirisiErrorMessage(status).decode("UTF-8")
+        raise EXCEPTION_BY_STATUS.get(status, LirisiException)(message)
 
 
-def readPointer(buff: BuffBytes) -> (int, List[int]):
-    """Read response data and status from unsafe.Pointer of Go C.CBytes."""
-    status = int.from_bytes(buff.data[:8], byteorder='little')
-    size = int.from_bytes(buff.data[:16][8:], byteorder='little')
-    data = buff.data[:size+16][16:]
-    return status, data
+def callBytes(lib: ctypes.CDLL, function: Callable, *args) -> bytes:
+    """Call function with output buffer, copy the buffer and release it."""
+    out, outLen = OutBytes(), Size()
+    checkStatus(lib, function(*args, ctypes.byref(out), ctypes.byref(outLen)))
+    try:
+        return ctypes.string_at(out, outLen.value)
+    finally:
+        lib.LirisiFree(out)
 
 
-def readFromPointer(buff: BuffBytes) -> (int, List[List[int]]):
-    """Read response status and data from unsafe.Pointer of Go C.CBytes."""
-    data = []
-    size = 8
-    status = int.from_bytes(buff.data[:size], byteorder='little')
-    length = int.from_bytes(buff.data[:size+size][size:], byteorder='little')
-    frm, to = size * 2, size * 2
-    for i in range(length):
-        frm, to = frm + size, frm
-        bufflen = int.from_bytes(buff.data[:frm][to:], byteorder='little')
-        frm, to = frm + bufflen, frm
-        data.append(bytes(buff.data[:frm][to:]))
-    return status, data
-
-
-def toListBytes(buff: BuffBytes) -> List[bytes]:
-    """Get response from buffer as bytes."""
-    status, data = readFromPointer(buff)
-    if status > 0:
-        raise EXCEPTION_BY_STATUS[status]
-    return data
-
-
-def toBytes(buff: BuffBytes) -> bytes:
-    """Get response from buffer as bytes."""
-    status, octets = readPointer(buff)
-    if status > 0:
-        raise EXCEPTION_BY_STATUS[status](bytes(octets).decode('UTF-8'))
-    return bytes(octets)
-
-
-def toStr(buff: BuffBytes) -> str:
-    """Get response from buffer as string."""
-    return toBytes(buff).decode('UTF-8')
+def callList(lib: ctypes.CDLL, function: Callable, *args) -> List[bytes]:
+    """Call function with output list, copy items of the list and release it."""
+    handle = ListHandle()
+    checkStatus(lib, function(*args, ctypes.byref(handle)))
+    try:
+        items = []
+        for index in range(lib.LirisiListSize(handle)):
+            length = Size()
+            item = lib.LirisiListItem(handle, index, ctypes.byref(length))
+            items.append(ctypes.string_at(item, length.value))
+        return items
+    finally:
+        lib.LirisiFree(handle)